@@ -0,0 +1,68 @@
+package ircmessage
+
+// Batch represents a completed IRCv3 batch as per:
+// https://ircv3.net/specs/extensions/batch
+type Batch struct {
+	Ref      string
+	Type     string
+	Params   []string
+	Tags     map[string]string // Tags of the opening BATCH message.
+	Prefix   string            // Prefix of the opening BATCH message.
+	Messages []Message
+	Batches  []*Batch // Nested batches, in the order they ended.
+}
+
+// BatchCollector gathers messages belonging to batches. The zero value is
+// ready to use.
+type BatchCollector struct {
+	open   map[string]*Batch
+	parent map[string]string
+}
+
+// Add feeds a message to the collector. It reports whether the message was
+// consumed as part of a batch, and returns the batch once a top-level batch
+// has ended. Nested batches are attached to their parent rather than being
+// returned.
+func (c *BatchCollector) Add(m Message) (*Batch, bool) {
+	if c.open == nil {
+		c.open = make(map[string]*Batch)
+		c.parent = make(map[string]string)
+	}
+	if HasCommand(m, "BATCH") && len(m.Params) > 0 && len(m.Params[0]) > 1 {
+		ref := m.Params[0][1:]
+		switch m.Params[0][0] {
+		case '+':
+			b := &Batch{Ref: ref, Tags: m.Tags, Prefix: m.Prefix}
+			if len(m.Params) > 1 {
+				b.Type = m.Params[1]
+				b.Params = m.Params[2:]
+			}
+			c.open[ref] = b
+			if p, ok := m.Tags["batch"]; ok && c.open[p] != nil {
+				c.parent[ref] = p
+			}
+			return nil, true
+		case '-':
+			b := c.open[ref]
+			if b == nil {
+				return nil, false
+			}
+			delete(c.open, ref)
+			if p, ok := c.parent[ref]; ok {
+				delete(c.parent, ref)
+				if parent := c.open[p]; parent != nil {
+					parent.Batches = append(parent.Batches, b)
+					return nil, true
+				}
+			}
+			return b, true
+		}
+	}
+	if ref, ok := m.Tags["batch"]; ok {
+		if b := c.open[ref]; b != nil {
+			b.Messages = append(b.Messages, m)
+			return nil, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,45 @@
+package ircmessage
+
+import "testing"
+
+func TestBatchCollector(t *testing.T) {
+	msgs := []Message{
+		{Command: "PRIVMSG", Params: []string{"#a", "before"}},
+		{Tags: map[string]string{"time": "x"}, Command: "BATCH", Params: []string{"+outer", "chathistory", "#a"}},
+		{Tags: map[string]string{"batch": "outer"}, Command: "PRIVMSG", Params: []string{"#a", "one"}},
+		{Tags: map[string]string{"batch": "outer"}, Command: "BATCH", Params: []string{"+inner", "netsplit"}},
+		{Tags: map[string]string{"batch": "inner"}, Command: "QUIT", Params: []string{"split"}},
+		{Command: "BATCH", Params: []string{"-inner"}},
+		{Tags: map[string]string{"batch": "outer"}, Command: "PRIVMSG", Params: []string{"#a", "two"}},
+		{Command: "BATCH", Params: []string{"-outer"}},
+		{Command: "BATCH", Params: []string{"-unknown"}},
+	}
+	var (
+		c    BatchCollector
+		done *Batch
+	)
+	for i, m := range msgs {
+		b, consumed := c.Add(m)
+		if want := i > 0 && i < len(msgs)-1; consumed != want {
+			t.Errorf("%d. expecting consumed %v, got %v", i, want, consumed)
+		}
+		if b != nil {
+			if done != nil {
+				t.Fatalf("%d. unexpected second batch %+v", i, b)
+			}
+			done = b
+		}
+	}
+	if done == nil {
+		t.Fatal("expecting a completed batch")
+	}
+	if done.Ref != "outer" || done.Type != "chathistory" || len(done.Params) != 1 || done.Tags["time"] != "x" {
+		t.Errorf("unexpected batch header: %+v", done)
+	}
+	if len(done.Messages) != 2 || done.Messages[1].Params[1] != "two" {
+		t.Errorf("unexpected batch messages: %v", done.Messages)
+	}
+	if len(done.Batches) != 1 || done.Batches[0].Type != "netsplit" || len(done.Batches[0].Messages) != 1 {
+		t.Errorf("unexpected nested batches: %+v", done.Batches)
+	}
+}
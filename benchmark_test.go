@@ -6,9 +6,10 @@ import (
 )
 
 const (
-	prefix    = ":nickname!user@example.com"
-	raw       = prefix + "PRIVMSG #example :hello there"
+	prefix    = "nickname!user@example.com"
+	raw       = ":" + prefix + " PRIVMSG #example :hello there\r\n"
 	rawTagged = "@test=super;single " + raw
+	rawPing   = "PING :irc.example.com\r\n"
 )
 
 func BenchmarkScan(b *testing.B) {
@@ -31,6 +32,45 @@ func BenchmarkScanTagged(b *testing.B) {
 	}
 }
 
+func BenchmarkScanPing(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		scanner := NewScanner(strings.NewReader(rawPing))
+		b.StartTimer()
+		scanner.Scan()
+		scanner.Message()
+	}
+}
+
+func BenchmarkScanStream(b *testing.B) {
+	input := strings.Repeat(raw+rawPing+rawTagged, 100)
+	b.SetBytes(int64(len(input)))
+	for n := 0; n < b.N; n++ {
+		scanner := NewScanner(strings.NewReader(input))
+		for scanner.Scan() {
+			scanner.Message()
+		}
+		if err := scanner.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScanStreamReuse(b *testing.B) {
+	input := strings.Repeat(raw+rawPing+rawTagged, 100)
+	b.SetBytes(int64(len(input)))
+	for n := 0; n < b.N; n++ {
+		scanner := NewScanner(strings.NewReader(input))
+		scanner.ReuseStorage()
+		for scanner.Scan() {
+			scanner.Message()
+		}
+		if err := scanner.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func BenchmarkParsePrefix(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		if p := ParsePrefix(prefix); p == nil {
@@ -38,3 +78,17 @@ func BenchmarkParsePrefix(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkAppendMessage(b *testing.B) {
+	m := Message{
+		Tags:    map[string]string{"test": "super", "single": ""},
+		Prefix:  prefix,
+		Command: "PRIVMSG",
+		Params:  []string{"#example", "hello there"},
+	}
+	buf := make([]byte, 0, 1024)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		buf, _ = AppendMessage(buf[:0], m)
+	}
+}
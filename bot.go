@@ -0,0 +1,114 @@
+package ircmessage
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrNotBotCommand is returned by ParseBotCommand for text that does
+	// not begin with the command prefix.
+	ErrNotBotCommand = errors.New("not a bot command")
+	// ErrUnterminatedQuote is returned for arguments with an unclosed quote.
+	ErrUnterminatedQuote = errors.New("unterminated quote")
+)
+
+// BotCommand is a command given to a bot in the text of a PRIVMSG, such as
+// "!weather London, UK".
+type BotCommand struct {
+	Name    string   // The command, without the command prefix.
+	Args    []string // The arguments, split as by SplitArgs.
+	ArgText string   // The text following the command, unsplit.
+	Sender  string   // The nickname of the user who gave the command.
+	Prefix  string   // The full prefix of the user who gave the command.
+	// Channel is the channel the command was given in, without any
+	// STATUSMSG prefix, or empty if it was sent privately.
+	Channel string
+}
+
+// ReplyTo returns the target a reply to c should be sent to: the channel,
+// or the sender if the command was sent privately.
+func (c BotCommand) ReplyTo() string {
+	if c.Channel != "" {
+		return c.Channel
+	}
+	return c.Sender
+}
+
+// ParseBotCommand parses a PRIVMSG whose text begins with prefix, such as
+// "!", as a bot command. It returns ErrNotBotCommand if the text does not
+// begin with the prefix followed by a command name, and ErrMessageMalformed
+// for anything other than a PRIVMSG. If the arguments cannot be split, the
+// command is returned without Args along with the error from SplitArgs.
+// The isupport argument is used to recognise channels and may be nil, in
+// which case the defaults apply.
+func ParseBotCommand(m Message, prefix string, isupport *ISupport) (BotCommand, error) {
+	if !HasCommand(m, "PRIVMSG") || len(m.Params) < 2 {
+		return BotCommand{}, ErrMessageMalformed
+	}
+	text, ok := strings.CutPrefix(m.Params[1], prefix)
+	if !ok || text == "" || text[0] == ' ' {
+		return BotCommand{}, ErrNotBotCommand
+	}
+	c := BotCommand{Prefix: m.Prefix}
+	c.Name, c.ArgText, _ = strings.Cut(text, " ")
+	c.ArgText = strings.Trim(c.ArgText, " ")
+	if p := ParsePrefix(m.Prefix); p != nil && !p.IsServer {
+		c.Sender = p.Nickname
+	}
+	target := m.Params[0]
+	if target != "" && strings.IndexByte(isupport.StatusMsg(), target[0]) >= 0 {
+		target = target[1:]
+	}
+	if isupport.IsChannel(target) {
+		c.Channel = target
+	}
+	args, err := SplitArgs(c.ArgText)
+	c.Args = args
+	return c, err
+}
+
+// SplitArgs splits s into space separated arguments. Single or double
+// quotes at the start of an argument group words into it, while those
+// within a word, as in "don't", are taken literally. A backslash outside
+// single quotes takes the following character literally. It returns
+// ErrUnterminatedQuote if a quote is left open.
+func SplitArgs(s string) ([]string, error) {
+	var (
+		args  []string
+		b     strings.Builder
+		quote byte
+		inArg bool
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && quote != '\'' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+			inArg = true
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			b.WriteByte(c)
+		case (c == '"' || c == '\'') && !inArg:
+			quote, inArg = c, true
+		case c == ' ':
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+		default:
+			b.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, ErrUnterminatedQuote
+	}
+	if inArg {
+		args = append(args, b.String())
+	}
+	return args, nil
+}
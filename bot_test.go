@@ -0,0 +1,87 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+var splitArgsTests = []struct {
+	in       string
+	expected []string
+	err      error
+}{
+	{"", nil, nil},
+	{"London, UK", []string{"London,", "UK"}, nil},
+	{`  a   b  `, []string{"a", "b"}, nil},
+	{`"New York" today`, []string{"New York", "today"}, nil},
+	{`'it\'s`, []string{`it\s`}, nil},
+	{`"it's" 'say "hi"'`, []string{"it's", `say "hi"`}, nil},
+	{`a\ b "c\"d" ""`, []string{"a b", `c"d`, ""}, nil},
+	{`pre"fix"ed`, []string{`pre"fix"ed`}, nil},
+	{`don't stop`, []string{"don't", "stop"}, nil},
+	{`St. John's "New York"`, []string{"St.", "John's", "New York"}, nil},
+	{`"open`, nil, ErrUnterminatedQuote},
+}
+
+func TestSplitArgs(t *testing.T) {
+	for i, tt := range splitArgsTests {
+		args, err := SplitArgs(tt.in)
+		if err != tt.err || !reflect.DeepEqual(args, tt.expected) {
+			t.Errorf("%d. expecting %q %v, got %q %v", i, tt.expected, tt.err, args, err)
+		}
+	}
+}
+
+var botCommandTests = []struct {
+	in       Message
+	expected BotCommand
+	err      error
+}{
+	{
+		Message{Prefix: "nick!user@host", Command: "PRIVMSG", Params: []string{"#chan", "!weather London, UK"}},
+		BotCommand{Name: "weather", Args: []string{"London,", "UK"}, ArgText: "London, UK", Sender: "nick", Prefix: "nick!user@host", Channel: "#chan"},
+		nil,
+	},
+	{
+		Message{Prefix: "nick!user@host", Command: "privmsg", Params: []string{"bot", "!help"}},
+		BotCommand{Name: "help", Sender: "nick", Prefix: "nick!user@host"},
+		nil,
+	},
+	{
+		Message{Prefix: "nick", Command: "PRIVMSG", Params: []string{"@#chan", `!say "hello there"`}},
+		BotCommand{Name: "say", Args: []string{"hello there"}, ArgText: `"hello there"`, Sender: "nick", Prefix: "nick", Channel: "#chan"},
+		nil,
+	},
+	{Message{Command: "PRIVMSG", Params: []string{"#chan", "hello"}}, BotCommand{}, ErrNotBotCommand},
+	{Message{Command: "PRIVMSG", Params: []string{"#chan", "! help"}}, BotCommand{}, ErrNotBotCommand},
+	{
+		Message{Prefix: "nick", Command: "PRIVMSG", Params: []string{"#chan", "!weather St. John's"}},
+		BotCommand{Name: "weather", Args: []string{"St.", "John's"}, ArgText: "St. John's", Sender: "nick", Prefix: "nick", Channel: "#chan"},
+		nil,
+	},
+	{
+		Message{Command: "PRIVMSG", Params: []string{"#chan", `!say "hi`}},
+		BotCommand{Name: "say", ArgText: `"hi`, Channel: "#chan"},
+		ErrUnterminatedQuote,
+	},
+	{Message{Command: "NOTICE", Params: []string{"#chan", "!help"}}, BotCommand{}, ErrMessageMalformed},
+}
+
+func TestParseBotCommand(t *testing.T) {
+	isupport := NewISupport()
+	isupport.Update(Message{Command: "005", Params: []string{"me", "STATUSMSG=@+", "are supported"}})
+	for i, tt := range botCommandTests {
+		c, err := ParseBotCommand(tt.in, "!", isupport)
+		if err != tt.err || !reflect.DeepEqual(c, tt.expected) {
+			t.Errorf("%d. expecting %+v %v, got %+v %v", i, tt.expected, tt.err, c, err)
+		}
+	}
+	c, _ := ParseBotCommand(botCommandTests[1].in, "!", nil)
+	if c.ReplyTo() != "nick" {
+		t.Errorf("expecting private reply to nick, got %q", c.ReplyTo())
+	}
+	c, _ = ParseBotCommand(botCommandTests[0].in, "!", nil)
+	if c.ReplyTo() != "#chan" {
+		t.Errorf("expecting reply to #chan, got %q", c.ReplyTo())
+	}
+}
@@ -0,0 +1,176 @@
+package ircmessage
+
+import (
+	"sort"
+	"strings"
+)
+
+// capReqBudget bounds the length of the capability list in a single CAP REQ
+// so that the message fits comfortably within the line limit.
+const capReqBudget = 400
+
+// CapNegotiator drives client-side capability negotiation as per:
+// https://ircv3.net/specs/extensions/capability-negotiation
+//
+// It does no I/O itself. Messages received from the server are passed to
+// Handle, and the messages it returns must be sent in order.
+type CapNegotiator struct {
+	// Wanted lists the capabilities to request if the server offers them.
+	Wanted []string
+	// BeforeEnd, if set, is called once the initial requests have been
+	// answered and before CAP END is sent. If it returns true, CAP END is
+	// withheld until Finish is called, allowing SASL authentication to
+	// take place during registration.
+	BeforeEnd func(n *CapNegotiator) bool
+
+	available CapSet
+	enabled   map[string]bool
+	pending   int // Outstanding CAP REQs.
+	listing   bool
+	ended     bool
+}
+
+// NewCapNegotiator returns a CapNegotiator that requests the given
+// capabilities.
+func NewCapNegotiator(wanted ...string) *CapNegotiator {
+	return &CapNegotiator{
+		Wanted:    wanted,
+		available: make(CapSet),
+		enabled:   make(map[string]bool),
+	}
+}
+
+// Start returns the CAP LS 302 message that begins negotiation. It should be
+// sent before NICK and USER.
+func (n *CapNegotiator) Start() Message {
+	n.listing = true
+	return Message{Command: "CAP", Params: []string{"LS", "302"}}
+}
+
+// Handle processes a message from the server, returning the messages to send
+// in response. Messages other than CAP are ignored.
+func (n *CapNegotiator) Handle(m Message) []Message {
+	if !HasCommand(m, "CAP") || len(m.Params) < 3 {
+		return nil
+	}
+	list := m.Params[len(m.Params)-1]
+	more := len(m.Params) > 3 && m.Params[2] == "*"
+	switch upperCommand(m.Params[1]) {
+	case "LS":
+		for k, v := range ParseCapSet(list) {
+			n.available[k] = v
+		}
+		if more || !n.listing {
+			return nil
+		}
+		n.listing = false
+		out := n.request(n.Wanted)
+		if len(out) == 0 {
+			return n.end()
+		}
+		return out
+	case "NEW":
+		caps := ParseCapSet(list)
+		var names []string
+		for k, v := range caps {
+			n.available[k] = v
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return n.request(names)
+	case "DEL":
+		for k := range ParseCapSet(list) {
+			delete(n.available, k)
+			delete(n.enabled, k)
+		}
+	case "ACK":
+		for _, c := range strings.Fields(list) {
+			if strings.HasPrefix(c, "-") {
+				delete(n.enabled, strings.ToLower(c[1:]))
+				continue
+			}
+			name, _, _ := strings.Cut(c, "=")
+			n.enabled[strings.ToLower(name)] = true
+		}
+		return n.answered(more)
+	case "NAK":
+		return n.answered(more)
+	}
+	return nil
+}
+
+func (n *CapNegotiator) answered(more bool) []Message {
+	if more || n.pending == 0 {
+		return nil
+	}
+	n.pending--
+	if n.pending > 0 || n.ended {
+		return nil
+	}
+	return n.end()
+}
+
+func (n *CapNegotiator) end() []Message {
+	if n.BeforeEnd != nil && n.BeforeEnd(n) {
+		return nil
+	}
+	return []Message{n.Finish()}
+}
+
+// request returns the CAP REQ messages for the capabilities in names that
+// are wanted, available and not yet enabled.
+func (n *CapNegotiator) request(names []string) []Message {
+	var (
+		out []Message
+		req []string
+		l   int
+	)
+	flush := func() {
+		if len(req) > 0 {
+			out = append(out, Message{Command: "CAP", Params: []string{"REQ", strings.Join(req, tokenSpace)}})
+			n.pending++
+			req, l = nil, 0
+		}
+	}
+	for _, c := range names {
+		if !n.available.Has(c) || n.Enabled(c) || !n.wants(c) {
+			continue
+		}
+		if l+len(c)+1 > capReqBudget {
+			flush()
+		}
+		req = append(req, c)
+		l += len(c) + 1
+	}
+	flush()
+	return out
+}
+
+func (n *CapNegotiator) wants(c string) bool {
+	for _, w := range n.Wanted {
+		if strings.EqualFold(w, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Finish marks negotiation as complete and returns the CAP END message. It
+// is called automatically unless BeforeEnd delays it.
+func (n *CapNegotiator) Finish() Message {
+	n.ended = true
+	return Message{Command: "CAP", Params: []string{"END"}}
+}
+
+// Done reports whether CAP END has been sent.
+func (n *CapNegotiator) Done() bool { return n.ended }
+
+// Enabled reports whether the server has acknowledged capability c.
+func (n *CapNegotiator) Enabled(c string) bool { return n.enabled[strings.ToLower(c)] }
+
+// Available returns the value advertised for capability c, and whether the
+// server currently offers it.
+func (n *CapNegotiator) Available(c string) (string, bool) {
+	v, ok := n.available[strings.ToLower(c)]
+	return v, ok
+}
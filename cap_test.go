@@ -0,0 +1,69 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func capMsg(params ...string) Message {
+	return Message{Prefix: "server", Command: "CAP", Params: params}
+}
+
+func TestCapNegotiator(t *testing.T) {
+	n := NewCapNegotiator("multi-prefix", "sasl", "away-notify", "missing")
+	if m := n.Start(); !reflect.DeepEqual(m.Params, []string{"LS", "302"}) {
+		t.Errorf("unexpected start message %v", m)
+	}
+	if out := n.Handle(capMsg("*", "LS", "*", "multi-prefix sasl=PLAIN,EXTERNAL")); out != nil {
+		t.Errorf("expecting no response to partial LS, got %v", out)
+	}
+	out := n.Handle(capMsg("*", "LS", "away-notify server-time"))
+	expected := []Message{{Command: "CAP", Params: []string{"REQ", "multi-prefix sasl away-notify"}}}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("expecting %v, got %v", expected, out)
+	}
+	if v, ok := n.Available("sasl"); !ok || v != "PLAIN,EXTERNAL" {
+		t.Errorf("expecting sasl value PLAIN,EXTERNAL, got %q", v)
+	}
+	out = n.Handle(capMsg("*", "ACK", "multi-prefix sasl away-notify"))
+	if len(out) != 1 || out[0].Params[0] != "END" || !n.Done() {
+		t.Errorf("expecting CAP END, got %v", out)
+	}
+	if !n.Enabled("sasl") || n.Enabled("server-time") {
+		t.Error("unexpected enabled capabilities")
+	}
+	out = n.Handle(capMsg("nick", "NEW", "missing"))
+	expected = []Message{{Command: "CAP", Params: []string{"REQ", "missing"}}}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("expecting %v after NEW, got %v", expected, out)
+	}
+	if out := n.Handle(capMsg("nick", "ACK", "missing")); out != nil {
+		t.Errorf("expecting no CAP END after registration, got %v", out)
+	}
+	n.Handle(capMsg("nick", "DEL", "away-notify"))
+	if n.Enabled("away-notify") {
+		t.Error("expecting away-notify to be disabled by DEL")
+	}
+}
+
+func TestCapNegotiatorBeforeEnd(t *testing.T) {
+	n := NewCapNegotiator("sasl")
+	n.BeforeEnd = func(n *CapNegotiator) bool { return n.Enabled("sasl") }
+	n.Start()
+	n.Handle(capMsg("*", "LS", "sasl"))
+	if out := n.Handle(capMsg("*", "ACK", "sasl")); out != nil || n.Done() {
+		t.Errorf("expecting CAP END to be delayed, got %v", out)
+	}
+	if m := n.Finish(); m.Params[0] != "END" || !n.Done() {
+		t.Errorf("unexpected finish message %v", m)
+	}
+}
+
+func TestCapNegotiatorNothingWanted(t *testing.T) {
+	n := NewCapNegotiator("sasl")
+	n.Start()
+	out := n.Handle(capMsg("*", "LS", "multi-prefix"))
+	if len(out) != 1 || out[0].Params[0] != "END" {
+		t.Errorf("expecting immediate CAP END, got %v", out)
+	}
+}
@@ -0,0 +1,142 @@
+package ircmessage
+
+import "strings"
+
+// CapSet is a set of capabilities with their values, such as "sasl" with
+// the value "PLAIN,EXTERNAL". Capabilities without a value have an empty
+// one. Names are compared case-insensitively and kept in lower case, so a
+// CapSet built directly should use lower case names. The operations on a
+// CapSet return new sets rather than modifying it.
+type CapSet map[string]string
+
+// ParseCapSet parses a space separated capability list with optional
+// values, such as "multi-prefix sasl=PLAIN,EXTERNAL".
+func ParseCapSet(list string) CapSet {
+	s := make(CapSet)
+	for _, c := range strings.Fields(list) {
+		k, v, _ := strings.Cut(c, "=")
+		s[strings.ToLower(k)] = v
+	}
+	return s
+}
+
+// Has reports whether name is in s.
+func (s CapSet) Has(name string) bool {
+	_, ok := s[strings.ToLower(name)]
+	return ok
+}
+
+// Union returns the capabilities in either s or o, with the values of o
+// where both have a capability.
+func (s CapSet) Union(o CapSet) CapSet {
+	u := make(CapSet, len(s)+len(o))
+	for k, v := range s {
+		u[strings.ToLower(k)] = v
+	}
+	for k, v := range o {
+		u[strings.ToLower(k)] = v
+	}
+	return u
+}
+
+// Diff returns the capabilities in s that are not in o.
+func (s CapSet) Diff(o CapSet) CapSet {
+	d := make(CapSet)
+	for k, v := range s {
+		if !o.Has(k) {
+			d[strings.ToLower(k)] = v
+		}
+	}
+	return d
+}
+
+// Intersect returns the capabilities in both s and o, with the values of s.
+func (s CapSet) Intersect(o CapSet) CapSet {
+	i := make(CapSet)
+	for k, v := range s {
+		if o.Has(k) {
+			i[strings.ToLower(k)] = v
+		}
+	}
+	return i
+}
+
+// Names returns the names of the capabilities in s, sorted.
+func (s CapSet) Names() []string {
+	names := make([]string, 0, len(s))
+	for k := range s {
+		names = append(names, k)
+	}
+	sortStrings(names)
+	return names
+}
+
+// String returns s as a capability list sorted by name, as ParseCapSet
+// reads.
+func (s CapSet) String() string {
+	var b strings.Builder
+	for i, k := range s.Names() {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k)
+		if v := s[k]; v != "" {
+			b.WriteString("=" + v)
+		}
+	}
+	return b.String()
+}
+
+// CapMessage is a CAP message from a server listing capabilities, such as
+// CAP LS, LIST, NEW, DEL, ACK or NAK.
+type CapMessage struct {
+	Subcommand string // In upper case, such as "LS".
+	Caps       CapSet
+	// Removed holds the capabilities prefixed with '-' in a CAP ACK,
+	// which are being disabled. They are not included in Caps.
+	Removed CapSet
+	// More reports that the list continues in a further message, as
+	// marked by an asterisk before the list.
+	More bool
+}
+
+// ParseCapMessage parses a CAP message from a server.
+func ParseCapMessage(m Message) (CapMessage, error) {
+	if !HasCommand(m, "CAP") || len(m.Params) < 3 {
+		return CapMessage{}, ErrMessageMalformed
+	}
+	cm := CapMessage{
+		Subcommand: upperCommand(m.Params[1]),
+		Caps:       make(CapSet),
+		Removed:    make(CapSet),
+		More:       len(m.Params) > 3 && m.Params[2] == "*",
+	}
+	for k, v := range ParseCapSet(m.Params[len(m.Params)-1]) {
+		if name, ok := strings.CutPrefix(k, "-"); ok && cm.Subcommand == "ACK" {
+			cm.Removed[name] = v
+		} else {
+			cm.Caps[k] = v
+		}
+	}
+	return cm, nil
+}
+
+// updateEnabledCaps applies the capabilities acknowledged by CAP ACK or
+// deleted by CAP DEL, as given by the subcommand and list, to enabled, which
+// maps lowered capability names to the entries as listed, with any values.
+func updateEnabledCaps(enabled map[string]string, subcommand, list string) {
+	switch upperCommand(subcommand) {
+	case "ACK":
+		for _, entry := range strings.Fields(list) {
+			if name, ok := strings.CutPrefix(entry, "-"); ok {
+				delete(enabled, strings.ToLower(capName(name)))
+			} else {
+				enabled[strings.ToLower(capName(entry))] = entry
+			}
+		}
+	case "DEL":
+		for _, entry := range strings.Fields(list) {
+			delete(enabled, strings.ToLower(capName(entry)))
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCapSet(t *testing.T) {
+	a := ParseCapSet("multi-prefix sasl=PLAIN,EXTERNAL  message-tags")
+	b := ParseCapSet("sasl=PLAIN server-time")
+	if !a.Has("sasl") || a.Has("server-time") || a["sasl"] != "PLAIN,EXTERNAL" {
+		t.Errorf("unexpected set %v", a)
+	}
+	tests := []struct {
+		got      CapSet
+		expected string
+	}{
+		{a, "message-tags multi-prefix sasl=PLAIN,EXTERNAL"},
+		{a.Union(b), "message-tags multi-prefix sasl=PLAIN server-time"},
+		{a.Diff(b), "message-tags multi-prefix"},
+		{b.Diff(a), "server-time"},
+		{a.Intersect(b), "sasl=PLAIN,EXTERNAL"},
+		{CapSet{}, ""},
+	}
+	for i, tt := range tests {
+		if s := tt.got.String(); s != tt.expected {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, s)
+		}
+	}
+	if a.String() != "message-tags multi-prefix sasl=PLAIN,EXTERNAL" {
+		t.Error("expecting operations to leave their operands unchanged")
+	}
+}
+
+func TestCapSetFold(t *testing.T) {
+	a := ParseCapSet("SASL=PLAIN Server-Time")
+	if !a.Has("sasl") || !a.Has("SERVER-TIME") || a["sasl"] != "PLAIN" {
+		t.Errorf("expecting names to be folded, got %v", a)
+	}
+	b := ParseCapSet("Server-TIME BATCH")
+	tests := []struct {
+		got      CapSet
+		expected string
+	}{
+		{a, "sasl=PLAIN server-time"},
+		{a.Union(b), "batch sasl=PLAIN server-time"},
+		{a.Diff(b), "sasl=PLAIN"},
+		{a.Intersect(b), "server-time"},
+	}
+	for i, tt := range tests {
+		if s := tt.got.String(); s != tt.expected {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, s)
+		}
+	}
+}
+
+var capMessageTests = []struct {
+	in       Message
+	expected CapMessage
+	err      error
+}{
+	{
+		Message{Command: "CAP", Params: []string{"*", "LS", "*", "sasl=PLAIN batch"}},
+		CapMessage{Subcommand: "LS", Caps: CapSet{"sasl": "PLAIN", "batch": ""}, Removed: CapSet{}, More: true},
+		nil,
+	},
+	{
+		Message{Command: "cap", Params: []string{"nick", "ack", "-batch server-time"}},
+		CapMessage{Subcommand: "ACK", Caps: CapSet{"server-time": ""}, Removed: CapSet{"batch": ""}},
+		nil,
+	},
+	{
+		Message{Command: "CAP", Params: []string{"nick", "DEL", "sasl"}},
+		CapMessage{Subcommand: "DEL", Caps: CapSet{"sasl": ""}, Removed: CapSet{}},
+		nil,
+	},
+	{Message{Command: "CAP", Params: []string{"nick", "END"}}, CapMessage{}, ErrMessageMalformed},
+	{Message{Command: "PING", Params: []string{"a", "b", "c"}}, CapMessage{}, ErrMessageMalformed},
+}
+
+func TestParseCapMessage(t *testing.T) {
+	for i, tt := range capMessageTests {
+		cm, err := ParseCapMessage(tt.in)
+		if err != tt.err || !reflect.DeepEqual(cm, tt.expected) {
+			t.Errorf("%d. expecting %+v %v, got %+v %v", i, tt.expected, tt.err, cm, err)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package ircmessage
+
+import (
+	"strconv"
+	"time"
+)
+
+// ParseChannelURL parses RPL_CHANNEL_URL (328), sent on joining a channel
+// that has a URL registered with services. It returns false for any other
+// message.
+func ParseChannelURL(m Message) (channel, url string, ok bool) {
+	if m.Command != "328" || len(m.Params) < 3 {
+		return "", "", false
+	}
+	return m.Params[1], m.Params[2], true
+}
+
+// ParseCreationTime parses RPL_CREATIONTIME (329), sent with the modes of a
+// channel. It returns false for any other message.
+func ParseCreationTime(m Message) (channel string, created time.Time, ok bool) {
+	if m.Command != "329" || len(m.Params) < 3 {
+		return "", time.Time{}, false
+	}
+	ts, err := strconv.ParseInt(m.Params[2], 10, 64)
+	if err != nil || ts < 0 {
+		return "", time.Time{}, false
+	}
+	return m.Params[1], time.Unix(ts, 0), true
+}
+
+// ChannelInfo holds what a MemberTracker knows about a channel beyond its
+// members.
+type ChannelInfo struct {
+	Name    string
+	URL     string    // From RPL_CHANNEL_URL, if sent.
+	Created time.Time // From RPL_CREATIONTIME, if sent.
+	// Modes holds the modes of the channel, as known from
+	// RPL_CHANNELMODEIS and the MODE messages since.
+	Modes ChannelModes
+}
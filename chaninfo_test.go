@@ -0,0 +1,43 @@
+package ircmessage
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseChannelInfo(t *testing.T) {
+	channel, url, ok := ParseChannelURL(Message{Command: "328", Params: []string{"me", "#chan", "https://example.org/"}})
+	if !ok || channel != "#chan" || url != "https://example.org/" {
+		t.Errorf("unexpected URL %q %q %v", channel, url, ok)
+	}
+	channel, created, ok := ParseCreationTime(Message{Command: "329", Params: []string{"me", "#chan", "1262304000"}})
+	if !ok || channel != "#chan" || !created.Equal(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected creation time %q %v %v", channel, created, ok)
+	}
+	if _, _, ok := ParseCreationTime(Message{Command: "329", Params: []string{"me", "#chan", "soon"}}); ok {
+		t.Error("expecting a malformed time to be rejected")
+	}
+	if _, _, ok := ParseChannelURL(Message{Command: "332", Params: []string{"me", "#chan", "topic"}}); ok {
+		t.Error("expecting RPL_TOPIC not to be a URL")
+	}
+}
+
+func TestMemberTrackerInfo(t *testing.T) {
+	in := ":me!u@h JOIN #chan\r\n" +
+		":irc.example.com 328 me #CHAN :https://example.org/\r\n" +
+		":irc.example.com 329 me #chan 1262304000\r\n" +
+		":irc.example.com 328 me #other :https://example.com/\r\n"
+	tr := NewMemberTracker("me")
+	s := NewScanner(strings.NewReader(in))
+	for s.Scan() {
+		tr.Handle(s.Message())
+	}
+	info, ok := tr.Info("#chan")
+	if !ok || info.Name != "#chan" || info.URL != "https://example.org/" || info.Created.Unix() != 1262304000 {
+		t.Errorf("unexpected info %+v %v", info, ok)
+	}
+	if _, ok := tr.Info("#other"); ok {
+		t.Error("expecting no info for a channel not joined")
+	}
+}
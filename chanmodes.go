@@ -0,0 +1,103 @@
+package ircmessage
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LastModeChange returns the last change of mode among changes, which
+// determines whether it ended up set, and with what parameter. For
+// example, the key set by a MODE message is the Param of the last change
+// of 'k' if its Add is true.
+func LastModeChange(changes []ModeChange, mode byte) (ModeChange, bool) {
+	for i := len(changes) - 1; i >= 0; i-- {
+		if changes[i].Mode == mode {
+			return changes[i], true
+		}
+	}
+	return ModeChange{}, false
+}
+
+// ChannelModes is a snapshot of the modes set on a channel, each mapped to
+// its parameter, or to an empty string if it has none. List modes, such as
+// bans, and membership modes are not included. The operations on a
+// ChannelModes return new snapshots rather than modifying it.
+type ChannelModes map[byte]string
+
+// ParseChannelModeIs parses RPL_CHANNELMODEIS (324), the reply to a MODE
+// query, into the channel and its modes. The modes that take a parameter
+// are those of isupport, which may be nil. It returns false for any other
+// message.
+func ParseChannelModeIs(m Message, isupport *ISupport) (channel string, modes ChannelModes, ok bool) {
+	if m.Command != "324" || len(m.Params) < 3 {
+		return "", nil, false
+	}
+	return m.Params[1], ChannelModes{}.Apply(ParseModeChanges(m.Params[2:], isupport), isupport), true
+}
+
+// Apply returns the modes resulting from changes, as parsed from a MODE
+// message with the same isupport, which may be nil.
+func (cm ChannelModes) Apply(changes []ModeChange, isupport *ISupport) ChannelModes {
+	members, _ := isupport.Prefix()
+	list, _, _, _ := isupport.ChanModes()
+	n := make(ChannelModes, len(cm))
+	for k, v := range cm {
+		n[k] = v
+	}
+	for _, c := range changes {
+		switch {
+		case strings.IndexByte(members+list, c.Mode) >= 0:
+		case c.Add:
+			n[c.Mode] = c.Param
+		default:
+			delete(n, c.Mode)
+		}
+	}
+	return n
+}
+
+// Has reports whether mode is set.
+func (cm ChannelModes) Has(mode byte) bool {
+	_, ok := cm[mode]
+	return ok
+}
+
+// Key returns the channel key, set by +k.
+func (cm ChannelModes) Key() (string, bool) {
+	k, ok := cm['k']
+	return k, ok
+}
+
+// Limit returns the user limit, set by +l.
+func (cm ChannelModes) Limit() (int, bool) {
+	l, err := strconv.Atoi(cm['l'])
+	return l, err == nil && cm.Has('l')
+}
+
+// Moderated reports whether only voiced users and above may speak, set by
+// +m.
+func (cm ChannelModes) Moderated() bool { return cm.Has('m') }
+
+// InviteOnly reports whether users must be invited to join, set by +i.
+func (cm ChannelModes) InviteOnly() bool { return cm.Has('i') }
+
+// Secret reports whether the channel is hidden from users outside it, set
+// by +s.
+func (cm ChannelModes) Secret() bool { return cm.Has('s') }
+
+// String returns the modes as a mode string followed by the parameters,
+// such as "+klnt key 10", sorted by mode.
+func (cm ChannelModes) String() string {
+	modes := make([]string, 0, len(cm))
+	for k := range cm {
+		modes = append(modes, string(k))
+	}
+	sortStrings(modes)
+	s := "+" + strings.Join(modes, "")
+	for _, k := range modes {
+		if p := cm[k[0]]; p != "" {
+			s += " " + p
+		}
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+package ircmessage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLastModeChange(t *testing.T) {
+	changes := ParseModeChanges([]string{"+k-m+k", "old", "new"}, nil)
+	if c, ok := LastModeChange(changes, 'k'); !ok || !c.Add || c.Param != "new" {
+		t.Errorf("expecting the key new, got %+v %v", c, ok)
+	}
+	if c, ok := LastModeChange(changes, 'm'); !ok || c.Add {
+		t.Errorf("expecting -m, got %+v %v", c, ok)
+	}
+	if _, ok := LastModeChange(changes, 'i'); ok {
+		t.Error("expecting no change of i")
+	}
+}
+
+var channelModesTests = []struct {
+	changes  []string
+	expected string
+}{
+	{[]string{"+nt"}, "+nt"},
+	{[]string{"+kl", "secret", "10"}, "+klnt secret 10"},
+	{[]string{"+ob-t", "op", "*!*@*"}, "+kln secret 10"},
+	{[]string{"-k+im", "secret"}, "+ilmn 10"},
+	{[]string{"-l"}, "+imn"},
+}
+
+func TestChannelModes(t *testing.T) {
+	var modes ChannelModes
+	for i, tt := range channelModesTests {
+		before := modes
+		modes = modes.Apply(ParseModeChanges(tt.changes, nil), nil)
+		if got := modes.String(); got != tt.expected {
+			t.Errorf("%d. expecting %s, got %s", i, tt.expected, got)
+		}
+		if i == 1 {
+			key, _ := modes.Key()
+			limit, ok := modes.Limit()
+			if key != "secret" || !ok || limit != 10 || before.Has('k') {
+				t.Errorf("expecting key secret and limit 10, got %q %d %v", key, limit, ok)
+			}
+		}
+	}
+	if !modes.Moderated() || !modes.InviteOnly() || modes.Secret() {
+		t.Errorf("expecting +im without +s, got %s", modes)
+	}
+	if _, ok := modes.Limit(); ok {
+		t.Error("expecting no limit")
+	}
+}
+
+func TestMemberTrackerModes(t *testing.T) {
+	in := ":me!u@h JOIN #chan\r\n" +
+		":irc.example.com 324 me #chan +ntl 25\r\n" +
+		":op!o@oh MODE #chan +k-l key\r\n"
+	tr := NewMemberTracker("me")
+	s := NewScanner(strings.NewReader(in))
+	for s.Scan() {
+		tr.Handle(s.Message())
+	}
+	info, _ := tr.Info("#chan")
+	if got := info.Modes.String(); got != "+knt key" {
+		t.Errorf("expecting +knt key, got %s", got)
+	}
+}
@@ -0,0 +1,134 @@
+package ircmessage
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// serverTimeLayout is the format of the server-time tag as per:
+// https://ircv3.net/specs/extensions/server-time
+const serverTimeLayout = "2006-01-02T15:04:05.000Z"
+
+// messageTime returns the time given by the server-time tag of m.
+func messageTime(m Message) (time.Time, bool) {
+	v, ok := m.Tags["time"]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// HistorySelector identifies a point in a conversation for CHATHISTORY
+// requests, either by message ID or by timestamp. The zero value selects no
+// particular point and is written as "*".
+type HistorySelector struct {
+	MsgID string
+	Time  time.Time
+}
+
+// String returns the selector as used in CHATHISTORY parameters.
+func (s HistorySelector) String() string {
+	switch {
+	case s.MsgID != "":
+		return "msgid=" + s.MsgID
+	case !s.Time.IsZero():
+		return "timestamp=" + s.Time.UTC().Format(serverTimeLayout)
+	}
+	return "*"
+}
+
+func chatHistory(sub string, args ...string) Message {
+	return Message{Command: "CHATHISTORY", Params: append([]string{sub}, args...)}
+}
+
+// ChatHistoryLatest requests the most recent messages in target, stopping at
+// the point selected by since, which may be the zero HistorySelector.
+func ChatHistoryLatest(target string, since HistorySelector, limit int) Message {
+	return chatHistory("LATEST", target, since.String(), strconv.Itoa(limit))
+}
+
+// ChatHistoryBefore requests messages in target before the selected point.
+func ChatHistoryBefore(target string, sel HistorySelector, limit int) Message {
+	return chatHistory("BEFORE", target, sel.String(), strconv.Itoa(limit))
+}
+
+// ChatHistoryAfter requests messages in target after the selected point.
+func ChatHistoryAfter(target string, sel HistorySelector, limit int) Message {
+	return chatHistory("AFTER", target, sel.String(), strconv.Itoa(limit))
+}
+
+// ChatHistoryAround requests messages in target either side of the selected
+// point.
+func ChatHistoryAround(target string, sel HistorySelector, limit int) Message {
+	return chatHistory("AROUND", target, sel.String(), strconv.Itoa(limit))
+}
+
+// ChatHistoryBetween requests messages in target between two points.
+func ChatHistoryBetween(target string, from, to HistorySelector, limit int) Message {
+	return chatHistory("BETWEEN", target, from.String(), to.String(), strconv.Itoa(limit))
+}
+
+// ChatHistoryTargets requests the conversations with activity between two
+// times.
+func ChatHistoryTargets(from, to time.Time, limit int) Message {
+	return chatHistory("TARGETS",
+		HistorySelector{Time: from}.String(),
+		HistorySelector{Time: to}.String(),
+		strconv.Itoa(limit),
+	)
+}
+
+// ChatHistory is the result of a CHATHISTORY request.
+type ChatHistory struct {
+	// Target is the conversation the history belongs to, or empty for
+	// the result of a TARGETS request.
+	Target   string
+	Messages []Message
+}
+
+// ChatHistoryCollector gathers the batches sent in reply to CHATHISTORY
+// requests. Multiline batches within the history are joined into single
+// messages. The zero value is ready to use.
+type ChatHistoryCollector struct {
+	batches BatchCollector
+}
+
+// Add feeds a message to the collector. It reports whether the message was
+// consumed as part of a batch, and returns the history once a chathistory
+// batch has ended. Batches of other types are consumed and discarded; use a
+// BatchCollector directly to handle them alongside chathistory.
+func (c *ChatHistoryCollector) Add(m Message) (*ChatHistory, bool) {
+	b, consumed := c.batches.Add(m)
+	if b == nil {
+		return nil, consumed
+	}
+	var h *ChatHistory
+	switch b.Type {
+	case "chathistory":
+		h = &ChatHistory{}
+		if len(b.Params) > 0 {
+			h.Target = b.Params[0]
+		}
+	case "draft/chathistory-targets":
+		h = &ChatHistory{}
+	default:
+		return nil, consumed
+	}
+	h.Messages = append(h.Messages, b.Messages...)
+	for _, nested := range b.Batches {
+		if joined, err := JoinMultiline(nested); err == nil {
+			h.Messages = append(h.Messages, joined)
+		}
+	}
+	sort.SliceStable(h.Messages, func(i, j int) bool {
+		ti, _ := messageTime(h.Messages[i])
+		tj, _ := messageTime(h.Messages[j])
+		return ti.Before(tj)
+	})
+	return h, consumed
+}
@@ -0,0 +1,57 @@
+package ircmessage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChatHistoryBuilders(t *testing.T) {
+	at := time.Date(2019, 1, 4, 14, 33, 26, 123e6, time.UTC)
+	tests := []struct {
+		m        Message
+		expected string
+	}{
+		{ChatHistoryLatest("#chan", HistorySelector{}, 50), "CHATHISTORY LATEST #chan * 50\r\n"},
+		{ChatHistoryBefore("#chan", HistorySelector{MsgID: "abc"}, 10), "CHATHISTORY BEFORE #chan msgid=abc 10\r\n"},
+		{ChatHistoryAfter("nick", HistorySelector{Time: at}, 10), "CHATHISTORY AFTER nick timestamp=2019-01-04T14:33:26.123Z 10\r\n"},
+		{ChatHistoryAround("#chan", HistorySelector{MsgID: "abc"}, 5), "CHATHISTORY AROUND #chan msgid=abc 5\r\n"},
+		{ChatHistoryBetween("#chan", HistorySelector{MsgID: "a"}, HistorySelector{MsgID: "b"}, 5), "CHATHISTORY BETWEEN #chan msgid=a msgid=b 5\r\n"},
+		{ChatHistoryTargets(at, at.Add(time.Hour), 3), "CHATHISTORY TARGETS timestamp=2019-01-04T14:33:26.123Z timestamp=2019-01-04T15:33:26.123Z 3\r\n"},
+	}
+	for i, tt := range tests {
+		b, err := AppendMessage(nil, tt.m)
+		if err != nil || string(b) != tt.expected {
+			t.Errorf("%d. expecting %q, got %q (%v)", i, tt.expected, b, err)
+		}
+	}
+}
+
+func TestChatHistoryCollector(t *testing.T) {
+	msgs := []Message{
+		{Command: "BATCH", Params: []string{"+h", "chathistory", "#chan"}},
+		{Tags: map[string]string{"batch": "h", "time": "2019-01-04T14:33:27.000Z"}, Command: "PRIVMSG", Params: []string{"#chan", "second"}},
+		{Tags: map[string]string{"batch": "h", "time": "2019-01-04T14:33:26.000Z"}, Command: "PRIVMSG", Params: []string{"#chan", "first"}},
+		{Tags: map[string]string{"batch": "h", "time": "2019-01-04T14:33:28.000Z"}, Command: "BATCH", Params: []string{"+m", "draft/multiline", "#chan"}},
+		{Tags: map[string]string{"batch": "m"}, Command: "PRIVMSG", Params: []string{"#chan", "third"}},
+		{Tags: map[string]string{"batch": "m"}, Command: "PRIVMSG", Params: []string{"#chan", "lines"}},
+		{Command: "BATCH", Params: []string{"-m"}},
+		{Command: "BATCH", Params: []string{"-h"}},
+	}
+	var (
+		c ChatHistoryCollector
+		h *ChatHistory
+	)
+	for _, m := range msgs {
+		if done, _ := c.Add(m); done != nil {
+			h = done
+		}
+	}
+	if h == nil || h.Target != "#chan" || len(h.Messages) != 3 {
+		t.Fatalf("unexpected history %+v", h)
+	}
+	for i, text := range []string{"first", "second", "third\nlines"} {
+		if got := h.Messages[i].Params[1]; got != text {
+			t.Errorf("%d. expecting %q, got %q", i, text, got)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package ircmessage
+
+import "time"
+
+// Clock is a source of the current time, which the time-dependent features
+// of the package, such as RateLimitedWriter, LagMonitor, EchoTracker and
+// Replayer, consult instead of the time package, so that tests can drive
+// them deterministically. The irctest package provides a manual Clock.
+type Clock interface {
+	Now() time.Time
+	// Sleep pauses the calling goroutine for at least d.
+	Sleep(d time.Duration)
+}
+
+// SystemClock is the Clock used by default, backed by the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
@@ -0,0 +1,23 @@
+package ircmessage
+
+import (
+	"testing"
+	"time"
+)
+
+// funcClock is a Clock built from functions, for tests.
+type funcClock struct {
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func (c funcClock) Now() time.Time        { return c.now() }
+func (c funcClock) Sleep(d time.Duration) { c.sleep(d) }
+
+func TestSystemClock(t *testing.T) {
+	before := time.Now()
+	SystemClock.Sleep(time.Millisecond)
+	if d := SystemClock.Now().Sub(before); d < time.Millisecond {
+		t.Errorf("expecting at least 1ms to pass, got %v", d)
+	}
+}
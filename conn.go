@@ -0,0 +1,92 @@
+package ircmessage
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Conn is a message oriented IRC connection, combining a Scanner and an
+// Encoder over a net.Conn. The size limits of the Scanner apply to incoming
+// messages and those of the Encoder to outgoing messages.
+//
+// ReadMessage and WriteMessage may be called concurrently with each other,
+// and WriteMessage may be called from multiple goroutines.
+type Conn struct {
+	// ReadTimeout and WriteTimeout, if non-zero, are the deadlines applied
+	// to each call to ReadMessage and WriteMessage respectively.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	conn    net.Conn
+	scanner *Scanner
+	wmu     sync.Mutex
+	enc     *Encoder
+	ka      *keepalive // Nil unless keepalive is enabled.
+	once    sync.Once
+
+	flushPolicy   FlushPolicy
+	flushInterval time.Duration
+	flushTimer    *time.Timer // Pending FlushInterval flush, guarded by wmu.
+}
+
+// NewConn returns a new Conn using c as its transport.
+func NewConn(c net.Conn) *Conn {
+	return &Conn{
+		conn:    c,
+		scanner: NewScanner(c),
+		enc:     NewEncoder(c),
+	}
+}
+
+// ReadMessage reads the next message from the connection. It returns io.EOF
+// when the connection is closed cleanly between messages. As with Scanner,
+// any error is permanent.
+func (c *Conn) ReadMessage() (Message, error) {
+	if c.ReadTimeout > 0 {
+		if err := c.conn.SetReadDeadline(time.Now().Add(c.ReadTimeout)); err != nil {
+			return Message{}, err
+		}
+	}
+	if !c.scanner.Scan() {
+		if c.ka != nil && c.ka.isStalled() {
+			return Message{}, ErrStalled
+		}
+		if err := c.scanner.Err(); err != nil {
+			return Message{}, err
+		}
+		return Message{}, io.EOF
+	}
+	m := c.scanner.Message()
+	if c.ka != nil {
+		c.handleKeepalive(m)
+	}
+	return m, nil
+}
+
+// WriteMessage encodes m and writes it to the connection.
+func (c *Conn) WriteMessage(m Message) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.WriteTimeout > 0 {
+		if err := c.conn.SetWriteDeadline(time.Now().Add(c.WriteTimeout)); err != nil {
+			return err
+		}
+	}
+	if err := c.enc.Encode(m); err != nil {
+		return err
+	}
+	if c.flushPolicy == FlushInterval && c.flushTimer == nil {
+		c.flushTimer = time.AfterFunc(c.flushInterval, func() { c.Flush() })
+	}
+	return nil
+}
+
+// Close closes the underlying connection and stops any keepalive.
+func (c *Conn) Close() error {
+	if c.ka != nil {
+		c.once.Do(func() { close(c.ka.done) })
+	}
+	return c.conn.Close()
+}
@@ -0,0 +1,43 @@
+package ircmessage
+
+import (
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConn(t *testing.T) {
+	client, server := net.Pipe()
+	c, s := NewConn(client), NewConn(server)
+	c.WriteTimeout = time.Second
+	s.ReadTimeout = time.Second
+	sent := Message{Prefix: "nick", Command: "PRIVMSG", Params: []string{"#chan", "hello there"}}
+	go func() {
+		c.WriteMessage(sent)
+		c.Close()
+	}()
+	m, err := s.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent.Raw = ":nick PRIVMSG #chan :hello there\r\n"
+	if !reflect.DeepEqual(m, sent) {
+		t.Errorf("expecting %v, got %v", sent, m)
+	}
+	if _, err := s.ReadMessage(); err != io.EOF {
+		t.Errorf("expecting %v after close, got %v", io.EOF, err)
+	}
+}
+
+func TestConnReadTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	s := NewConn(server)
+	s.ReadTimeout = 10 * time.Millisecond
+	_, err := s.ReadMessage()
+	if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+		t.Errorf("expecting a timeout error, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package ircmessage
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// HistoryDeduper recognises messages seen before, such as those replayed by
+// CHATHISTORY after a reconnect, so that they are not shown twice. Messages
+// are identified by their msgid tag, or failing that by their time tag
+// together with their sender, command and text, and messages with neither
+// tag are never taken to be duplicates. A window of the most recent
+// identities is kept for each target, and can be saved and loaded so that
+// it survives a restart.
+//
+// A HistoryDeduper is safe for concurrent use.
+type HistoryDeduper struct {
+	mu       sync.Mutex
+	window   int
+	isupport isupportRef
+	targets  map[string]*seenWindow
+}
+
+// seenWindow holds the identities seen for a target, oldest first.
+type seenWindow struct {
+	order []string
+	seen  map[string]bool
+}
+
+// NewHistoryDeduper returns a HistoryDeduper that remembers the last window
+// messages of each target.
+func NewHistoryDeduper(window int) *HistoryDeduper {
+	if window < 1 {
+		window = 1
+	}
+	return &HistoryDeduper{window: window, targets: make(map[string]*seenWindow)}
+}
+
+// SetISupport sets the server parameters whose casemapping target names are
+// compared with.
+func (d *HistoryDeduper) SetISupport(isupport *ISupport) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.isupport.isupport = isupport
+}
+
+// SetNetworkConfig sets the store whose current casemapping target names are
+// compared with, in place of any set by SetISupport.
+func (d *HistoryDeduper) SetNetworkConfig(c *NetworkConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.isupport.config = c
+}
+
+// Seen reports whether m has been seen before, recording it if not.
+func (d *HistoryDeduper) Seen(m Message) bool {
+	id := messageIdentity(m)
+	if id == "" {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	w := d.target(m)
+	if w.seen[id] {
+		return true
+	}
+	w.add(id, d.window)
+	return false
+}
+
+// Stage returns a pipeline Stage that drops messages seen before.
+func (d *HistoryDeduper) Stage() Stage {
+	return func(m Message) (Message, bool) {
+		return m, !d.Seen(m)
+	}
+}
+
+func (d *HistoryDeduper) target(m Message) *seenWindow {
+	var name string
+	if len(m.Params) > 0 {
+		name = d.isupport.get().Fold(m.Params[0])
+	}
+	w, ok := d.targets[name]
+	if !ok {
+		w = &seenWindow{seen: make(map[string]bool)}
+		d.targets[name] = w
+	}
+	return w
+}
+
+func (w *seenWindow) add(id string, window int) {
+	if len(w.order) == window {
+		delete(w.seen, w.order[0])
+		w.order = w.order[1:]
+	}
+	w.order = append(w.order, id)
+	w.seen[id] = true
+}
+
+// messageIdentity returns the identity of m, or an empty string if it has
+// none.
+func messageIdentity(m Message) string {
+	if id := m.Tags["msgid"]; id != "" {
+		return "msgid " + id
+	}
+	t := m.Tags["time"]
+	if t == "" {
+		return ""
+	}
+	var text string
+	if len(m.Params) > 1 {
+		text = m.Params[len(m.Params)-1]
+	}
+	return "time " + t + " " + m.Prefix + " " + m.Command + " " + text
+}
+
+// Save writes the remembered identities to w, in a form Load reads.
+func (d *HistoryDeduper) Save(w io.Writer) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state := make(map[string][]string, len(d.targets))
+	for name, t := range d.targets {
+		state[name] = t.order
+	}
+	return json.NewEncoder(w).Encode(state)
+}
+
+// Load adds the identities saved by Save to those remembered, keeping the
+// most recent within the window of each target.
+func (d *HistoryDeduper) Load(r io.Reader) error {
+	var state map[string][]string
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for name, ids := range state {
+		w, ok := d.targets[name]
+		if !ok {
+			w = &seenWindow{seen: make(map[string]bool)}
+			d.targets[name] = w
+		}
+		for _, id := range ids {
+			if !w.seen[id] {
+				w.add(id, d.window)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package ircmessage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHistoryDeduper(t *testing.T) {
+	d := NewHistoryDeduper(2)
+	msg := func(target, msgid, time, text string) Message {
+		tags := map[string]string{}
+		if msgid != "" {
+			tags["msgid"] = msgid
+		}
+		if time != "" {
+			tags["time"] = time
+		}
+		return Message{Tags: tags, Prefix: "n!u@h", Command: "PRIVMSG", Params: []string{target, text}}
+	}
+	steps := []struct {
+		m        Message
+		expected bool
+	}{
+		{msg("#a", "1", "", "one"), false},
+		{msg("#A", "1", "", "one"), true},
+		{msg("#b", "1", "", "one"), false},
+		{msg("#a", "", "2026-10-16T12:00:00.000Z", "two"), false},
+		{msg("#a", "", "2026-10-16T12:00:00.000Z", "two"), true},
+		{msg("#a", "", "2026-10-16T12:00:00.000Z", "other"), false},
+		{msg("#a", "", "", "untimed"), false},
+		{msg("#a", "", "", "untimed"), false},
+		// The window of #a now holds the last two identities only.
+		{msg("#a", "1", "", "one"), false},
+	}
+	for i, s := range steps {
+		if got := d.Seen(s.m); got != s.expected {
+			t.Errorf("%d. expecting %v, got %v", i, s.expected, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := d.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored := NewHistoryDeduper(2)
+	if err := restored.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	s := NewScanner(strings.NewReader("@msgid=1 :n!u@h PRIVMSG #a one\r\n@msgid=1 :n!u@h PRIVMSG #b one\r\n@msgid=3 :n!u@h PRIVMSG #b three\r\n"))
+	s.SetPipeline(Pipeline{restored.Stage()})
+	var got []string
+	for s.Scan() {
+		got = append(got, s.Message().Tags["msgid"])
+	}
+	if strings.Join(got, ",") != "3" {
+		t.Errorf("expecting only msgid 3 after restoring, got %v", got)
+	}
+}
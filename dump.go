@@ -0,0 +1,168 @@
+package ircmessage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dump returns a description of the wire form of m for debugging, with a
+// line for each component giving its byte range, what it is and its bytes,
+// in the manner of a protocol dissector. Tags are broken down into their
+// keys and escape sequences, and the prefix into its parts. Ranges are half
+// open and indented beneath the component that contains them.
+//
+// The Raw field of m is dissected if set, and otherwise its encoding. Dump
+// describes a line as far as it can and notes where it stops making sense.
+func Dump(m Message) string {
+	raw := m.Raw
+	if raw == "" {
+		b, err := AppendMessage(nil, m)
+		if err != nil {
+			return fmt.Sprintf("cannot encode: %v\n", err)
+		}
+		raw = string(b)
+	}
+	d := dumper{raw: raw}
+	fmt.Fprintf(&d.b, "%q\n", raw)
+	// A line may end in a bare LF, as written with TerminatorLF, or CRLF.
+	end := len(strings.TrimSuffix(strings.TrimSuffix(raw, "\n"), "\r"))
+	if !strings.HasSuffix(raw, "\n") {
+		end = len(raw)
+	}
+	pos := 0
+	word := func() int {
+		i := strings.IndexByte(raw[pos:end], ' ')
+		if i < 0 {
+			return end
+		}
+		return pos + i
+	}
+	skip := func() {
+		for pos < end && raw[pos] == ' ' {
+			pos++
+		}
+	}
+	if strings.HasPrefix(raw, "@") {
+		next := word()
+		d.row(0, next, 0, "tags", "")
+		d.tags(1, next)
+		pos = next
+		skip()
+	}
+	if pos < end && raw[pos] == ':' {
+		next := word()
+		d.row(pos, next, 0, "prefix", "")
+		d.prefix(pos+1, next)
+		pos = next
+		skip()
+	}
+	if next := word(); next > pos {
+		d.row(pos, next, 0, "command", "")
+		pos = next
+	} else {
+		d.note(pos, "missing command")
+	}
+	for n := 0; ; n++ {
+		skip()
+		if pos == end {
+			break
+		}
+		if raw[pos] == ':' {
+			d.row(pos, end, 0, "trailing", fmt.Sprintf("param %d = %q", n, raw[pos+1:end]))
+			break
+		}
+		next := word()
+		d.row(pos, next, 0, fmt.Sprintf("param %d", n), "")
+		pos = next
+	}
+	if end == len(raw)-1 {
+		d.row(end, len(raw), 0, "line ending", "bare LF")
+	} else if end < len(raw) {
+		d.row(end, len(raw), 0, "line ending", "")
+	} else {
+		d.note(end, "missing CRLF")
+	}
+	return d.b.String()
+}
+
+type dumper struct {
+	raw string
+	b   strings.Builder
+}
+
+// row describes raw[start:end] as label, indented to the given depth.
+func (d *dumper) row(start, end, depth int, label, note string) {
+	fmt.Fprintf(&d.b, "%4d-%-4d %-20s %q", start, end, strings.Repeat("  ", depth)+label, d.raw[start:end])
+	if note != "" {
+		d.b.WriteString("  " + note)
+	}
+	d.b.WriteByte('\n')
+}
+
+func (d *dumper) note(at int, problem string) {
+	fmt.Fprintf(&d.b, "%4d      %s\n", at, problem)
+}
+
+// tags describes the tags in raw[start:end], which follows the @.
+func (d *dumper) tags(start, end int) {
+	for pos := start; pos <= end; {
+		next := strings.IndexByte(d.raw[pos:end], ';')
+		if next < 0 {
+			next = end
+		} else {
+			next += pos
+		}
+		tag := d.raw[pos:next]
+		k, v, hasValue := strings.Cut(tag, "=")
+		note := fmt.Sprintf("%s = %q", k, unescapeTagValue(v))
+		if k == "" || strings.Contains(v, "=") {
+			note = "malformed"
+		} else if !hasValue {
+			note = k + " with no value"
+		}
+		d.row(pos, next, 1, "tag", note)
+		if hasValue {
+			valueStart := pos + len(k) + 1
+			for i := 0; i < len(v); i++ {
+				if v[i] != '\\' {
+					continue
+				}
+				if i+1 == len(v) {
+					d.row(valueStart+i, valueStart+i+1, 2, "escape", "dangling, dropped")
+					break
+				}
+				note := fmt.Sprintf("%q", unescapeTagValue(v[i:i+2]))
+				if !validTagEscapes(v[i : i+2]) {
+					note = "invalid, backslash dropped"
+				}
+				d.row(valueStart+i, valueStart+i+2, 2, "escape", note)
+				i++
+			}
+		}
+		pos = next + 1
+	}
+}
+
+// prefix describes the parts of the prefix in raw[start:end], which follows
+// the colon.
+func (d *dumper) prefix(start, end int) {
+	p := ParsePrefix(d.raw[start:end])
+	switch {
+	case p == nil:
+		d.note(start, "malformed prefix")
+		return
+	case p.IsServer:
+		d.row(start, end, 1, "server", "")
+		return
+	}
+	pos := start
+	d.row(pos, pos+len(p.Nickname), 1, "nick", "")
+	pos += len(p.Nickname)
+	if pos < end && d.raw[pos] == '!' {
+		d.row(pos+1, pos+1+len(p.User), 1, "user", "")
+		pos += 1 + len(p.User)
+	}
+	if pos < end && d.raw[pos] == '@' {
+		d.row(pos+1, end, 1, "host", "")
+	}
+}
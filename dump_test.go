@@ -0,0 +1,64 @@
+package ircmessage
+
+import "testing"
+
+var dumpTests = []struct {
+	in       Message
+	expected string
+}{
+	{
+		Message{Raw: "@a=x\\sy\\q;b;c=d=e :nick!~u@host PRIVMSG  #c :hi there\r\n"},
+		`"@a=x\\sy\\q;b;c=d=e :nick!~u@host PRIVMSG  #c :hi there\r\n"
+   0-17   tags                 "@a=x\\sy\\q;b;c=d=e"
+   1-9      tag                "a=x\\sy\\q"  a = "x yq"
+   4-6        escape           "\\s"  " "
+   7-9        escape           "\\q"  invalid, backslash dropped
+  10-11     tag                "b"  b with no value
+  12-17     tag                "c=d=e"  malformed
+  18-31   prefix               ":nick!~u@host"
+  19-23     nick               "nick"
+  24-26     user               "~u"
+  27-31     host               "host"
+  32-39   command              "PRIVMSG"
+  41-43   param 0              "#c"
+  44-53   trailing             ":hi there"  param 1 = "hi there"
+  53-55   line ending          "\r\n"
+`,
+	},
+	{
+		Message{Prefix: "irc.example.com", Command: "PING", Params: []string{"x"}},
+		`":irc.example.com PING x\r\n"
+   0-16   prefix               ":irc.example.com"
+   1-16     server             "irc.example.com"
+  17-21   command              "PING"
+  22-23   param 0              "x"
+  23-25   line ending          "\r\n"
+`,
+	},
+	{
+		Message{Raw: ":@ "},
+		`":@ "
+   0-2    prefix               ":@"
+   1      malformed prefix
+   3      missing command
+   3      missing CRLF
+`,
+	},
+	{
+		Message{Raw: "PING x\n"},
+		`"PING x\n"
+   0-4    command              "PING"
+   5-6    param 0              "x"
+   6-7    line ending          "\n"  bare LF
+`,
+	},
+	{Message{}, "cannot encode: message malformed\n"},
+}
+
+func TestDump(t *testing.T) {
+	for i, tt := range dumpTests {
+		if d := Dump(tt.in); d != tt.expected {
+			t.Errorf("%d. expecting\n%s\ngot\n%s", i, tt.expected, d)
+		}
+	}
+}
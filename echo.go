@@ -0,0 +1,114 @@
+package ircmessage
+
+import (
+	"sync"
+	"time"
+)
+
+// EchoTracker correlates messages echoed back by the server under the
+// echo-message capability with the messages sent locally, so that they are
+// not displayed twice.
+//
+// Echoes are matched by label when labeled-response is in use, and otherwise
+// by sender, command, target and text within a time window of sending, with
+// the nicknames and targets compared using the network's casemapping.
+//
+// An EchoTracker is safe for concurrent use.
+type EchoTracker struct {
+	mu       sync.Mutex
+	nick     string
+	isupport isupportRef
+	window   time.Duration
+	sent     []sentMessage
+	clock    Clock
+}
+
+type sentMessage struct {
+	label, command, target, text string
+	at                           time.Time
+}
+
+// NewEchoTracker returns an EchoTracker for a client using nick that
+// forgets sent messages that have not been echoed within window.
+func NewEchoTracker(nick string, window time.Duration) *EchoTracker {
+	return &EchoTracker{nick: nick, window: window, clock: SystemClock}
+}
+
+// SetNick sets the nickname of the client, after it has changed.
+func (e *EchoTracker) SetNick(nick string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.nick = nick
+}
+
+// SetNetworkConfig sets the store whose current casemapping nicknames and
+// targets are compared by.
+func (e *EchoTracker) SetNetworkConfig(config *NetworkConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.isupport.config = config
+}
+
+// SetClock sets the Clock the window is measured by.
+func (e *EchoTracker) SetClock(c Clock) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.clock = c
+}
+
+func newSentMessage(m Message) sentMessage {
+	s := sentMessage{label: m.Tags["label"], command: upperCommand(m.Command)}
+	if len(m.Params) > 0 {
+		s.target = m.Params[0]
+	}
+	if len(m.Params) > 1 {
+		s.text = m.Params[len(m.Params)-1]
+	}
+	return s
+}
+
+// Sent records an outgoing message.
+func (e *EchoTracker) Sent(m Message) {
+	s := newSentMessage(m)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s.at = e.clock.Now()
+	e.expire(s.at)
+	e.sent = append(e.sent, s)
+}
+
+// IsEcho reports whether m is the echo of a message recorded with Sent. A
+// sent message is only matched once.
+func (e *EchoTracker) IsEcho(m Message) bool {
+	r := newSentMessage(m)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.expire(e.clock.Now())
+	is := e.isupport.get()
+	var fromUs bool
+	if p := ParsePrefix(m.Prefix); p != nil && !p.IsServer {
+		fromUs = is.Fold(p.Nickname) == is.Fold(e.nick)
+	}
+	for i, s := range e.sent {
+		var match bool
+		if r.label != "" || s.label != "" {
+			match = r.label == s.label
+		} else {
+			match = fromUs && r.command == s.command && r.text == s.text &&
+				is.Fold(r.target) == is.Fold(s.target)
+		}
+		if match {
+			e.sent = append(e.sent[:i], e.sent[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (e *EchoTracker) expire(now time.Time) {
+	i := 0
+	for i < len(e.sent) && now.Sub(e.sent[i].at) > e.window {
+		i++
+	}
+	e.sent = e.sent[i:]
+}
@@ -0,0 +1,42 @@
+package ircmessage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEchoTracker(t *testing.T) {
+	var now time.Time
+	e := NewEchoTracker("Me", 10*time.Second)
+	e.SetClock(funcClock{now: func() time.Time { return now }})
+	privmsg := func(prefix, text string) Message {
+		return Message{Prefix: prefix, Command: "PRIVMSG", Params: []string{"#chan", text}}
+	}
+	e.Sent(privmsg("", "hello"))
+	e.Sent(Message{Tags: map[string]string{"label": "abc"}, Command: "PRIVMSG", Params: []string{"#chan", "labeled"}})
+	if e.IsEcho(privmsg("other!u@h", "different")) {
+		t.Error("expecting a different message not to be an echo")
+	}
+	if !e.IsEcho(privmsg("me!u@h", "hello")) {
+		t.Error("expecting a matching message to be an echo")
+	}
+	if e.IsEcho(privmsg("me!u@h", "hello")) {
+		t.Error("expecting a sent message to only be matched once")
+	}
+	e.Sent(privmsg("", "hi"))
+	if e.IsEcho(privmsg("someoneelse!u@h", "hi")) {
+		t.Error("expecting the same text from another user not to be an echo")
+	}
+	if !e.IsEcho(Message{Prefix: "ME!u@h", Command: "PRIVMSG", Params: []string{"#CHAN", "hi"}}) {
+		t.Error("expecting nick and target to be compared by casemapping")
+	}
+	labeled := Message{Tags: map[string]string{"label": "abc"}, Prefix: "me!u@h", Command: "PRIVMSG", Params: []string{"#chan", "labeled"}}
+	if !e.IsEcho(labeled) {
+		t.Error("expecting a message with a matching label to be an echo")
+	}
+	e.Sent(privmsg("", "late"))
+	now = now.Add(time.Minute)
+	if e.IsEcho(privmsg("me!u@h", "late")) {
+		t.Error("expecting an echo outside the window not to match")
+	}
+}
@@ -0,0 +1,259 @@
+package ircmessage
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// ErrLineTooLong is returned when the encoder is asked to write a message
+// that would exceed the maximum message size.
+var ErrLineTooLong = errors.New("line too long")
+
+// Encoder writes IRC messages to an output stream. An Encoder is safe for
+// concurrent use.
+type Encoder struct {
+	mu       sync.Mutex
+	w        io.Writer
+	buf      []byte // Re-used between messages.
+	hook     Hook
+	pipeline Pipeline
+	limits   Limits
+	profile  Profile
+	fidelity bool
+	raw      *rawParser // Non-nil once fidelity has been set.
+
+	terminator Terminator
+
+	policy   FlushPolicy
+	interval time.Duration
+	bw       *bufio.Writer // Non-nil once output has been buffered.
+	timer    *time.Timer   // Pending FlushInterval flush.
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, buf: make([]byte, 0, 1024), limits: DefaultLimits}
+}
+
+// Encode writes the wire representation of m, terminated as set by
+// SetTerminator, to the underlying writer, or buffers it as determined by
+// the flush policy. The Raw field of m is ignored unless fidelity is set.
+// Nothing is written if m cannot be encoded.
+func (e *Encoder) Encode(m Message) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.pipeline != nil {
+		var ok bool
+		if m, ok = e.pipeline.Apply(m); !ok {
+			return nil
+		}
+	}
+	b, err := e.buf[:0], error(nil)
+	if e.profile.check(m) != nil {
+		err = ErrMessageMalformed
+	} else if e.fidelity && e.raw.describes(m, e.limits) {
+		b = append(b, m.Raw...)
+	} else {
+		b, err = appendMessage(b, m, e.limits, e.fidelity && m.TrailingColon())
+	}
+	if err != nil {
+		if e.hook != nil {
+			e.hook.Encoded(m.Command, 0, err)
+		}
+		return err
+	}
+	b = e.terminator.terminate(b)
+	e.buf = b
+	err = e.write(b)
+	if e.hook != nil {
+		e.hook.Encoded(m.Command, len(b), err)
+	}
+	return err
+}
+
+// AppendMessage appends the wire representation of m, terminated by CRLF,
+// to dst and returns the extended buffer. The Raw field of m is ignored. If m
+// cannot be encoded, dst is returned unchanged along with the error.
+//
+// AppendMessage does not allocate when dst has sufficient capacity and m has
+// no more than 16 tags, making it suitable for writing from pooled buffers.
+// The message must fit within DefaultLimits.
+func AppendMessage(dst []byte, m Message) ([]byte, error) {
+	return appendMessage(dst, m, DefaultLimits, false)
+}
+
+// appendMessage implements AppendMessage with the given limits. If colon is
+// set, the last parameter is written as a trailing parameter even when it
+// need not be.
+func appendMessage(dst []byte, m Message, limits Limits, colon bool) ([]byte, error) {
+	start := len(dst)
+	if len(m.Tags) > 0 {
+		var small [16]string
+		keys := small[:0]
+		if len(m.Tags) > len(small) {
+			keys = make([]string, 0, len(m.Tags))
+		}
+		for k := range m.Tags {
+			if k == "" || strings.ContainsAny(k, " ;=\r\n\x00") {
+				return dst[:start], ErrMessageMalformed
+			}
+			keys = append(keys, k)
+		}
+		sortStrings(keys)
+		dst = append(dst, runeAt)
+		for i, k := range keys {
+			if i > 0 {
+				dst = append(dst, runeSemicolon)
+			}
+			if k == MalformedTagKey {
+				// Pass malformed tags kept by the Scanner on as they were.
+				v := m.Tags[k]
+				if strings.ContainsAny(v, " \r\n\x00") {
+					return dst[:start], ErrMessageMalformed
+				}
+				dst = append(dst, v...)
+				continue
+			}
+			dst = append(dst, k...)
+			if v := m.Tags[k]; v != "" {
+				if limits.TagValue > 0 && len(v) > limits.TagValue {
+					return dst[:start], ErrTagTooLong
+				}
+				dst = append(dst, runeEquals)
+				dst = appendTagValue(dst, v)
+			}
+		}
+		dst = append(dst, runeSpace)
+		if len(dst)-start > limits.Tags {
+			return dst[:start], ErrLineTooLong
+		}
+	}
+	body := len(dst)
+	if m.Prefix != "" {
+		if !prefixWellFormed(m.Prefix) || strings.ContainsAny(m.Prefix, " \r\n\x00") {
+			return dst[:start], ErrMessageMalformed
+		}
+		dst = append(dst, runeColon)
+		dst = append(dst, m.Prefix...)
+		dst = append(dst, runeSpace)
+	}
+	if m.Command == "" || strings.ContainsAny(m.Command, " :\r\n\x00") {
+		return dst[:start], ErrMessageMalformed
+	}
+	dst = append(dst, m.Command...)
+	// Parameters beyond the limit are folded into the trailing parameter.
+	fold := len(m.Params)
+	if limits.MaxParams > 0 && fold > limits.MaxParams {
+		fold = limits.MaxParams - 1
+	}
+	for i, p := range m.Params {
+		if strings.ContainsAny(p, "\r\n\x00") {
+			return dst[:start], ErrMessageMalformed
+		}
+		trailing := p == "" || p[0] == runeColon || strings.Contains(p, tokenSpace)
+		if trailing && i != len(m.Params)-1 && i < fold {
+			return dst[:start], ErrMessageMalformed
+		}
+		dst = append(dst, runeSpace)
+		if trailing && i < fold || i == fold || colon && i == len(m.Params)-1 && i < fold {
+			dst = append(dst, runeColon)
+		}
+		dst = append(dst, p...)
+	}
+	dst = append(dst, '\r', '\n')
+	if len(dst)-body > limits.Body {
+		return dst[:start], ErrLineTooLong
+	}
+	return dst, nil
+}
+
+// sortStrings is an insertion sort, which unlike sort.Strings does not
+// cause keys to escape to the heap. Messages rarely carry more than a
+// handful of tags.
+func sortStrings(keys []string) {
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && keys[j] < keys[j-1]; j-- {
+			keys[j], keys[j-1] = keys[j-1], keys[j]
+		}
+	}
+}
+
+// appendTagValue appends v to dst, escaped as per:
+// http://ircv3.net/specs/core/message-tags-3.2.html#escaping-values
+func appendTagValue(dst []byte, v string) []byte {
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case ';':
+			dst = append(dst, `\:`...)
+		case ' ':
+			dst = append(dst, `\s`...)
+		case '\\':
+			dst = append(dst, `\\`...)
+		case '\r':
+			dst = append(dst, `\r`...)
+		case '\n':
+			dst = append(dst, `\n`...)
+		default:
+			dst = append(dst, v[i])
+		}
+	}
+	return dst
+}
+
+// unescapeTagValue reverses the escaping applied by appendTagValue. As the
+// spec requires, the backslash of an invalid escape sequence is dropped,
+// leaving the character after it, as is a backslash ending the value.
+func unescapeTagValue(v string) string {
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	b := appendUnescapedTagValue(make([]byte, 0, len(v)), v)
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// appendUnescapedTagValue appends v to dst with its escaping reversed.
+func appendUnescapedTagValue(dst []byte, v string) []byte {
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' {
+			dst = append(dst, v[i])
+			continue
+		}
+		if i++; i == len(v) {
+			break
+		}
+		switch v[i] {
+		case ':':
+			dst = append(dst, ';')
+		case 's':
+			dst = append(dst, ' ')
+		case '\\':
+			dst = append(dst, '\\')
+		case 'r':
+			dst = append(dst, '\r')
+		case 'n':
+			dst = append(dst, '\n')
+		default:
+			dst = append(dst, v[i])
+		}
+	}
+	return dst
+}
+
+// validTagEscapes reports whether every backslash in the tag value v begins
+// a valid escape sequence.
+func validTagEscapes(v string) bool {
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' {
+			continue
+		}
+		if i++; i == len(v) || strings.IndexByte(`:s\rn`, v[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package ircmessage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var encoderTests = []struct {
+	in       Message
+	expected string
+	err      error
+}{
+	{Message{Command: "PING"}, "PING\r\n", nil},
+	{
+		Message{Prefix: "nick!user@host", Command: "PRIVMSG", Params: []string{"#chan", "hello there"}},
+		":nick!user@host PRIVMSG #chan :hello there\r\n",
+		nil,
+	},
+	{Message{Command: "MODE", Params: []string{"#chan", "+o", "nick"}}, "MODE #chan +o nick\r\n", nil},
+	{Message{Command: "PRIVMSG", Params: []string{"#chan", ""}}, "PRIVMSG #chan :\r\n", nil},
+	{Message{Command: "PRIVMSG", Params: []string{"#chan", ":)"}}, "PRIVMSG #chan ::)\r\n", nil},
+	{
+		Message{Tags: map[string]string{"b": "x y;z\\", "a": ""}, Command: "TAGMSG", Params: []string{"#chan"}},
+		"@a;b=x\\sy\\:z\\\\ TAGMSG #chan\r\n",
+		nil,
+	},
+	{Message{}, "", ErrMessageMalformed},
+	{Message{Command: "PRIVMSG", Params: []string{"two words", "text"}}, "", ErrMessageMalformed},
+	{Message{Command: "PRIVMSG", Params: []string{"#chan", "line\r\nQUIT"}}, "", ErrMessageMalformed},
+	{Message{Command: "PRIVMSG", Params: []string{"#chan", strings.Repeat("a", 500)}}, "", ErrLineTooLong},
+	{Message{Prefix: "!user@host", Command: "PING"}, "", ErrMessageMalformed},
+	{Message{Prefix: "@host", Command: "PING"}, "", ErrMessageMalformed},
+	{Message{Prefix: "nick host", Command: "PING"}, "", ErrMessageMalformed},
+	{Message{Prefix: "nick!", Command: "PING"}, "", ErrMessageMalformed},
+	{Message{Prefix: "nick@", Command: "PING"}, "", ErrMessageMalformed},
+	{Message{Prefix: "a@b!c", Command: "PING"}, "", ErrMessageMalformed},
+	{Message{Prefix: "n!u!x@h", Command: "PING"}, "", ErrMessageMalformed},
+	{Message{Prefix: "n!u@h@x", Command: "PING"}, "", ErrMessageMalformed},
+	{Message{Prefix: "nick@host", Command: "PING"}, ":nick@host PING\r\n", nil},
+	{Message{Prefix: "nick!user", Command: "PING"}, ":nick!user PING\r\n", nil},
+	{Message{Prefix: "irc.example.com", Command: "PING"}, ":irc.example.com PING\r\n", nil},
+	{Message{Prefix: (&Prefix{Nickname: "nick", User: "user", Host: "host"}).String(), Command: "PING"}, ":nick!user@host PING\r\n", nil},
+}
+
+func TestEncoder(t *testing.T) {
+	for i, tt := range encoderTests {
+		var buf bytes.Buffer
+		err := NewEncoder(&buf).Encode(tt.in)
+		if err != tt.err {
+			t.Errorf("%d. expecting error %v, got %v", i, tt.err, err)
+		}
+		if buf.String() != tt.expected {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, buf.String())
+		}
+	}
+}
+
+func TestEncoderRoundTrip(t *testing.T) {
+	m := Message{
+		Tags:    map[string]string{"k": "semi;colon space\\slash"},
+		Prefix:  "nick!user@host",
+		Command: "PRIVMSG",
+		Params:  []string{"#chan", "hello there"},
+	}
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatal(err)
+	}
+	m.Raw = buf.String()
+	s := NewScanner(&buf)
+	if !s.Scan() {
+		t.Fatalf("scan failed: %v", s.Err())
+	}
+	if got := s.Message(); got.String() != m.String() {
+		t.Errorf("expecting %v, got %v", m, got)
+	}
+}
+
+func TestAppendMessageAllocs(t *testing.T) {
+	m := Message{
+		Tags:    map[string]string{"time": "2017-09-26T00:00:00.000Z", "msgid": "abc", "+typing": "active"},
+		Prefix:  "nick!user@host",
+		Command: "PRIVMSG",
+		Params:  []string{"#chan", "hello there"},
+	}
+	buf := make([]byte, 0, 1024)
+	allocs := testing.AllocsPerRun(100, func() {
+		var err error
+		if buf, err = AppendMessage(buf[:0], m); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expecting no allocations, got %v", allocs)
+	}
+	expected := "@+typing=active;msgid=abc;time=2017-09-26T00:00:00.000Z :nick!user@host PRIVMSG #chan :hello there\r\n"
+	if string(buf) != expected {
+		t.Errorf("expecting %q, got %q", expected, buf)
+	}
+	if out, err := AppendMessage([]byte("keep"), Message{}); err != ErrMessageMalformed || string(out) != "keep" {
+		t.Errorf("expecting dst to be unchanged on error, got %q (%v)", out, err)
+	}
+}
+
+var terminatorTests = []struct {
+	t        Terminator
+	expected string
+}{
+	{TerminatorCRLF, "PING a\r\nPING b\r\n"},
+	{TerminatorLF, "PING a\nPING b\n"},
+	{TerminatorNone, "PING aPING b"},
+}
+
+func TestEncoderTerminator(t *testing.T) {
+	for i, tt := range terminatorTests {
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		e.SetTerminator(tt.t)
+		e.SetFidelity(true)
+		s := NewScanner(strings.NewReader("PING a\r\n"))
+		s.Scan()
+		for _, m := range []Message{s.Message(), {Command: "PING", Params: []string{"b"}}} {
+			if err := e.Encode(m); err != nil {
+				t.Fatalf("%d. %v", i, err)
+			}
+		}
+		if buf.String() != tt.expected {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package ircmessage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Specific parse errors. A Scanner returns them wrapped in a *ParseError,
+// which also matches ErrMessageMalformed with errors.Is, apart from
+// ErrMissingCRLF, which matches io.ErrUnexpectedEOF.
+var (
+	ErrBadTag        = errors.New("malformed tag")
+	ErrBadPrefix     = errors.New("malformed prefix")
+	ErrBadCommand    = errors.New("malformed command")
+	ErrEmptyCommand  = errors.New("empty command")
+	ErrTooManyParams = errors.New("too many parameters")
+	ErrTagTooLong    = errors.New("tag value too long")
+	ErrMissingCRLF   = errors.New("missing CRLF")
+)
+
+// ParseError describes a line the Scanner could not parse.
+type ParseError struct {
+	Err    error  // The specific error, or ErrMessageMalformed.
+	Offset int    // The number of bytes read when the error was detected.
+	Line   string // The bytes of the line read.
+
+	// Partial holds the tags, prefix, command and params extracted from
+	// Line before the error, and is otherwise empty. Malformed tags are
+	// left out and Raw is not set.
+	Partial Message
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v at byte %d", e.Err, e.Offset)
+}
+
+// Unwrap returns the specific error along with the broader class it belongs
+// to, ErrMessageMalformed or io.ErrUnexpectedEOF.
+func (e *ParseError) Unwrap() []error {
+	if e.Err == ErrMissingCRLF {
+		return []error{e.Err, io.ErrUnexpectedEOF}
+	}
+	return []error{e.Err, ErrMessageMalformed}
+}
+
+// errEmptyLine is returned by next for a line with nothing on it, which
+// Scan skips.
+var errEmptyLine = errors.New("empty line")
+
+// parseError wraps err, returned while parsing, with the context of the
+// line being read. Errors other than parse errors are returned unchanged.
+func (s *Scanner) parseError(err error) error {
+	switch err {
+	case io.ErrUnexpectedEOF:
+		err = ErrMissingCRLF
+	case ErrMessageMalformed, ErrLineTooLong, ErrBadTag, ErrBadPrefix,
+		ErrBadCommand, ErrEmptyCommand, ErrTooManyParams, ErrTagTooLong:
+	default:
+		return err
+	}
+	line := string(s.rawBuf)
+	return &ParseError{Err: err, Offset: len(line), Line: line, Partial: s.parsed.message(line)}
+}
+
+// parsed records the components of a line extracted so far.
+type parsed struct {
+	tags, prefix, command, params span
+}
+
+// message builds a Message from the components of raw recorded in p.
+func (p parsed) message(raw string) Message {
+	m := Message{
+		Prefix:  raw[p.prefix.start:p.prefix.end],
+		Command: raw[p.command.start:p.command.end],
+		Params:  splitParams(raw[p.params.start:p.params.end]),
+	}
+	if p.tags.start != p.tags.end {
+		m.Tags = make(map[string]string)
+		parseTags(m.Tags, raw[p.tags.start:p.tags.end], nil, TagsDrop, false)
+	}
+	return m
+}
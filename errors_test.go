@@ -0,0 +1,85 @@
+package ircmessage
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var parseErrorTests = []struct {
+	in     string
+	err    error
+	offset int
+}{
+	{"@a=b\r\n", ErrBadTag, 4},
+	{"@a=b=c PING\r\n", ErrBadTag, 13},
+	{":\r\n", ErrBadPrefix, 1},
+	{": PING\r\n", ErrBadPrefix, 2},
+	{":n \r\n", ErrEmptyCommand, 5},
+	{"PING", ErrMissingCRLF, 4},
+	{"PRIVMSG #c :" + strings.Repeat("a", 500) + "\r\n", ErrLineTooLong, 513},
+}
+
+func TestParseError(t *testing.T) {
+	for i, tt := range parseErrorTests {
+		s := NewScanner(strings.NewReader(tt.in))
+		for s.Scan() {
+		}
+		err := s.Err()
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. expecting error %v, got %v", i, tt.err, err)
+		}
+		broad := ErrMessageMalformed
+		if tt.err == ErrMissingCRLF {
+			broad = io.ErrUnexpectedEOF
+		}
+		if !errors.Is(err, broad) {
+			t.Errorf("%d. expecting %v to match %v", i, err, broad)
+		}
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Fatalf("%d. expecting a *ParseError, got %T", i, err)
+		}
+		if pe.Offset != tt.offset || !strings.HasPrefix(tt.in, pe.Line) || len(pe.Line) != tt.offset {
+			t.Errorf("%d. expecting offset %d, got %d %q", i, tt.offset, pe.Offset, pe.Line)
+		}
+	}
+}
+
+func TestScannerSkipsEmptyLines(t *testing.T) {
+	s := NewScanner(strings.NewReader("\r\nPING\r\n\r\n"))
+	var n int
+	for s.Scan() {
+		n++
+	}
+	if n != 1 || s.Err() != nil {
+		t.Errorf("expecting 1 message and no error, got %d %v", n, s.Err())
+	}
+}
+
+var partialTests = []struct {
+	in       string
+	expected Message
+}{
+	{"@a=b;c=d=e :n!u@h PRIVMSG #c :hi\r\n", Message{Tags: map[string]string{"a": "b"}, Prefix: "n!u@h", Command: "PRIVMSG", Params: []string{"#c", "hi"}}},
+	{"@a=b :n!u@h PRIVMSG #c :hi", Message{Tags: map[string]string{"a": "b"}, Prefix: "n!u@h", Command: "PRIVMSG"}},
+	{":n \r\n", Message{Prefix: "n"}},
+	{"@a=b\r\n", Message{}},
+}
+
+func TestParseErrorPartial(t *testing.T) {
+	for i, tt := range partialTests {
+		s := NewScanner(strings.NewReader(tt.in))
+		for s.Scan() {
+		}
+		var pe *ParseError
+		if !errors.As(s.Err(), &pe) {
+			t.Fatalf("%d. expecting a *ParseError, got %v", i, s.Err())
+		}
+		if !reflect.DeepEqual(pe.Partial, tt.expected) {
+			t.Errorf("%d. expecting partial %#v, got %#v", i, tt.expected, pe.Partial)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ircmessage
+
+import "strings"
+
+// TrailingColon reports whether the last parameter of m was received as a
+// trailing parameter, introduced by a colon, which is only required when it
+// is empty, begins with a colon or contains a space. It always reports false
+// for a message with no Raw field.
+func (m Message) TrailingColon() bool {
+	rest := strings.TrimRight(m.Raw, "\r\n")
+	// Skip the tags, prefix and command, none of which contain spaces.
+	if strings.HasPrefix(rest, "@") {
+		rest = skipWord(rest)
+	}
+	if strings.HasPrefix(rest, ":") {
+		rest = skipWord(rest)
+	}
+	rest = skipWord(rest)
+	return strings.HasPrefix(rest, ":") || strings.Contains(rest, " :")
+}
+
+// skipWord returns s without its first space separated word and the spaces
+// following it.
+func skipWord(s string) string {
+	i := strings.IndexByte(s, ' ')
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimLeft(s[i:], " ")
+}
+
+// SetFidelity sets whether the Encoder preserves details of how a scanned
+// message was framed, so that re-encoding it reproduces the original. When
+// set, a message whose Raw field still describes it exactly, as when it is
+// passed on unchanged from a Scanner, is written as Raw, keeping the order
+// and escaping of its tags and any extra spaces. Otherwise, a last
+// parameter received with a colon is written with one. Checking Raw costs
+// parsing it again.
+func (e *Encoder) SetFidelity(on bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fidelity = on
+	if on && e.raw == nil {
+		e.raw = new(rawParser)
+		e.raw.s = NewScanner(&e.raw.r)
+		e.raw.s.ReuseStorage()
+	}
+}
+
+// rawParser parses the Raw field of messages being encoded.
+type rawParser struct {
+	r strings.Reader
+	s *Scanner
+}
+
+// describes reports whether m.Raw is a single line, within limits, which
+// parses to exactly m. A Raw holding bytes that could not be encoded, such as
+// NUL or a bare CR, never does, so that it is checked as any other message.
+func (p *rawParser) describes(m Message, limits Limits) bool {
+	if m.Raw == "" || strings.ContainsAny(strings.TrimRight(m.Raw, "\r\n"), "\r\n\x00") {
+		return false
+	}
+	for _, spaces := range []SpacePolicy{SpacesCollapse, SpacesPreserve} {
+		if spaces == SpacesPreserve && !strings.Contains(m.Raw, "  ") {
+			break
+		}
+		p.r.Reset(m.Raw)
+		p.s.src.Reset(&p.r)
+		p.s.err = nil
+		p.s.limits = limits
+		p.s.profile = Profile{Spaces: spaces, BadTags: TagsKeepRaw, NUL: NULReject}
+		if !p.s.Scan() || len(p.s.rawBuf) != len(m.Raw) {
+			continue
+		}
+		if o := p.s.Message(); o.Command == m.Command && o.Equal(m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package ircmessage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var fidelityTests = []struct {
+	in            string
+	params        []string
+	trailingColon bool
+}{
+	{"PRIVMSG #chan :", []string{"#chan", ""}, true},
+	{"PRIVMSG #chan :hello", []string{"#chan", "hello"}, true},
+	{"PRIVMSG #chan hello", []string{"#chan", "hello"}, false},
+	{"@a=b :n!u@h 353 nick = #chan :", []string{"nick", "=", "#chan", ""}, true},
+	{":n!u@h JOIN #chan", []string{"#chan"}, false},
+	{"@a=b :n!u@h JOIN :#chan", []string{"#chan"}, true},
+	{"PING", nil, false},
+	{"MODE #c +b a:b", []string{"#c", "+b", "a:b"}, false},
+}
+
+func TestTrailingFidelity(t *testing.T) {
+	for i, tt := range fidelityTests {
+		s := NewScanner(strings.NewReader(tt.in + "\r\n"))
+		if !s.Scan() {
+			t.Fatalf("%d. %v", i, s.Err())
+		}
+		m := s.Message()
+		if strings.Join(m.Params, ",") != strings.Join(tt.params, ",") || len(m.Params) != len(tt.params) {
+			t.Errorf("%d. expecting params %q, got %q", i, tt.params, m.Params)
+		}
+		if m.TrailingColon() != tt.trailingColon {
+			t.Errorf("%d. expecting TrailingColon %t", i, tt.trailingColon)
+		}
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		e.SetFidelity(true)
+		if err := e.Encode(m); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != m.Raw {
+			t.Errorf("%d. expecting %q, got %q", i, m.Raw, buf.String())
+		}
+	}
+}
+
+var rawFidelityTests = []struct {
+	in       string
+	preserve bool
+	modify   func(*Message)
+	expected string
+}{
+	{"@b=2;a=x\\sy\\q :n!u@h privmsg  #c   :hi there", false, nil, ""},
+	{"@b=2;a=1 PRIVMSG #c  x  y", true, nil, ""},
+	{"@b=2;a=1 PRIVMSG #c  x  y", false, nil, ""},
+	{"@b=2;a=1;c=d=e PING", false, nil, ""},
+	{"@b=2;a=1 privmsg #c :hi", false, func(m *Message) { m.Command = "PRIVMSG" }, "@a=1;b=2 PRIVMSG #c :hi\r\n"},
+	{"@b=2;a=1 PRIVMSG #c  :hi", false, func(m *Message) { m.Tags["a"] = "3" }, "@a=3;b=2 PRIVMSG #c :hi\r\n"},
+	{"PRIVMSG #c  :hi", false, func(m *Message) { m.Raw = "PRIVMSG #c :hi\r\nQUIT\r\n" }, "PRIVMSG #c :hi\r\n"},
+}
+
+func TestRawFidelity(t *testing.T) {
+	for i, tt := range rawFidelityTests {
+		s := NewScanner(strings.NewReader(tt.in + "\r\n"))
+		p := Profile{BadTags: TagsKeepRaw}
+		if tt.preserve {
+			p.Spaces = SpacesPreserve
+		}
+		s.SetProfile(p)
+		if !s.Scan() {
+			t.Fatalf("%d. %v", i, s.Err())
+		}
+		m := s.Message()
+		if tt.modify != nil {
+			tt.modify(&m)
+		}
+		expected := tt.expected
+		if expected == "" {
+			expected = tt.in + "\r\n"
+		}
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		e.SetFidelity(true)
+		if err := e.Encode(m); err != nil {
+			t.Fatalf("%d. %v", i, err)
+		}
+		if buf.String() != expected {
+			t.Errorf("%d. expecting %q, got %q", i, expected, buf.String())
+		}
+	}
+}
+
+func TestRawFidelityForbiddenBytes(t *testing.T) {
+	for i, line := range []string{":n!u@h PRIVMSG #chan :hi\x00there", ":n!u@h PRIVMSG #chan :hi\rthere"} {
+		s := NewScanner(strings.NewReader(line + "\r\n"))
+		if !s.Scan() {
+			t.Fatalf("%d. %v", i, s.Err())
+		}
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		e.SetFidelity(true)
+		if err := e.Encode(s.Message()); err != ErrMessageMalformed || buf.Len() != 0 {
+			t.Errorf("%d. expecting %v and nothing written, got %v %q", i, ErrMessageMalformed, err, buf.String())
+		}
+	}
+}
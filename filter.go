@@ -0,0 +1,25 @@
+package ircmessage
+
+// FilteredScanner wraps a Scanner, transparently skipping messages that do
+// not satisfy a predicate. All other methods are those of the Scanner.
+type FilteredScanner struct {
+	*Scanner
+	keep func(Message) bool
+}
+
+// NewFilteredScanner returns a FilteredScanner that reads from s and only
+// stops at messages for which keep returns true.
+func NewFilteredScanner(s *Scanner, keep func(Message) bool) *FilteredScanner {
+	return &FilteredScanner{Scanner: s, keep: keep}
+}
+
+// Scan advances to the next message satisfying the predicate, with the same
+// semantics as Scanner.Scan.
+func (f *FilteredScanner) Scan() bool {
+	for f.Scanner.Scan() {
+		if f.keep(f.Scanner.Message()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package ircmessage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFilteredScanner(t *testing.T) {
+	in := "PING :x\r\n:n PRIVMSG #c :hi\r\n:n JOIN #c\r\n:n NOTICE #c :there\r\n@a=b=c FOO\r\n"
+	s := NewFilteredScanner(NewScanner(strings.NewReader(in)), func(m Message) bool {
+		return m.Command == "PRIVMSG" || m.Command == "NOTICE"
+	})
+	var got []string
+	for s.Scan() {
+		got = append(got, s.Message().Params[1])
+	}
+	if expected := "hi,there"; strings.Join(got, ",") != expected {
+		t.Errorf("expecting %s, got %s", expected, strings.Join(got, ","))
+	}
+	if !errors.Is(s.Err(), ErrMessageMalformed) {
+		t.Errorf("expecting error %v, got %v", ErrMessageMalformed, s.Err())
+	}
+}
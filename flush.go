@@ -0,0 +1,104 @@
+package ircmessage
+
+import (
+	"bufio"
+	"time"
+)
+
+// FlushPolicy determines when an Encoder or Conn writes the messages it
+// has encoded to the underlying connection.
+type FlushPolicy int
+
+const (
+	// FlushEach writes every message as soon as it is encoded. This is
+	// the default, and suits interactive clients.
+	FlushEach FlushPolicy = iota
+	// FlushInterval buffers messages and writes them no later than a
+	// given interval after the first was buffered, coalescing writes.
+	FlushInterval
+	// FlushManual buffers messages until Flush is called or the buffer
+	// fills.
+	FlushManual
+)
+
+// flushBufferSize is the size of the buffer used by buffered policies.
+const flushBufferSize = 4096
+
+// SetFlushPolicy sets when encoded messages are written. The interval is
+// only used by FlushInterval. It must be called before the first call to
+// Encode.
+func (e *Encoder) SetFlushPolicy(p FlushPolicy, interval time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.policy, e.interval = p, interval
+	if p != FlushEach && e.bw == nil {
+		e.bw = bufio.NewWriterSize(e.w, flushBufferSize)
+	}
+}
+
+// Flush writes any buffered messages to the underlying writer.
+func (e *Encoder) Flush() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.flush()
+}
+
+func (e *Encoder) flush() error {
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	if e.bw == nil {
+		return nil
+	}
+	return e.bw.Flush()
+}
+
+// write writes an encoded message according to the flush policy.
+func (e *Encoder) write(b []byte) error {
+	if e.policy == FlushEach {
+		_, err := e.w.Write(b)
+		return err
+	}
+	if _, err := e.bw.Write(b); err != nil {
+		return err
+	}
+	if e.policy == FlushInterval && e.timer == nil && e.bw.Buffered() > 0 {
+		e.timer = time.AfterFunc(e.interval, func() { e.Flush() })
+	}
+	return nil
+}
+
+// SetFlushPolicy sets when messages passed to WriteMessage are written to
+// the connection. Buffered messages are written subject to WriteTimeout.
+// Messages sent by Keepalive are always written immediately, along with any
+// buffered before them. SetFlushPolicy must be called before the first call
+// to WriteMessage. Call Flush before Close to send buffered messages.
+func (c *Conn) SetFlushPolicy(p FlushPolicy, interval time.Duration) {
+	c.flushPolicy, c.flushInterval = p, interval
+	if p != FlushEach {
+		// The Conn runs its own timer so that the write deadline
+		// is set before flushing.
+		c.enc.SetFlushPolicy(FlushManual, 0)
+	}
+}
+
+// Flush writes any buffered messages to the connection.
+func (c *Conn) Flush() error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return c.flush()
+}
+
+func (c *Conn) flush() error {
+	if c.flushTimer != nil {
+		c.flushTimer.Stop()
+		c.flushTimer = nil
+	}
+	if c.WriteTimeout > 0 {
+		if err := c.conn.SetWriteDeadline(time.Now().Add(c.WriteTimeout)); err != nil {
+			return err
+		}
+	}
+	return c.enc.Flush()
+}
@@ -0,0 +1,81 @@
+package ircmessage
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestEncoderFlushManual(t *testing.T) {
+	var buf lockedBuffer
+	e := NewEncoder(&buf)
+	e.SetFlushPolicy(FlushManual, 0)
+	e.Encode(Message{Command: "PING", Params: []string{"a"}})
+	e.Encode(Message{Command: "PING", Params: []string{"b"}})
+	if buf.String() != "" {
+		t.Errorf("expecting nothing written before Flush, got %q", buf.String())
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "PING a\r\nPING b\r\n"; buf.String() != expected {
+		t.Errorf("expecting %q, got %q", expected, buf.String())
+	}
+}
+
+func TestEncoderFlushInterval(t *testing.T) {
+	var buf lockedBuffer
+	e := NewEncoder(&buf)
+	e.SetFlushPolicy(FlushInterval, 10*time.Millisecond)
+	e.Encode(Message{Command: "PING", Params: []string{"a"}})
+	e.Encode(Message{Command: "PING", Params: []string{"b"}})
+	if buf.String() != "" {
+		t.Errorf("expecting nothing written immediately, got %q", buf.String())
+	}
+	deadline := time.Now().Add(time.Second)
+	for buf.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if expected := "PING a\r\nPING b\r\n"; buf.String() != expected {
+		t.Errorf("expecting %q, got %q", expected, buf.String())
+	}
+}
+
+func TestConnFlushInterval(t *testing.T) {
+	client, server := net.Pipe()
+	c, s := NewConn(client), NewConn(server)
+	defer c.Close()
+	defer s.Close()
+	c.WriteTimeout = time.Second
+	s.ReadTimeout = time.Second
+	c.SetFlushPolicy(FlushInterval, 10*time.Millisecond)
+	if err := c.WriteMessage(Message{Command: "PING", Params: []string{"a"}}); err != nil {
+		t.Fatal(err)
+	}
+	m, err := s.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Command != "PING" || m.Params[0] != "a" {
+		t.Errorf("unexpected message %v", m)
+	}
+}
@@ -0,0 +1,69 @@
+package ircmessage
+
+// CommandEqual reports whether a and b are the same command. Commands are
+// compared under ASCII case folding, so "privmsg" equals "PRIVMSG".
+func CommandEqual(a, b string) bool {
+	return equalFoldASCII(a, b)
+}
+
+// HasCommand reports whether m has the given command, ignoring ASCII case.
+func HasCommand(m Message, command string) bool {
+	return equalFoldASCII(m.Command, command)
+}
+
+// CapEqual reports whether a and b name the same capability, ignoring ASCII
+// case and any value, so "SASL=PLAIN" equals "sasl".
+func CapEqual(a, b string) bool {
+	return equalFoldASCII(capName(a), capName(b))
+}
+
+// upperCommand returns command in upper case, for switching on or looking
+// up commands in a way consistent with CommandEqual. Unlike strings.ToUpper
+// it leaves non-ASCII bytes alone, and does not allocate if command is
+// already in upper case.
+func upperCommand(command string) string {
+	for i := 0; i < len(command); i++ {
+		if c := command[i]; c >= 'a' && c <= 'z' {
+			b := []byte(command)
+			for j := i; j < len(b); j++ {
+				if b[j] >= 'a' && b[j] <= 'z' {
+					b[j] -= 'a' - 'A'
+				}
+			}
+			return string(b)
+		}
+	}
+	return command
+}
+
+// capName returns the name of the capability c, without its value.
+func capName(c string) string {
+	for i := 0; i < len(c); i++ {
+		if c[i] == '=' {
+			return c[:i]
+		}
+	}
+	return c
+}
+
+// equalFoldASCII is strings.EqualFold restricted to ASCII, which unlike
+// Unicode folding cannot equate a name with, for example, one containing
+// the Kelvin sign.
+func equalFoldASCII(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		if lowerASCII(a[i]) != lowerASCII(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func lowerASCII(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
@@ -0,0 +1,36 @@
+package ircmessage
+
+import "testing"
+
+var commandFoldTests = []struct {
+	a, b    string
+	command bool
+	cap     bool
+}{
+	{"PRIVMSG", "privmsg", true, true},
+	{"PrivMsg", "PRIVMSG", true, true},
+	{"PRIVMSG", "NOTICE", false, false},
+	{"PRIVMSG", "PRIVMS", false, false},
+	{"sasl=PLAIN", "SASL", false, true},
+	{"sasl=PLAIN", "sasl=EXTERNAL", false, true},
+	{"multi-prefix", "multi_prefix", false, false},
+	{"K", "k", false, false}, // Kelvin sign.
+	{"", "", true, true},
+}
+
+func TestCommandEqual(t *testing.T) {
+	for i, tt := range commandFoldTests {
+		if eq := CommandEqual(tt.a, tt.b); eq != tt.command {
+			t.Errorf("%d. expecting CommandEqual %t, got %t", i, tt.command, eq)
+		}
+		if eq := HasCommand(Message{Command: tt.a}, tt.b); eq != tt.command {
+			t.Errorf("%d. expecting HasCommand %t, got %t", i, tt.command, eq)
+		}
+		if eq := upperCommand(tt.a) == upperCommand(tt.b); eq != tt.command {
+			t.Errorf("%d. expecting upperCommand equality %t, got %t", i, tt.command, eq)
+		}
+		if eq := CapEqual(tt.a, tt.b); eq != tt.cap {
+			t.Errorf("%d. expecting CapEqual %t, got %t", i, tt.cap, eq)
+		}
+	}
+}
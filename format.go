@@ -0,0 +1,172 @@
+package ircmessage
+
+import "strings"
+
+// mIRC formatting control codes.
+const (
+	fmtBold          = '\x02'
+	fmtColor         = '\x03'
+	fmtHexColor      = '\x04'
+	fmtReset         = '\x0f'
+	fmtMonospace     = '\x11'
+	fmtReverse       = '\x16'
+	fmtItalic        = '\x1d'
+	fmtStrikethrough = '\x1e'
+	fmtUnderline     = '\x1f'
+)
+
+func isFormatCode(b byte) bool {
+	switch b {
+	case fmtBold, fmtColor, fmtHexColor, fmtReset, fmtMonospace,
+		fmtReverse, fmtItalic, fmtStrikethrough, fmtUnderline:
+		return true
+	}
+	return false
+}
+
+// formatCodeLen returns the length in bytes of the formatting code at the
+// start of s, including any color arguments, or 0 if s does not start with
+// a formatting code.
+func formatCodeLen(s string) int {
+	if len(s) == 0 || !isFormatCode(s[0]) {
+		return 0
+	}
+	switch s[0] {
+	case fmtColor:
+		n := 1 + countDigits(s[1:], 2)
+		if n > 1 && n+1 < len(s) && s[n] == ',' {
+			if d := countDigits(s[n+1:], 2); d > 0 {
+				n += 1 + d
+			}
+		}
+		return n
+	case fmtHexColor:
+		n := 1
+		if countHex(s[1:], 6) == 6 {
+			n += 6
+			if n+1 < len(s) && s[n] == ',' && countHex(s[n+1:], 6) == 6 {
+				n += 7
+			}
+		}
+		return n
+	}
+	return 1
+}
+
+func countDigits(s string, max int) int {
+	n := 0
+	for n < len(s) && n < max && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+func countHex(s string, max int) int {
+	n := 0
+	for n < len(s) && n < max && isHex(s[n]) {
+		n++
+	}
+	return n
+}
+
+func isHex(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'f' || b >= 'A' && b <= 'F'
+}
+
+// formatState tracks the formatting in effect at a point in a line of text.
+type formatState struct {
+	bold, italic, underline, strikethrough, monospace, reverse bool
+	fg, bg                                                     string // Two digit mIRC colors.
+	hexFg, hexBg                                               string // Six digit hex colors.
+}
+
+// apply updates the state with a single formatting code as delimited by
+// formatCodeLen.
+func (f *formatState) apply(code string) {
+	switch code[0] {
+	case fmtBold:
+		f.bold = !f.bold
+	case fmtItalic:
+		f.italic = !f.italic
+	case fmtUnderline:
+		f.underline = !f.underline
+	case fmtStrikethrough:
+		f.strikethrough = !f.strikethrough
+	case fmtMonospace:
+		f.monospace = !f.monospace
+	case fmtReverse:
+		f.reverse = !f.reverse
+	case fmtReset:
+		*f = formatState{}
+	case fmtColor:
+		if len(code) == 1 {
+			f.fg, f.bg = "", ""
+			return
+		}
+		fg, bg, _ := strings.Cut(code[1:], ",")
+		f.fg = padColor(fg)
+		if bg != "" {
+			f.bg = padColor(bg)
+		}
+	case fmtHexColor:
+		if len(code) == 1 {
+			f.hexFg, f.hexBg = "", ""
+			return
+		}
+		fg, bg, _ := strings.Cut(code[1:], ",")
+		f.hexFg = fg
+		if bg != "" {
+			f.hexBg = bg
+		}
+	}
+}
+
+// padColor pads single digit colors so that a restored color code can
+// never absorb a digit from the text that follows it.
+func padColor(c string) string {
+	if len(c) == 1 {
+		return "0" + c
+	}
+	return c
+}
+
+// codes returns the formatting codes required to enter this state from
+// unformatted text.
+func (f formatState) codes() string {
+	var b strings.Builder
+	if f.bold {
+		b.WriteByte(fmtBold)
+	}
+	if f.italic {
+		b.WriteByte(fmtItalic)
+	}
+	if f.underline {
+		b.WriteByte(fmtUnderline)
+	}
+	if f.strikethrough {
+		b.WriteByte(fmtStrikethrough)
+	}
+	if f.monospace {
+		b.WriteByte(fmtMonospace)
+	}
+	if f.reverse {
+		b.WriteByte(fmtReverse)
+	}
+	if f.fg != "" {
+		b.WriteByte(fmtColor)
+		b.WriteString(f.fg)
+		if f.bg != "" {
+			b.WriteByte(',')
+			b.WriteString(f.bg)
+		}
+	}
+	if f.hexFg != "" {
+		b.WriteByte(fmtHexColor)
+		b.WriteString(f.hexFg)
+		if f.hexBg != "" {
+			b.WriteByte(',')
+			b.WriteString(f.hexBg)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package ircmessage
+
+import "strings"
+
+// HelpRequest returns the HELP message asking for help on subject, or the
+// server's index of help if it is empty.
+func HelpRequest(subject string) Message { return serverQuery("HELP", subject) }
+
+// Help is the reply to a HELP request.
+type Help struct {
+	Subject string
+	Lines   []string
+	// NotFound is true if the server has no help on the subject, in
+	// which case Lines holds its explanation.
+	NotFound bool
+}
+
+// HelpCollector gathers the RPL_HELPSTART (704), RPL_HELPTXT (705) and
+// RPL_ENDOFHELP (706) replies to HELP, returning the text once the last
+// arrives, or that there is none on ERR_HELPNOTFOUND (524). Replies are
+// matched to queries by subject, ignoring case. The zero value is ready to
+// use, and is not safe for concurrent use.
+type HelpCollector struct {
+	pending map[string]*Help // By lowered subject.
+}
+
+// Add feeds a message to the collector. It reports whether the message was
+// a HELP reply, and returns the help once the replies to a query have
+// ended.
+func (c *HelpCollector) Add(m Message) (*Help, bool) {
+	if len(m.Params) < 3 {
+		return nil, false
+	}
+	subject, text := m.Params[1], m.Params[len(m.Params)-1]
+	key := strings.ToLower(subject)
+	switch m.Command {
+	case "704": // RPL_HELPSTART
+		if c.pending == nil {
+			c.pending = make(map[string]*Help)
+		}
+		c.pending[key] = &Help{Subject: subject, Lines: []string{text}}
+	case "705": // RPL_HELPTXT
+		if h, ok := c.pending[key]; ok {
+			h.Lines = append(h.Lines, text)
+		}
+	case "706": // RPL_ENDOFHELP
+		h, ok := c.pending[key]
+		if !ok {
+			h = &Help{Subject: subject}
+		}
+		delete(c.pending, key)
+		h.Lines = append(h.Lines, text)
+		return h, true
+	case "524": // ERR_HELPNOTFOUND
+		delete(c.pending, key)
+		return &Help{Subject: subject, Lines: []string{text}, NotFound: true}, true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
@@ -0,0 +1,39 @@
+package ircmessage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHelpCollector(t *testing.T) {
+	in := ":irc.example.org 704 me privmsg :** Help System **\r\n" +
+		":irc.example.org 705 me PRIVMSG :PRIVMSG <target> <text>\r\n" +
+		":irc.example.org 705 me privmsg :\r\n" +
+		":irc.example.org 706 me privmsg :End of /HELP.\r\n" +
+		":irc.example.org 524 me foo :No help available on this topic\r\n"
+	var (
+		c       HelpCollector
+		results []*Help
+	)
+	s := NewScanner(strings.NewReader(in))
+	for s.Scan() {
+		h, ok := c.Add(s.Message())
+		if !ok {
+			t.Errorf("expecting %v to be consumed", s.Message())
+		}
+		if h != nil {
+			results = append(results, h)
+		}
+	}
+	expected := []*Help{
+		{Subject: "privmsg", Lines: []string{"** Help System **", "PRIVMSG <target> <text>", "", "End of /HELP."}},
+		{Subject: "foo", Lines: []string{"No help available on this topic"}, NotFound: true},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expecting %+v, got %+v", expected, results)
+	}
+	if m := HelpRequest("privmsg"); m.Command != "HELP" || !reflect.DeepEqual(m.Params, []string{"privmsg"}) {
+		t.Errorf("unexpected message %v", m)
+	}
+}
@@ -0,0 +1,28 @@
+package ircmessage
+
+// Hook receives notifications of scanner and encoder activity, allowing
+// metrics to be collected without this package depending on any metrics
+// library. Size is the number of bytes of the line on the wire.
+//
+// Methods are called synchronously from Scan and Encode, so they should
+// return quickly.
+type Hook interface {
+	// Scanned is called after a message is scanned successfully.
+	Scanned(command string, size int)
+	// ScanError is called when a scan stops with an error other than io.EOF.
+	ScanError(err error, size int)
+	// Encoded is called after each attempt to write a message. After a
+	// failure to encode, size is 0.
+	Encoded(command string, size int, err error)
+}
+
+// SetHook sets h to be notified of every scan. It must be called before the
+// first call to Scan.
+func (s *Scanner) SetHook(h Hook) {
+	s.hook = h
+}
+
+// SetHook sets h to be notified of every message encoded.
+func (e *Encoder) SetHook(h Hook) {
+	e.hook = h
+}
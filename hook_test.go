@@ -0,0 +1,57 @@
+package ircmessage
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type recordingHook struct {
+	events []string
+}
+
+func (h *recordingHook) Scanned(command string, size int) {
+	h.events = append(h.events, fmt.Sprintf("scanned %s %d", command, size))
+}
+
+func (h *recordingHook) ScanError(err error, size int) {
+	h.events = append(h.events, fmt.Sprintf("error %v %d", err, size))
+}
+
+func (h *recordingHook) Encoded(command string, size int, err error) {
+	h.events = append(h.events, fmt.Sprintf("encoded %s %d %v", command, size, err))
+}
+
+func TestScannerHook(t *testing.T) {
+	var h recordingHook
+	s := NewScanner(strings.NewReader("PING :a\r\n@a=b=c FOO\r\n"))
+	s.SetHook(&h)
+	for s.Scan() {
+	}
+	expected := []string{"scanned PING 9", "error malformed tag at byte 12 12"}
+	if !reflect.DeepEqual(h.events, expected) {
+		t.Errorf("expecting %q, got %q", expected, h.events)
+	}
+	h.events = nil
+	s = NewScanner(strings.NewReader("PING :a\r\n"))
+	s.SetHook(&h)
+	for s.Scan() {
+	}
+	if expected := []string{"scanned PING 9"}; !reflect.DeepEqual(h.events, expected) {
+		t.Errorf("expecting %q, got %q", expected, h.events)
+	}
+}
+
+func TestEncoderHook(t *testing.T) {
+	var h recordingHook
+	e := NewEncoder(io.Discard)
+	e.SetHook(&h)
+	e.Encode(Message{Command: "PING", Params: []string{"a"}})
+	e.Encode(Message{})
+	expected := []string{"encoded PING 8 <nil>", "encoded  0 message malformed"}
+	if !reflect.DeepEqual(h.events, expected) {
+		t.Errorf("expecting %q, got %q", expected, h.events)
+	}
+}
@@ -0,0 +1,38 @@
+package ircmessage
+
+// interned holds the commands and tag keys seen on nearly every connection.
+// Message components are otherwise substrings of the raw line, so a command
+// or tag key kept beyond the life of its message, such as a map key for
+// per-command counters, would keep the whole line alive. Interned strings
+// are shared by every message instead.
+var interned = make(map[string]string)
+
+func init() {
+	for _, s := range []string{
+		// Commands.
+		"ACCOUNT", "AUTHENTICATE", "AWAY", "BATCH", "CAP", "CHGHOST", "ERROR",
+		"INVITE", "JOIN", "KICK", "MODE", "NICK", "NOTICE", "PART", "PING",
+		"PONG", "PRIVMSG", "QUIT", "SETNAME", "TAGMSG", "TOPIC", "WALLOPS",
+		// Numerics.
+		"001", "002", "003", "004", "005", "221", "251", "252", "253", "254",
+		"255", "265", "266", "301", "305", "306", "311", "312", "313", "315",
+		"317", "318", "319", "324", "329", "330", "331", "332", "333", "341",
+		"352", "353", "354", "366", "367", "368", "372", "375", "376", "401",
+		"403", "404", "421", "433", "442", "451", "461", "462", "473", "474",
+		"475", "482", "900", "903", "904",
+		// Tag keys.
+		"account", "batch", "label", "msgid", "time", "bot", "draft/multiline-concat",
+		"+draft/reply", "+draft/react", "+typing",
+	} {
+		interned[s] = s
+	}
+}
+
+// intern returns the shared copy of s if it is one of the interned strings,
+// and s otherwise.
+func intern(s string) string {
+	if i, ok := interned[s]; ok {
+		return i
+	}
+	return s
+}
@@ -0,0 +1,24 @@
+package ircmessage
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestIntern(t *testing.T) {
+	s := NewScanner(strings.NewReader("@time=2017-09-26T00:00:00.000Z;x-custom=1 PRIVMSG #chan :hi\r\n"))
+	if !s.Scan() {
+		t.Fatal(s.Err())
+	}
+	m := s.Message()
+	if unsafe.StringData(m.Command) != unsafe.StringData(interned["PRIVMSG"]) {
+		t.Error("expecting PRIVMSG to be interned")
+	}
+	for k := range m.Tags {
+		shared := unsafe.StringData(k) == unsafe.StringData(interned[k])
+		if want := k == "time"; shared != want {
+			t.Errorf("expecting interning of tag key %q to be %v", k, want)
+		}
+	}
+}
@@ -4,11 +4,12 @@ package ircmessage
 
 import (
 	"bufio"
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
+	"unsafe"
 )
 
 const (
@@ -24,58 +25,112 @@ const (
 	tokenSpace     = " "
 )
 
-// ErrMessageMalformed is returned when the scanner encounters a malformed message.
-// The only other  error ircmessage specifically returns is io.ErrUnexpectedEOF.
-// Any other error you encounter comes from the source reader.
+// ErrMessageMalformed is returned when the encoder is asked to write a malformed
+// message. The scanner returns a *ParseError for a malformed message, which matches
+// ErrMessageMalformed with errors.Is. Apart from the errors declared by ircmessage,
+// any error you encounter comes from the underlying reader or writer.
 var ErrMessageMalformed = errors.New("message malformed")
 
 // Scanner provides a convenient interface for parsing RFC1459-compliant IRC messages,
 // with support for IRCv3 message tags.
 //
-// Scanning stops unrecoverably at EOF, the first I/O error, or a malformed message.
-// When a scan stops, the reader may have advanced arbitrarily far past the last message.
+// Scanning stops at EOF, the first I/O error, or a malformed message. Only the
+// last can be recovered from, by calling SkipLine. When a scan stops, the reader
+// may have advanced arbitrarily far past the last message.
 type Scanner struct {
 	src            *bufio.Reader
-	buf            *bytes.Buffer // Temporary buffer that is re-used where possible.
-	rawBuf         []rune        // Keeps track of the current raw IRC message.
-	message        Message       // Last message parsed.
-	err            error         // Last error encountered.
+	rawBuf         []byte  // Keeps track of the current raw IRC message.
+	message        Message // Last message parsed.
+	err            error   // Last error encountered.
 	currentMsgSize int
-	lastRuneSize   int // There is never a need to unread further than one rune, so this is enough.
+	sizeLimit      int // Limit on currentMsgSize for the current section.
+	limits         Limits
+	profile        Profile
+	quota          int
+	lastRuneSize   int  // There is never a need to unread further than one rune, so this is enough.
+	lastReadByte   bool // Whether the last rune was read as a single byte.
+	tee            io.Writer
+	stats          scannerStats
+	hook           Hook
+	tracer         func(TraceEvent)
+	pipeline       Pipeline
+	parsed         parsed // Components of the current line read so far.
+	normalize      bool
+	isupport       *ISupport // Casemapping used when normalize is set.
+
+	// Storage shared by every message when reuse is set.
+	reuse  bool
+	arena  []byte
+	params []string
+	tags   map[string]string
 }
 
 // NewScanner returns a new Scanner to read from r.
 func NewScanner(r io.Reader) *Scanner {
 	return &Scanner{
 		src:    bufio.NewReader(r),
-		buf:    bytes.NewBuffer(make([]byte, 0, 1024)),
-		rawBuf: make([]rune, 0, 1024),
+		rawBuf: make([]byte, 0, 1024),
+		limits: DefaultLimits,
 	}
 }
 
 func (s *Scanner) read() (rune, error) {
-	rn, n, err := s.src.ReadRune()
+	// IRC traffic is mostly ASCII, so read a byte at a time and only
+	// decode a rune when a multi-byte sequence begins.
+	b, err := s.src.ReadByte()
 	if err != nil {
 		return 0, err
 	}
+	rn, n := rune(b), 1
+	s.lastReadByte = true
+	if b >= utf8.RuneSelf {
+		s.src.UnreadByte()
+		rn, n, _ = s.src.ReadRune()
+		s.lastReadByte = rn == utf8.RuneError && n == 1
+		if s.lastReadByte {
+			// Keep the byte as it was received rather than
+			// the replacement character.
+			s.src.UnreadRune()
+			s.src.ReadByte()
+		}
+	}
 	s.lastRuneSize = n
 	s.currentMsgSize += n
-	s.rawBuf = append(s.rawBuf, rn)
-	if s.currentMsgSize > maxMessageSize {
-		return 0, ErrMessageMalformed
+	if s.lastReadByte {
+		s.rawBuf = append(s.rawBuf, b)
+	} else {
+		s.rawBuf = utf8.AppendRune(s.rawBuf, rn)
+	}
+	if s.currentMsgSize > s.sizeLimit {
+		return 0, ErrLineTooLong
 	}
-	return rn, err
+	if s.overQuota() {
+		return 0, ErrQuotaExceeded
+	}
+	return rn, nil
 }
 
 func (s *Scanner) unread() error {
-	if err := s.src.UnreadRune(); err != nil {
+	unread := s.src.UnreadRune
+	if s.lastReadByte {
+		unread = s.src.UnreadByte
+	}
+	if err := unread(); err != nil {
 		return err
 	}
 	s.currentMsgSize -= s.lastRuneSize
-	s.rawBuf = s.rawBuf[:len(s.rawBuf)-1]
+	s.rawBuf = s.rawBuf[:len(s.rawBuf)-s.lastRuneSize]
 	return nil
 }
 
+// Tee sets w to receive a copy of the exact bytes of every line the Scanner
+// reads, including lines that turn out to be malformed, before the message
+// is returned by Scan. An error writing to w stops the scan. Tee must be
+// called before the first call to Scan.
+func (s *Scanner) Tee(w io.Writer) {
+	s.tee = w
+}
+
 // Message represents a parsed IRC message.
 type Message struct {
 	Raw     string
@@ -83,6 +138,10 @@ type Message struct {
 	Prefix  string
 	Command string
 	Params  []string
+	// Truncated is set by the Scanner on a message whose body is exactly
+	// the size limit, as happens when a server cuts a long message to
+	// fit. The message may have been longer when sent.
+	Truncated bool
 }
 
 func (m Message) String() string {
@@ -95,6 +154,68 @@ func (m Message) String() string {
 	)
 }
 
+// Clone returns a deep copy of m, whose Tags and Params may be modified or
+// retained without affecting m.
+func (m Message) Clone() Message {
+	if m.Tags != nil {
+		tags := make(map[string]string, len(m.Tags))
+		for k, v := range m.Tags {
+			tags[k] = v
+		}
+		m.Tags = tags
+	}
+	if m.Params != nil {
+		m.Params = append(make([]string, 0, len(m.Params)), m.Params...)
+	}
+	return m
+}
+
+// Equal reports whether m and o are semantically the same message: they
+// have the same tags, prefix and params, and commands that are equal under
+// case folding. The Raw and Truncated fields are ignored, so differences in
+// tag order, escaping or spacing on the wire do not matter.
+func (m Message) Equal(o Message) bool {
+	if len(m.Tags) != len(o.Tags) || len(m.Params) != len(o.Params) ||
+		m.Prefix != o.Prefix || !CommandEqual(m.Command, o.Command) {
+		return false
+	}
+	for k, v := range m.Tags {
+		if ov, ok := o.Tags[k]; !ok || ov != v {
+			return false
+		}
+	}
+	for i := range m.Params {
+		if m.Params[i] != o.Params[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualRaw reports whether m and o have exactly the same wire
+// representation. A message with an empty Raw field is compared using its
+// encoding, as produced by AppendMessage, without the trailing CRLF, and is
+// never equal to anything if it cannot be encoded.
+func (m Message) EqualRaw(o Message) bool {
+	mw, ok := m.wire()
+	if !ok {
+		return false
+	}
+	ow, ok := o.wire()
+	return ok && mw == ow
+}
+
+func (m Message) wire() (string, bool) {
+	if m.Raw != "" {
+		return strings.TrimSuffix(m.Raw, "\r\n"), true
+	}
+	b, err := AppendMessage(nil, m)
+	if err != nil {
+		return "", false
+	}
+	return string(b[:len(b)-2]), true
+}
+
 func (s *Scanner) skipSpace() {
 	for {
 		ch, _ := s.read()
@@ -105,122 +226,191 @@ func (s *Scanner) skipSpace() {
 	}
 }
 
-func (s *Scanner) readTags() (map[string]string, error) {
+// span holds the byte offsets of a message component within rawBuf.
+type span struct {
+	start, end int
+}
+
+func (s *Scanner) readTags() (span, error) {
 	// Read whole tag string.
-	s.buf.Reset()
+	sp := span{start: len(s.rawBuf)}
 	for {
 		ch, err := s.read()
 		if err != nil {
 			if err == io.EOF {
-				return nil, io.ErrUnexpectedEOF
+				return span{}, io.ErrUnexpectedEOF
 			}
-			return nil, err
+			return span{}, err
 		}
 		if ch == runeSpace {
 			break
 		}
-		if s.buf.Len() >= maxMessageSize {
-			return nil, ErrMessageMalformed
+		if ch == '\r' {
+			// The line ended before the command.
+			s.unread()
+			return span{}, ErrBadTag
 		}
-		s.buf.WriteRune(ch)
 	}
-	// Split tags.
-	tagMap := make(map[string]string)
-	var tags []string
-	if !strings.Contains(s.buf.String(), tokenSemicolon) {
-		tags = append(tags, s.buf.String())
-	} else {
-		splitTags := strings.Split(s.buf.String(), tokenSemicolon)
-		for _, v := range splitTags {
-			if strings.Contains(v, tokenEquals) {
-				pair := strings.Split(v, tokenEquals)
-				if len(pair) != 2 {
-					return nil, ErrMessageMalformed
+	sp.end = len(s.rawBuf) - 1
+	s.skipSpace()
+	return sp, nil
+}
+
+// parseTags splits a raw tag string into its keys and unescaped values,
+// adding them to tagMap. Malformed tags, which include those with invalid
+// escape sequences if strict is set, are handled according to policy. If
+// arena is not nil, unescaped values are stored in it rather than allocated
+// separately.
+func parseTags(tagMap map[string]string, raw string, arena *[]byte, policy TagPolicy, strict bool) error {
+	for raw != "" {
+		var tag string
+		tag, raw, _ = strings.Cut(raw, tokenSemicolon)
+		k, v, ok := strings.Cut(tag, tokenEquals)
+		if ok && strings.Contains(v, tokenEquals) || strict && !validTagEscapes(v) {
+			switch policy {
+			case TagsDrop:
+				continue
+			case TagsKeepRaw:
+				if prev, ok := tagMap[MalformedTagKey]; ok {
+					tag = prev + tokenSemicolon + tag
 				}
-				tagMap[pair[0]] = pair[1]
+				tagMap[MalformedTagKey] = tag
 				continue
 			}
-			tagMap[v] = ""
+			return ErrBadTag
 		}
+		if arena != nil && strings.Contains(v, `\`) {
+			start := len(*arena)
+			*arena = appendUnescapedTagValue(*arena, v)
+			v = unsafe.String(&(*arena)[start], len(*arena)-start)
+		} else {
+			v = unescapeTagValue(v)
+		}
+		tagMap[intern(k)] = v
 	}
-	s.skipSpace()
-	return tagMap, nil
+	return nil
 }
 
-func (s *Scanner) readPrefix() (string, error) {
-	s.buf.Reset()
+func (s *Scanner) readPrefix() (span, error) {
+	sp := span{start: len(s.rawBuf)}
 	for {
 		ch, err := s.read()
 		if err != nil {
 			if err == io.EOF {
-				return "", io.ErrUnexpectedEOF
+				return span{}, io.ErrUnexpectedEOF
 			}
-			return "", err
+			return span{}, err
 		}
 		if ch == runeSpace {
 			break
 		}
-		s.buf.WriteRune(ch)
+		if ch == '\r' {
+			s.unread()
+			return span{}, ErrBadPrefix
+		}
+	}
+	sp.end = len(s.rawBuf) - 1
+	if sp.end == sp.start {
+		return span{}, ErrBadPrefix
 	}
-	prefix := s.buf.String()
 	s.skipSpace()
-	return prefix, nil
+	return sp, nil
 }
 
-func (s *Scanner) readCommand() (string, error) {
-	s.buf.Reset()
+func (s *Scanner) readCommand() (span, error) {
+	sp := span{start: len(s.rawBuf)}
 	for {
 		ch, err := s.read()
 		if err != nil {
 			if err == io.EOF {
-				return "", io.ErrUnexpectedEOF
+				return span{}, io.ErrUnexpectedEOF
 			}
-			return "", err
+			return span{}, err
 		}
 		if ch == runeSpace {
+			sp.end = len(s.rawBuf) - 1
 			break
 		}
 		if ch == '\r' {
 			s.unread()
+			sp.end = len(s.rawBuf)
 			break
 		}
-		s.buf.WriteRune(ch)
 	}
 	s.skipSpace()
-	return s.buf.String(), nil
+	return sp, nil
 }
 
-func (s *Scanner) readParams() ([]string, error) {
-	var params []string
-	s.buf.Reset()
+func (s *Scanner) readParams() (span, error) {
+	sp := span{start: len(s.rawBuf)}
 	for {
-		if end, _ := s.isLineEnd(); end {
+		end, err := s.isLineEnd()
+		if err != nil {
+			if err == io.EOF {
+				return span{}, io.ErrUnexpectedEOF
+			}
+			return span{}, err
+		}
+		if end {
 			break
 		}
-		ch, err := s.read()
-		if err != nil {
+		if _, err := s.read(); err != nil {
 			if err == io.EOF {
-				return nil, io.ErrUnexpectedEOF
+				return span{}, io.ErrUnexpectedEOF
 			}
-			return nil, err
-		}
-		s.buf.WriteRune(ch)
-	}
-	// A colon indicates a trailing parameter, read
-	// everything from after the colon to line ending
-	// and append it to params.
-	paramString := strings.Split(s.buf.String(), tokenSpace)
-	for i, v := range paramString {
-		if strings.HasPrefix(v, tokenColon) {
-			paramString[i] = paramString[i][1:]
-			params = append(params, strings.Join(paramString[i:], tokenSpace))
+			return span{}, err
+		}
+	}
+	sp.end = len(s.rawBuf) - len("\r\n")
+	return sp, nil
+}
+
+// splitParams splits a raw parameter string. A colon indicates a trailing
+// parameter: everything from after the colon to the line ending forms the
+// final parameter. The returned slice is sized exactly.
+func splitParams(raw string) []string {
+	if n := countParams(raw); n > 0 {
+		return appendParams(make([]string, 0, n), raw)
+	}
+	return nil
+}
+
+func countParams(raw string) int {
+	n := 0
+	for i := 0; i < len(raw); {
+		if raw[i] == runeSpace {
+			i++
+			continue
+		}
+		n++
+		j := strings.IndexByte(raw[i:], runeSpace)
+		if raw[i] == runeColon || j < 0 {
+			break
+		}
+		i += j
+	}
+	return n
+}
+
+func appendParams(params []string, raw string) []string {
+	for i := 0; i < len(raw); {
+		if raw[i] == runeSpace {
+			i++
+			continue
+		}
+		if raw[i] == runeColon {
+			params = append(params, raw[i+1:])
 			break
 		}
-		if v != "" {
-			params = append(params, v)
+		j := strings.IndexByte(raw[i:], runeSpace)
+		if j < 0 {
+			params = append(params, raw[i:])
+			break
 		}
+		params = append(params, raw[i:i+j])
+		i += j
 	}
-	return params, nil
+	return params
 }
 
 func (s *Scanner) isLineEnd() (bool, error) {
@@ -244,8 +434,14 @@ func (s *Scanner) isLineEnd() (bool, error) {
 
 func (s *Scanner) next() (Message, error) {
 	s.rawBuf = s.rawBuf[:0]
+	s.arena = s.arena[:0]
 	s.currentMsgSize = 0
-	var msg Message
+	s.sizeLimit = s.limits.Body
+	s.parsed = parsed{}
+	var (
+		tags, prefix, command, params span
+		hasTags                       bool
+	)
 	ch, err := s.read()
 	if err != nil {
 		return Message{}, err
@@ -253,13 +449,18 @@ func (s *Scanner) next() (Message, error) {
 	// Check for and read message tags if present as per:
 	// http://ircv3.net/specs/core/message-tags-3.2.html
 	if ch == runeAt {
-		msg.Tags, err = s.readTags()
+		hasTags = true
+		s.sizeLimit = s.limits.Tags
+		tags, err = s.readTags()
 		if err != nil {
 			return Message{}, err
 		}
-		// Reset the size counter. Tags can be a maximum of 512 bytes
-		// and the remainder of the message is allowed a further 512.
+		s.parsed.tags = tags
+		s.trace(TraceTags, tags, "tags present, size limit reset for the body", nil)
+		// Reset the size counter. Tags and the remainder of the
+		// message have separate limits, by default 512 bytes each.
 		s.currentMsgSize = 0
+		s.sizeLimit = s.limits.Body
 		// Get next rune
 		ch, err = s.read()
 		if err != nil {
@@ -272,51 +473,166 @@ func (s *Scanner) next() (Message, error) {
 	// Read message prefix if present, prefixes are
 	// prepended with a colon.
 	if ch == runeColon {
-		msg.Prefix, err = s.readPrefix()
+		prefix, err = s.readPrefix()
 		if err != nil {
 			return Message{}, err
 		}
+		s.parsed.prefix = prefix
+		s.trace(TracePrefix, prefix, "prefix present", nil)
 	} else {
 		s.unread()
 	}
-	msg.Command, err = s.readCommand()
+	command, err = s.readCommand()
 	if err != nil {
 		return Message{}, err
 	}
+	if command.start == command.end {
+		if end, _ := s.isLineEnd(); end && !hasTags && prefix == (span{}) && len(s.rawBuf) == 2 {
+			return Message{}, errEmptyLine
+		}
+		return Message{}, ErrEmptyCommand
+	}
+	s.parsed.command = command
+	s.trace(TraceCommand, command, "", nil)
 	// Check for line ending, else start reading params.
 	end, err := s.isLineEnd()
 	if err != nil {
 		return Message{}, err
 	}
-	if end {
-		msg.Raw = string(s.rawBuf)
-		return msg, nil
+	if !end {
+		s.unread()
+		params, err = s.readParams()
+		if err != nil {
+			return Message{}, err
+		}
+		s.parsed.params = params
+		if s.tracer != nil {
+			note := ""
+			if p := " " + string(s.rawBuf[params.start:params.end]); strings.Contains(p, " :") {
+				note = "trailing parameter present"
+			}
+			s.trace(TraceParams, params, note, nil)
+		}
 	}
-	s.unread()
-	msg.Params, err = s.readParams()
-	if err != nil {
+	if err := s.applyNULPolicy(&tags, &prefix, &command, &params); err != nil {
+		return Message{}, err
+	}
+	s.parsed = parsed{tags, prefix, command, params}
+	if s.profile.Spaces == SpacesStrict && !strictSpaces(s.rawBuf, params) {
+		// Extra spaces are not worth a specific error.
+		return Message{}, ErrMessageMalformed
+	}
+	if s.reuse {
+		return s.reuseMessage(tags, prefix, command, params, hasTags)
+	}
+	// Every component is a substring of the raw message, so a
+	// message costs a single allocation plus its tags and params.
+	raw := string(s.rawBuf)
+	msg := Message{
+		Raw:     raw,
+		Prefix:  raw[prefix.start:prefix.end],
+		Command: intern(raw[command.start:command.end]),
+	}
+	if s.profile.Spaces == SpacesPreserve {
+		msg.Params = appendParamsExact(nil, raw, command, params)
+	} else {
+		msg.Params = splitParams(raw[params.start:params.end])
+	}
+	if hasTags {
+		rawTags := raw[tags.start:tags.end]
+		msg.Tags = make(map[string]string, strings.Count(rawTags, tokenSemicolon)+1)
+		if err := parseTags(msg.Tags, rawTags, nil, s.profile.BadTags, s.profile.StrictEscapes); err != nil {
+			return Message{}, err
+		}
+	}
+	return s.finish(msg)
+}
+
+// finish applies the checks made on every complete message.
+func (s *Scanner) finish(msg Message) (Message, error) {
+	msg.Truncated = s.currentMsgSize == s.limits.Body
+	if s.limits.MaxParams > 0 && len(msg.Params) > s.limits.MaxParams {
+		return Message{}, ErrTooManyParams
+	}
+	if s.limits.TagValue > 0 {
+		for _, v := range msg.Tags {
+			if len(v) > s.limits.TagValue {
+				return Message{}, ErrTagTooLong
+			}
+		}
+	}
+	if err := s.profile.check(msg); err != nil {
 		return Message{}, err
 	}
-	msg.Raw = string(s.rawBuf)
+	if s.profile.UpperCommand {
+		msg.Command = upperCommand(msg.Command)
+	}
+	s.trace(TraceLine, span{0, len(s.rawBuf)}, "", nil)
 	return msg, nil
 }
 
 // Scan advances the Scanner to the next message, which is then available
-// through the Message method. It returns false when the scan stops, either
-// by reaching the end of the input or an error. After Scan returns false,
+// through the Message method. Empty lines are skipped. It returns false when
+// the scan stops, either by reaching the end of the input or an error. After Scan returns false,
 // the Err method will return any error that occurred during scanning, the
 // exception being if it was io.EOF, in which case Err will return nil.
 func (s *Scanner) Scan() bool {
-	if s.err != nil {
-		return false
+	for s.err == nil {
+		msg, err := s.scanLine()
+		if err == errEmptyLine {
+			continue
+		}
+		if err != nil {
+			s.err = err
+			return false
+		}
+		if s.normalize {
+			msg = normalizeMessage(msg, s.isupport)
+		}
+		if s.pipeline != nil {
+			var ok bool
+			if msg, ok = s.pipeline.Apply(msg); !ok {
+				continue
+			}
+		}
+		s.message = msg
+		return true
 	}
+	return false
+}
+
+// scanLine parses the next line and notifies any observers of the result.
+func (s *Scanner) scanLine() (Message, error) {
 	msg, err := s.next()
-	if err != nil {
-		s.err = err
-		return false
+	if err == errEmptyLine {
+		s.stats.bytes.Add(int64(len(s.rawBuf)))
+		if s.tee != nil {
+			if _, err := s.tee.Write(s.rawBuf); err != nil {
+				return Message{}, err
+			}
+		}
+		return Message{}, errEmptyLine
 	}
-	s.message = msg
-	return true
+	err = s.parseError(err)
+	s.stats.record(len(s.rawBuf), msg, err)
+	if s.tee != nil && len(s.rawBuf) > 0 {
+		if _, teeErr := s.tee.Write(s.rawBuf); teeErr != nil && err == nil {
+			err = teeErr
+		}
+	}
+	if s.hook != nil {
+		switch err {
+		case nil:
+			s.hook.Scanned(msg.Command, len(s.rawBuf))
+		case io.EOF:
+		default:
+			s.hook.ScanError(err, len(s.rawBuf))
+		}
+	}
+	if err != nil && err != io.EOF {
+		s.trace(TraceError, span{0, len(s.rawBuf)}, "", err)
+	}
+	return msg, err
 }
 
 // Message returns the most recent Message generated by a call to Scan.
@@ -345,10 +661,7 @@ type Prefix struct {
 // ParsePrefix accepts a string prefix and returns a
 // parsed *Prefix or nil if the input was invalid.
 func ParsePrefix(in string) *Prefix {
-	if len(in) == 0 {
-		return nil
-	}
-	if in[0] == '!' || in[0] == '@' {
+	if !prefixParses(in) {
 		return nil
 	}
 	dpos := strings.Index(in, ".") + 1
@@ -374,3 +687,36 @@ func ParsePrefix(in string) *Prefix {
 	}
 	return p
 }
+
+// prefixParses reports whether ParsePrefix accepts in, which it does unless
+// in is empty or lacks a nickname or servername.
+func prefixParses(in string) bool {
+	return in != "" && in[0] != '!' && in[0] != '@'
+}
+
+// prefixWellFormed reports whether in has the form of a prefix to send: a
+// servername or nickname, optionally followed by a non-empty user after a
+// '!' and then a non-empty host after an '@'. ParsePrefix is more lenient.
+func prefixWellFormed(in string) bool {
+	nick, host, hasHost := strings.Cut(in, "@")
+	nick, user, hasUser := strings.Cut(nick, "!")
+	return nick != "" &&
+		(!hasUser || user != "" && !strings.Contains(user, "!")) &&
+		(!hasHost || host != "" && !strings.ContainsAny(host, "!@"))
+}
+
+// String returns the wire form of p built from its fields, as used for the
+// Prefix field of a Message. Raw is ignored.
+func (p *Prefix) String() string {
+	if p.IsServer {
+		return p.Host
+	}
+	s := p.Nickname
+	if p.User != "" {
+		s += "!" + p.User
+	}
+	if p.Host != "" {
+		s += "@" + p.Host
+	}
+	return s
+}
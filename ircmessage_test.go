@@ -1,6 +1,8 @@
 package ircmessage
 
 import (
+	"bytes"
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
@@ -116,6 +118,27 @@ var scannerTests = []struct {
 		},
 		nil,
 	},
+	{
+		"@time=2017-09-26T00:00:00.000Z FOO",
+		Message{
+			Tags:    map[string]string{"time": "2017-09-26T00:00:00.000Z"},
+			Command: "FOO",
+		},
+		nil,
+	},
+	{
+		"@msg=hello\\sworld\\:\\\\ FOO",
+		Message{
+			Tags:    map[string]string{"msg": "hello world;\\"},
+			Command: "FOO",
+		},
+		nil,
+	},
+	{
+		":n PRIVMSG #café :héllo \u263a \xff\xe2\x98 end",
+		Message{Prefix: "n", Command: "PRIVMSG", Params: []string{"#café", "héllo \u263a \xff\xe2\x98 end"}},
+		nil,
+	},
 }
 
 func TestScanner(t *testing.T) {
@@ -138,6 +161,26 @@ func TestScanner(t *testing.T) {
 	}
 }
 
+func TestScannerTee(t *testing.T) {
+	const in = "PING :a\r\n:n PRIVMSG #c :caf\xe9\r\n@bad=a=b FOO\r\n"
+	var tee bytes.Buffer
+	s := NewScanner(strings.NewReader(in))
+	s.Tee(&tee)
+	var msgs []Message
+	for s.Scan() {
+		msgs = append(msgs, s.Message())
+	}
+	if !errors.Is(s.Err(), ErrMessageMalformed) {
+		t.Errorf("expecting %v, got %v", ErrMessageMalformed, s.Err())
+	}
+	if tee.String() != in {
+		t.Errorf("expecting tee %q, got %q", in, tee.String())
+	}
+	if len(msgs) != 2 || msgs[1].Params[1] != "caf\xe9" {
+		t.Errorf("expecting invalid UTF-8 to be preserved, got %v", msgs)
+	}
+}
+
 var prefixTests = []struct {
 	in       string
 	expected *Prefix
@@ -160,6 +203,76 @@ var prefixTests = []struct {
 	{"!user@", nil},
 }
 
+var prefixStringTests = []struct {
+	in       Prefix
+	expected string
+}{
+	{Prefix{Nickname: "nick"}, "nick"},
+	{Prefix{Nickname: "nick", User: "user"}, "nick!user"},
+	{Prefix{Nickname: "nick", Host: "host"}, "nick@host"},
+	{Prefix{Nickname: "nick", User: "user", Host: "host", Raw: "x"}, "nick!user@host"},
+	{Prefix{IsServer: true, Host: "irc.example.com"}, "irc.example.com"},
+}
+
+func TestPrefixString(t *testing.T) {
+	for i, tt := range prefixStringTests {
+		if s := tt.in.String(); s != tt.expected {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, s)
+		}
+	}
+}
+
+func TestMessageClone(t *testing.T) {
+	m := Message{Tags: map[string]string{"a": "b"}, Command: "PRIVMSG", Params: []string{"#c", "hi"}}
+	c := m.Clone()
+	c.Tags["a"] = "x"
+	c.Params[1] = "changed"
+	if m.Tags["a"] != "b" || m.Params[1] != "hi" {
+		t.Errorf("clone shares storage with the original: %v", m)
+	}
+	if c := (Message{Command: "PING"}).Clone(); c.Tags != nil || c.Params != nil {
+		t.Errorf("expecting nil tags and params, got %v", c)
+	}
+}
+
+var messageEqualTests = []struct {
+	a, b     Message
+	equal    bool
+	equalRaw bool
+}{
+	{
+		Message{Raw: "@a=x\\sy;b PING\r\n", Tags: map[string]string{"a": "x y", "b": ""}, Command: "PING"},
+		Message{Raw: "@b=;a=x\\sy ping\r\n", Tags: map[string]string{"b": "", "a": "x y"}, Command: "ping"},
+		true, false,
+	},
+	{
+		Message{Raw: "PRIVMSG #c :hi\r\n", Command: "PRIVMSG", Params: []string{"#c", "hi"}},
+		Message{Command: "PRIVMSG", Params: []string{"#c", "hi"}},
+		true, false,
+	},
+	{
+		Message{Raw: "PRIVMSG #c hi\r\n", Command: "PRIVMSG", Params: []string{"#c", "hi"}},
+		Message{Command: "PRIVMSG", Params: []string{"#c", "hi"}},
+		true, true,
+	},
+	{Message{Command: "PING"}, Message{Command: "PING", Tags: map[string]string{}}, true, true},
+	{Message{Command: "PING", Params: []string{"a"}}, Message{Command: "PING", Params: []string{"b"}}, false, false},
+	{Message{Prefix: "a", Command: "PING"}, Message{Prefix: "b", Command: "PING"}, false, false},
+	{Message{}, Message{}, true, false},
+	{Message{Tags: map[string]string{"a": ""}, Command: "PING"}, Message{Tags: map[string]string{"b": ""}, Command: "PING"}, false, false},
+}
+
+func TestMessageEqual(t *testing.T) {
+	for i, tt := range messageEqualTests {
+		if eq := tt.a.Equal(tt.b); eq != tt.equal {
+			t.Errorf("%d. expecting Equal %t, got %t", i, tt.equal, eq)
+		}
+		if eq := tt.a.EqualRaw(tt.b); eq != tt.equalRaw {
+			t.Errorf("%d. expecting EqualRaw %t, got %t", i, tt.equalRaw, eq)
+		}
+	}
+}
+
 func TestParsePrefix(t *testing.T) {
 	for i, tt := range prefixTests {
 		p := ParsePrefix(tt.in)
@@ -172,5 +285,8 @@ func TestParsePrefix(t *testing.T) {
 		if !reflect.DeepEqual(p, tt.expected) {
 			t.Errorf("%d. expecting prefix: %v, got %v", i, *tt.expected, *p)
 		}
+		if p != nil && ParsePrefix(p.String()) == nil {
+			t.Errorf("%d. %q does not parse", i, p.String())
+		}
 	}
 }
@@ -0,0 +1,53 @@
+package irctest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is an ircmessage.Clock that only moves when told to, for driving
+// time-dependent features deterministically. Sleep advances the clock by
+// the duration slept rather than blocking. The zero value reads as the zero
+// time, and a Clock is safe for concurrent use.
+type Clock struct {
+	mu    sync.Mutex
+	now   time.Time
+	slept []time.Duration
+}
+
+// NewClock returns a Clock reading t.
+func NewClock(t time.Time) *Clock { return &Clock{now: t} }
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep records d and advances the clock by it.
+func (c *Clock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.slept = append(c.slept, d)
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Slept returns the durations passed to Sleep since the last call to
+// Slept.
+func (c *Clock) Slept() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	slept := c.slept
+	c.slept = nil
+	return slept
+}
@@ -0,0 +1,32 @@
+package irctest
+
+import (
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bruston/ircmessage"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+	var _ ircmessage.Clock = c
+	w := ircmessage.NewRateLimitedWriter(ircmessage.NewEncoder(io.Discard), 2, time.Second)
+	w.SetClock(c)
+	for i := 0; i < 4; i++ {
+		w.Encode(ircmessage.Message{Command: "PING"})
+	}
+	expected := []time.Duration{time.Second, time.Second}
+	if slept := c.Slept(); !reflect.DeepEqual(slept, expected) {
+		t.Errorf("expecting sleeps %v, got %v", expected, slept)
+	}
+	c.Advance(time.Minute)
+	if got := c.Now(); !got.Equal(start.Add(time.Minute + 2*time.Second)) {
+		t.Errorf("unexpected time %v", got)
+	}
+	if slept := c.Slept(); slept != nil {
+		t.Errorf("expecting no further sleeps, got %v", slept)
+	}
+}
@@ -0,0 +1,211 @@
+// Package irctest generates IRC messages for use in tests and fuzz corpora,
+// from ordinary traffic to deliberate edge cases such as lines at the
+// length limits, maximal tag sections and unusual prefixes. Everything it
+// generates is accepted by an ircmessage.Scanner with default settings, or
+// with the limits given.
+package irctest
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/bruston/ircmessage"
+)
+
+// Prefixes returns valid but unusual prefixes: bare nicknames and servers,
+// nicknames made of special characters, IPv6 and cloaked hosts, and users
+// and hosts without the other.
+func Prefixes() []string {
+	return []string{
+		"nick",
+		"irc.example.com",
+		"nick!user@host",
+		"nick!~user@host.example.com",
+		"nick@host",
+		"nick!user",
+		`[]\` + "`_^{|}!u@h",
+		"n-1!u@h",
+		"nick!user@2001:db8::1",
+		"nick!user@::1",
+		"nick!user@gateway/web/irccloud.com/x-abcdef",
+		"nick!user@user/nick/bot/helper",
+		"nick!user@127.0.0.1",
+		"NickServ!NickServ@services.",
+		"x.y",
+	}
+}
+
+// Valid returns a representative set of well-formed messages.
+func Valid() []ircmessage.Message {
+	return []ircmessage.Message{
+		{Command: "PING", Params: []string{"irc.example.com"}},
+		{Command: "NICK", Params: []string{"nick"}},
+		{Command: "USER", Params: []string{"user", "0", "*", "Real Name"}},
+		{Command: "CAP", Params: []string{"LS", "302"}},
+		{Prefix: "irc.example.com", Command: "CAP", Params: []string{"*", "LS", "message-tags server-time sasl=PLAIN"}},
+		{Command: "AUTHENTICATE", Params: []string{"PLAIN"}},
+		{Prefix: "nick!user@host", Command: "JOIN", Params: []string{"#chan"}},
+		{Prefix: "nick!user@host", Command: "PART", Params: []string{"#chan", "Goodbye"}},
+		{Prefix: "nick!user@host", Command: "QUIT", Params: []string{"Quit: leaving"}},
+		{Prefix: "nick!user@host", Command: "PRIVMSG", Params: []string{"#chan", "hello world"}},
+		{Prefix: "nick!user@host", Command: "NOTICE", Params: []string{"nick", "hi"}},
+		{Prefix: "op!user@host", Command: "MODE", Params: []string{"#chan", "+ov", "nick", "nick"}},
+		{Prefix: "op!user@host", Command: "KICK", Params: []string{"#chan", "nick", "Behave"}},
+		{Prefix: "nick!user@host", Command: "TOPIC", Params: []string{"#chan", "New topic"}},
+		{
+			Tags:    map[string]string{"time": "2026-10-16T12:00:00.000Z", "msgid": "abc123", "account": "nick"},
+			Prefix:  "nick!user@host",
+			Command: "PRIVMSG",
+			Params:  []string{"#chan", "tagged"},
+		},
+		{Tags: map[string]string{"+typing": "active"}, Prefix: "nick!user@host", Command: "TAGMSG", Params: []string{"#chan"}},
+		{Tags: map[string]string{"batch": "ref"}, Prefix: "irc.example.com", Command: "BATCH", Params: []string{"+ref", "chathistory", "#chan"}},
+	}
+}
+
+// EdgeCases returns raw lines, each ending with CRLF, that exercise the
+// corners of the grammar: empty and colon-led trailing parameters, runs of
+// spaces, escaped and valueless tags, CTCP and formatting codes, non-ASCII
+// text and lowercase commands.
+func EdgeCases() []string {
+	return []string{
+		"PING\r\n",
+		"PING :\r\n",
+		"PRIVMSG #chan :\r\n",
+		"PRIVMSG #chan ::starts with a colon\r\n",
+		"PRIVMSG #chan :  spaces  inside  and  out  \r\n",
+		"PRIVMSG #chan :a:b c:d\r\n",
+		"PRIVMSG  #chan   word\r\n",
+		"@a;b=;c=\\s\\:\\\\\\r\\n PING x\r\n",
+		"@+example.com/client-tag=1;vendor.example/key=v TAGMSG #chan\r\n",
+		"@unknown=\\x PING x\r\n",
+		"CMD 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15\r\n",
+		":nick!user@2001:db8::1 JOIN #chan\r\n",
+		"PRIVMSG #chan :\x01ACTION waves\x01\r\n",
+		"PRIVMSG #chan :\x01VERSION\x01\r\n",
+		"PRIVMSG #chan :\x02bold\x0f \x0304,12color\x03 \x1ditalic\x1d \x1funder\x1f\r\n",
+		"PRIVMSG #chan :héllo wörld ✓ \U0001F600\r\n",
+		"privmsg #chan :lowercase command\r\n",
+		"001 nick :no prefix numeric\r\n",
+		":irc.example.com 005 nick CHANTYPES=# PREFIX=(ov)@+ NETWORK=Example :are supported by this server\r\n",
+		"PRIVMSG #chan,&local,+modeless,!12345 :many targets\r\n",
+		"MODE #chan +b *!*@*\r\n",
+	}
+}
+
+// Numerics returns a reply for every numeric from 000 to 999, from a server
+// to the nickname "nick".
+func Numerics() []ircmessage.Message {
+	msgs := make([]ircmessage.Message, 0, 1000)
+	for n := 0; n < 1000; n++ {
+		msgs = append(msgs, ircmessage.Message{
+			Prefix:  "irc.example.com",
+			Command: fmt.Sprintf("%03d", n),
+			Params:  []string{"nick", "numeric reply " + strconv.Itoa(n)},
+		})
+	}
+	return msgs
+}
+
+// MaxLength returns a PRIVMSG whose encoding is exactly as long as the body
+// limit of limits allows, including the CRLF.
+func MaxLength(limits ircmessage.Limits) ircmessage.Message {
+	// A single word is encoded without a colon.
+	const overhead = len("PRIVMSG #chan \r\n")
+	body := limits.Body
+	if body == 0 {
+		body = ircmessage.DefaultLimits.Body
+	}
+	return ircmessage.Message{
+		Command: "PRIVMSG",
+		Params:  []string{"#chan", strings.Repeat("x", body-overhead)},
+	}
+}
+
+// MaxTags returns a PING whose tag section is exactly as long as the tag
+// limit of limits allows, made up of as many tags as fit. The TagValue
+// limit is not considered.
+func MaxTags(limits ircmessage.Limits) ircmessage.Message {
+	size := limits.Tags
+	if size == 0 {
+		size = ircmessage.DefaultLimits.Tags
+	}
+	const value = "0123456789abcdef"
+	tags := make(map[string]string)
+	used := len("@ ") - 1 // Less the separator counted with the first tag.
+	for i := 0; ; i++ {
+		key := fmt.Sprintf("k%04d", i)
+		// Leave room for this tag and a final one to fill the rest.
+		if size-used < 2*(len(key)+len(value)+2)+2 {
+			break
+		}
+		tags[key] = value
+		used += len(key) + len(value) + 2
+	}
+	tags["z"] = strings.Repeat("x", size-used-len(";z="))
+	return ircmessage.Message{Tags: tags, Command: "PING", Params: []string{"x"}}
+}
+
+// Random returns a random well-formed message that fits within the default
+// limits, drawing on r so that a fixed seed gives a reproducible sequence.
+// Tag values include characters that must be escaped, and parameters
+// include colons and, in the trailing parameter, spaces.
+func Random(r *rand.Rand) ircmessage.Message {
+	var m ircmessage.Message
+	if n := r.Intn(4); n > 0 {
+		m.Tags = make(map[string]string, n)
+		for i := 0; i < n; i++ {
+			key := randomString(r, 1+r.Intn(8), "abcdefghijklmnopqrstuvwxyz0123456789-")
+			if r.Intn(4) == 0 {
+				key = "+" + key
+			}
+			m.Tags[key] = randomString(r, r.Intn(16), "abc XYZ;\\:\r\né")
+		}
+	}
+	if r.Intn(2) == 0 {
+		prefixes := Prefixes()
+		m.Prefix = prefixes[r.Intn(len(prefixes))]
+	}
+	if r.Intn(3) == 0 {
+		m.Command = fmt.Sprintf("%03d", r.Intn(1000))
+	} else {
+		m.Command = randomString(r, 1+r.Intn(12), "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	}
+	for n := r.Intn(6); n > 0; n-- {
+		m.Params = append(m.Params, randomString(r, 1+r.Intn(10), "abc#&+-_:,!@*é"))
+		if m.Params[len(m.Params)-1][0] == ':' {
+			m.Params[len(m.Params)-1] = "x" + m.Params[len(m.Params)-1]
+		}
+	}
+	if r.Intn(2) == 0 {
+		m.Params = append(m.Params, randomString(r, r.Intn(80), "abc XYZ:\x01\x02\x03,.!?é✓"))
+	}
+	return m
+}
+
+func randomString(r *rand.Rand, n int, alphabet string) string {
+	runes := []rune(alphabet)
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteRune(runes[r.Intn(len(runes))])
+	}
+	return b.String()
+}
+
+// Corpus returns the wire form, with CRLF, of every message generated by
+// the package at the default limits, for seeding a fuzz test.
+func Corpus() []string {
+	var lines []string
+	msgs := append(Valid(), Numerics()...)
+	msgs = append(msgs, MaxLength(ircmessage.DefaultLimits), MaxTags(ircmessage.DefaultLimits))
+	for _, m := range msgs {
+		b, err := ircmessage.AppendMessage(nil, m)
+		if err != nil {
+			panic("irctest: " + err.Error())
+		}
+		lines = append(lines, string(b))
+	}
+	return append(lines, EdgeCases()...)
+}
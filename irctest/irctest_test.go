@@ -0,0 +1,79 @@
+package irctest
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/bruston/ircmessage"
+)
+
+func scan(line string, limits ircmessage.Limits) (ircmessage.Message, error) {
+	s := ircmessage.NewScanner(strings.NewReader(line))
+	s.SetLimits(limits)
+	if !s.Scan() {
+		return ircmessage.Message{}, s.Err()
+	}
+	return s.Message(), nil
+}
+
+func encode(m ircmessage.Message, limits ircmessage.Limits) (string, error) {
+	var buf bytes.Buffer
+	e := ircmessage.NewEncoder(&buf)
+	e.SetLimits(limits)
+	err := e.Encode(m)
+	return buf.String(), err
+}
+
+func TestCorpusScans(t *testing.T) {
+	for i, line := range Corpus() {
+		if _, err := scan(line, ircmessage.DefaultLimits); err != nil {
+			t.Errorf("%d. %q: %v", i, line, err)
+		}
+	}
+	for i, p := range Prefixes() {
+		if ircmessage.ParsePrefix(p) == nil {
+			t.Errorf("%d. expecting prefix %q to parse", i, p)
+		}
+	}
+	if n := len(Numerics()); n != 1000 {
+		t.Errorf("expecting 1000 numerics, got %d", n)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		m := Random(r)
+		line, err := encode(m, ircmessage.DefaultLimits)
+		if err != nil {
+			t.Fatalf("%d. %#v: %v", i, m, err)
+		}
+		got, err := scan(line, ircmessage.DefaultLimits)
+		if err != nil || !got.Equal(m) {
+			t.Fatalf("%d. expecting %#v, got %#v %v", i, m, got, err)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	for _, limits := range []ircmessage.Limits{ircmessage.DefaultLimits, ircmessage.ClientLimits, ircmessage.ServerLimits} {
+		line, err := encode(MaxLength(limits), limits)
+		if err != nil || len(line) != limits.Body {
+			t.Errorf("expecting a %d byte body, got %d %v", limits.Body, len(line), err)
+		}
+		m := MaxTags(limits)
+		line, err = encode(m, limits)
+		if n := strings.IndexByte(line, ' ') + 1; err != nil || n != limits.Tags {
+			t.Errorf("expecting a %d byte tag section, got %d %v", limits.Tags, n, err)
+		}
+		if got, err := scan(line, limits); err != nil || !got.Equal(m) {
+			t.Errorf("expecting tags to scan at %+v, got %v", limits, err)
+		}
+		m.Tags["z"] += "x"
+		if _, err := encode(m, limits); err == nil {
+			t.Errorf("expecting one more byte of tags to exceed %+v", limits)
+		}
+	}
+}
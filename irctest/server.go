@@ -0,0 +1,255 @@
+package irctest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/bruston/ircmessage"
+)
+
+// ServerName is the name a Server uses in the prefix of the replies built
+// by the script helpers.
+const ServerName = "irc.example.com"
+
+// A Step is one exchange in a Server's script: a message the client must
+// send, and the lines the server replies with.
+type Step struct {
+	// Expect is the line the client must send next, without the line
+	// ending, compared as a parsed message with the command compared
+	// case-insensitively and tags ignored. A parameter of "*" matches any
+	// value, and a final "..." any number of remaining parameters. An
+	// empty Expect sends the replies without waiting.
+	Expect string
+	Send   []string // Lines sent in reply, without line endings.
+}
+
+// Expect returns a Step expecting line and replying with the given lines.
+func Expect(line string, replies ...string) Step {
+	return Step{Expect: line, Send: replies}
+}
+
+// Send returns a Step sending lines without waiting for the client.
+func Send(lines ...string) Step { return Step{Send: lines} }
+
+// CapLS returns a Step answering CAP LS 302 with the available
+// capabilities, such as "sasl message-tags".
+func CapLS(caps string) Step {
+	return Expect("CAP LS 302", ":"+ServerName+" CAP * LS :"+caps)
+}
+
+// CapReq returns a Step acknowledging a CAP REQ for exactly the given
+// capabilities, in order.
+func CapReq(caps string) Step {
+	return Expect("CAP REQ :"+caps, ":"+ServerName+" CAP * ACK :"+caps)
+}
+
+// CapEnd returns a Step expecting CAP END.
+func CapEnd() Step { return Expect("CAP END") }
+
+// SASLPlain returns the Steps of a successful PLAIN authentication with the
+// given credentials and no authorization identity.
+func SASLPlain(nick, account, password string) []Step {
+	payload := base64.StdEncoding.EncodeToString([]byte("\x00" + account + "\x00" + password))
+	return []Step{
+		Expect("AUTHENTICATE PLAIN", "AUTHENTICATE +"),
+		Expect("AUTHENTICATE "+payload,
+			":"+ServerName+" 900 "+nick+" "+nick+"!user@host "+account+" :You are now logged in as "+account,
+			":"+ServerName+" 903 "+nick+" :SASL authentication successful",
+		),
+	}
+}
+
+// Register returns the Steps expecting a client to register as nick,
+// sending NICK and USER.
+func Register(nick string) []Step {
+	return []Step{Expect("NICK " + nick), Expect("USER * * * *")}
+}
+
+// Welcome returns a Step welcoming nick once registration is complete, with
+// the welcome numerics, an ISUPPORT line and the lack of a MOTD.
+func Welcome(nick string) Step {
+	reply := func(numeric, text string) string {
+		return ":" + ServerName + " " + numeric + " " + nick + " " + text
+	}
+	return Send(
+		reply("001", ":Welcome to the Example Internet Relay Chat Network "+nick),
+		reply("002", ":Your host is "+ServerName),
+		reply("003", ":This server was created today"),
+		reply("004", ServerName+" ircmessage-test iow biklmnopstv"),
+		reply("005", "CHANTYPES=# PREFIX=(ov)@+ NETWORK=Example CASEMAPPING=rfc1459 :are supported by this server"),
+		reply("422", ":MOTD File is missing"),
+	)
+}
+
+// Join returns a Step answering a JOIN of channel by nick with the JOIN
+// echo, the names list and its end.
+func Join(nick, channel string) Step {
+	return Expect("JOIN "+channel,
+		":"+nick+"!user@host JOIN "+channel,
+		":"+ServerName+" 353 "+nick+" = "+channel+" :@"+nick,
+		":"+ServerName+" 366 "+nick+" "+channel+" :End of /NAMES list.",
+	)
+}
+
+// Server is a mock IRC server for integration testing clients. It accepts a
+// single connection on a local port and follows a script, checking each
+// message the client sends against the next Step and replying with its
+// lines. Once the script is complete further messages are ignored.
+//
+// Scripts are built from the helpers for common exchanges:
+//
+//	script := append(irctest.Register("nick"), irctest.Welcome("nick"), irctest.Join("nick", "#chan"))
+//	srv := irctest.NewServer(script)
+//	defer srv.Close()
+type Server struct {
+	ln     net.Listener
+	script []step
+	done   chan struct{}
+
+	mu     sync.Mutex
+	conn   *ircmessage.Conn
+	closed bool
+	err    error
+}
+
+type step struct {
+	expect *ircmessage.Message
+	send   []ircmessage.Message
+}
+
+// NewServer starts a Server following script. It panics if a line in the
+// script does not parse.
+func NewServer(script []Step) *Server {
+	s := &Server{done: make(chan struct{})}
+	for _, st := range script {
+		s.script = append(s.script, compile(st))
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("irctest: failed to listen: " + err.Error())
+	}
+	s.ln = ln
+	go s.serve()
+	return s
+}
+
+func compile(st Step) step {
+	var c step
+	if st.Expect != "" {
+		m := mustParse(st.Expect)
+		c.expect = &m
+	}
+	for _, line := range st.Send {
+		c.send = append(c.send, mustParse(line))
+	}
+	return c
+}
+
+func mustParse(line string) ircmessage.Message {
+	s := ircmessage.NewScanner(strings.NewReader(line + "\r\n"))
+	s.SetLimits(ircmessage.ServerLimits)
+	if !s.Scan() {
+		panic(fmt.Sprintf("irctest: cannot parse %q: %v", line, s.Err()))
+	}
+	return s.Message().Detach()
+}
+
+// Addr returns the address the server is listening on, such as
+// "127.0.0.1:6667".
+func (s *Server) Addr() string { return s.ln.Addr().String() }
+
+func (s *Server) serve() {
+	c, err := s.ln.Accept()
+	s.ln.Close()
+	if err != nil {
+		s.finish(err)
+		return
+	}
+	conn := ircmessage.NewConn(c)
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		c.Close()
+		s.finish(net.ErrClosed)
+		return
+	}
+	s.conn = conn
+	s.mu.Unlock()
+	s.finish(s.run(conn))
+	// Drain the connection until it is closed.
+	for {
+		if _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) run(conn *ircmessage.Conn) error {
+	for i, st := range s.script {
+		if st.expect != nil {
+			m, err := conn.ReadMessage()
+			if err != nil {
+				return fmt.Errorf("irctest: step %d: expecting %q: %w", i, st.expect.Raw, err)
+			}
+			if !matches(*st.expect, m) {
+				return fmt.Errorf("irctest: step %d: expecting %q, got %q", i, st.expect.Raw, m.Raw)
+			}
+		}
+		for _, m := range st.send {
+			if err := conn.WriteMessage(m); err != nil {
+				return fmt.Errorf("irctest: step %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) finish(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	close(s.done)
+}
+
+// matches reports whether m is the message described by pattern.
+func matches(pattern, m ircmessage.Message) bool {
+	if !ircmessage.CommandEqual(pattern.Command, m.Command) {
+		return false
+	}
+	for i, p := range pattern.Params {
+		if p == "..." && i == len(pattern.Params)-1 {
+			return true
+		}
+		if i >= len(m.Params) || p != "*" && p != m.Params[i] {
+			return false
+		}
+	}
+	return len(m.Params) == len(pattern.Params)
+}
+
+// Wait waits for the script to finish and returns the first way in which
+// the client departed from it, if any.
+func (s *Server) Wait() error {
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Done returns a channel that is closed when the script finishes.
+func (s *Server) Done() <-chan struct{} { return s.done }
+
+// Close stops the server, closing any connection.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package irctest
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/bruston/ircmessage"
+)
+
+func TestServerRegistration(t *testing.T) {
+	var script []Step
+	script = append(script, CapLS("sasl message-tags"))
+	script = append(script, Register("nick")...)
+	script = append(script, CapReq("sasl"))
+	script = append(script, SASLPlain("nick", "account", "secret")...)
+	script = append(script, CapEnd(), Welcome("nick"), Join("nick", "#chan"))
+	srv := NewServer(script)
+	defer srv.Close()
+
+	c, err := net.Dial("tcp", srv.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := ircmessage.NewConn(c)
+	defer conn.Close()
+	sasl := ircmessage.NewSASLClient(ircmessage.NewCapNegotiator("sasl"), ircmessage.SASLPlain("", "account", "secret"))
+	reg := &ircmessage.Registration{Nicks: []string{"nick"}, User: "user", SASL: sasl}
+	send := func(msgs []ircmessage.Message) {
+		for _, m := range msgs {
+			if err := conn.WriteMessage(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	start, err := reg.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	send(start)
+	for !reg.Done() {
+		m, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := reg.Handle(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		send(out)
+	}
+	send([]ircmessage.Message{{Command: "JOIN", Params: []string{"#chan"}}})
+	for {
+		m, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Command == "366" {
+			break
+		}
+	}
+	if err := srv.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if sasl.Account() != "account" {
+		t.Errorf("expecting to be logged in as account, got %q", sasl.Account())
+	}
+}
+
+func TestServerMismatch(t *testing.T) {
+	srv := NewServer([]Step{Expect("PING ..."), Expect("JOIN #chan")})
+	defer srv.Close()
+	c, err := net.Dial("tcp", srv.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.Write([]byte("PING a b\r\nJOIN #other\r\n"))
+	err = srv.Wait()
+	if err == nil || !strings.Contains(err.Error(), `step 1: expecting "JOIN #chan\r\n", got "JOIN #other\r\n"`) {
+		t.Errorf("expecting a mismatch at step 1, got %v", err)
+	}
+}
+
+var matchTests = []struct {
+	pattern, line string
+	expected      bool
+}{
+	{"NICK nick", "nick nick", true},
+	{"NICK nick", "NICK other", false},
+	{"USER * * * *", "USER u 0 * :Real Name", true},
+	{"USER * * * *", "USER u 0 *", false},
+	{"CAP REQ :a b", "CAP REQ :a b", true},
+	{"CAP REQ :a b", "CAP REQ a", false},
+	{"PRIVMSG #chan ...", "PRIVMSG #chan :hi", true},
+	{"PRIVMSG #chan ...", "PRIVMSG #other :hi", false},
+}
+
+func TestMatches(t *testing.T) {
+	for i, tt := range matchTests {
+		if got := matches(mustParse(tt.pattern), mustParse(tt.line)); got != tt.expected {
+			t.Errorf("%d. %q %q: expecting %v, got %v", i, tt.pattern, tt.line, tt.expected, got)
+		}
+	}
+}
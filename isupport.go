@@ -0,0 +1,175 @@
+package ircmessage
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ISupport holds the parameters a server advertises in RPL_ISUPPORT (005)
+// replies, as per: https://modern.ircdocs.horse/#rplisupport-parameters
+//
+// The zero value is ready to use. Methods other than Update may be called on
+// a nil *ISupport, returning the defaults that apply before the server has
+// advertised anything.
+type ISupport struct {
+	tokens map[string]string
+}
+
+// NewISupport returns an empty ISupport.
+func NewISupport() *ISupport {
+	return &ISupport{tokens: make(map[string]string)}
+}
+
+// Update applies the tokens of an RPL_ISUPPORT message, reporting whether m
+// was one. Tokens prefixed with '-' remove a previously advertised token.
+func (is *ISupport) Update(m Message) bool {
+	if m.Command != "005" || len(m.Params) < 3 {
+		return false
+	}
+	if is.tokens == nil {
+		is.tokens = make(map[string]string)
+	}
+	for _, tok := range m.Params[1 : len(m.Params)-1] {
+		if strings.HasPrefix(tok, "-") {
+			delete(is.tokens, tok[1:])
+			continue
+		}
+		k, v, _ := strings.Cut(tok, tokenEquals)
+		is.tokens[k] = unescapeISupportValue(v)
+	}
+	return true
+}
+
+// unescapeISupportValue decodes the \xHH escapes used in token values.
+func unescapeISupportValue(v string) string {
+	if !strings.Contains(v, `\x`) {
+		return v
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '\\' && i+3 < len(v) && v[i+1] == 'x' {
+			if n, err := strconv.ParseUint(v[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(v[i])
+	}
+	return b.String()
+}
+
+// Get returns the value of token name and whether it has been advertised.
+// Tokens without a value have an empty value.
+func (is *ISupport) Get(name string) (string, bool) {
+	if is == nil {
+		return "", false
+	}
+	v, ok := is.tokens[name]
+	return v, ok
+}
+
+func (is *ISupport) getDefault(name, def string) string {
+	if v, ok := is.Get(name); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+func (is *ISupport) getInt(name string, def int) int {
+	if n, err := strconv.Atoi(is.getDefault(name, "")); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+// ChanTypes returns the channel prefix characters, "#&" by default.
+func (is *ISupport) ChanTypes() string { return is.getDefault("CHANTYPES", "#&") }
+
+// StatusMsg returns the membership prefixes that may precede a channel name
+// to address only members with that status, such as "@+".
+func (is *ISupport) StatusMsg() string { return is.getDefault("STATUSMSG", "") }
+
+// Casemapping returns the casemapping in use, "rfc1459" by default.
+func (is *ISupport) Casemapping() string { return is.getDefault("CASEMAPPING", "rfc1459") }
+
+// ChannelLen returns the maximum length of a channel name, 200 by default.
+func (is *ISupport) ChannelLen() int { return is.getInt("CHANNELLEN", 200) }
+
+// NickLen returns the maximum length of a nickname, 9 by default.
+func (is *ISupport) NickLen() int { return is.getInt("NICKLEN", 9) }
+
+// Watch returns the maximum number of WATCH entries, or 0 if the server does
+// not support WATCH.
+func (is *ISupport) Watch() int { return is.getInt("WATCH", 0) }
+
+// Silence returns the maximum number of SILENCE entries, or 0 if the server
+// does not support SILENCE.
+func (is *ISupport) Silence() int { return is.getInt("SILENCE", 0) }
+
+// Modes returns the maximum number of modes with a parameter that may be
+// changed by one MODE message, 3 by default, or 0 if there is no limit.
+func (is *ISupport) Modes() int {
+	if _, ok := is.Get("MODES"); !ok {
+		return 3
+	}
+	return is.getInt("MODES", 0)
+}
+
+// Prefix returns the channel membership modes and the prefixes shown for
+// them, in order of rank, "ov" and "@+" by default.
+func (is *ISupport) Prefix() (modes, prefixes string) {
+	v := is.getDefault("PREFIX", "(ov)@+")
+	modes, prefixes, ok := strings.Cut(strings.TrimPrefix(v, "("), ")")
+	if !ok || len(modes) != len(prefixes) {
+		return "ov", "@+"
+	}
+	return modes, prefixes
+}
+
+// ChanModes returns the channel modes of each type: those that add to or
+// remove from a list and always take a parameter, those that always take
+// a parameter, those that take one only when set, and those that never
+// do. The default is "b", "k", "l" and "imnpst".
+func (is *ISupport) ChanModes() (list, always, set, never string) {
+	v := is.getDefault("CHANMODES", "b,k,l,imnpst")
+	types := strings.SplitN(v, ",", 4)
+	for len(types) < 4 {
+		types = append(types, "")
+	}
+	return types[0], types[1], types[2], strings.ReplaceAll(types[3], ",", "")
+}
+
+// IsChannel reports whether name is a channel name.
+func (is *ISupport) IsChannel(name string) bool {
+	return name != "" && strings.IndexByte(is.ChanTypes(), name[0]) >= 0
+}
+
+// Fold returns s casefolded according to the server's casemapping, so that
+// two names are equivalent exactly when their folded forms are equal.
+func (is *ISupport) Fold(s string) string {
+	switch is.Casemapping() {
+	case "ascii":
+		return foldASCII(s, "", "")
+	case "strict-rfc1459":
+		return foldASCII(s, `[]\`, `{}|`)
+	case "rfc7613", "precis":
+		return strings.Map(unicode.ToLower, s)
+	}
+	return foldASCII(s, `[]\~`, `{}|^`)
+}
+
+// foldASCII lowers ASCII letters in s, along with the extra characters in
+// upper which map to the character at the same index in lower.
+func foldASCII(s, upper, lower string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		} else if j := strings.IndexByte(upper, c); j >= 0 {
+			b[i] = lower[j]
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,69 @@
+package ircmessage
+
+import "testing"
+
+func TestISupport(t *testing.T) {
+	is := NewISupport()
+	if is.ChanTypes() != "#&" || is.Casemapping() != "rfc1459" {
+		t.Error("unexpected defaults")
+	}
+	is.Update(Message{Command: "005", Params: []string{"nick", "CHANTYPES=#", "EXCEPTS", "NETWORK=Example\\x20Net", "CASEMAPPING=ascii", "are supported"}})
+	if v, ok := is.Get("EXCEPTS"); !ok || v != "" {
+		t.Errorf("expecting EXCEPTS without a value, got %q (%v)", v, ok)
+	}
+	if v, _ := is.Get("NETWORK"); v != "Example Net" {
+		t.Errorf("expecting unescaped network name, got %q", v)
+	}
+	if is.IsChannel("&local") || !is.IsChannel("#chan") {
+		t.Error("expecting CHANTYPES to be honoured")
+	}
+	is.Update(Message{Command: "005", Params: []string{"nick", "-EXCEPTS", "are supported"}})
+	if _, ok := is.Get("EXCEPTS"); ok {
+		t.Error("expecting EXCEPTS to be removed")
+	}
+	var nilIS *ISupport
+	if nilIS.ChanTypes() != "#&" {
+		t.Error("expecting defaults from a nil ISupport")
+	}
+}
+
+var foldTests = []struct {
+	casemapping, in, expected string
+}{
+	{"rfc1459", "Nick[]\\~", "nick{}|^"},
+	{"strict-rfc1459", "Nick[]\\~", "nick{}|~"},
+	{"ascii", "Nick[]\\~", "nick[]\\~"},
+	{"rfc7613", "NÏCK", "nïck"},
+}
+
+func TestISupportFold(t *testing.T) {
+	for i, tt := range foldTests {
+		is := NewISupport()
+		is.tokens["CASEMAPPING"] = tt.casemapping
+		if got := is.Fold(tt.in); got != tt.expected {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, got)
+		}
+	}
+}
+
+func TestISupportModes(t *testing.T) {
+	var is *ISupport
+	if modes, prefixes := is.Prefix(); modes != "ov" || prefixes != "@+" {
+		t.Errorf("expecting default prefix (ov)@+, got (%s)%s", modes, prefixes)
+	}
+	is = NewISupport()
+	is.Update(Message{Command: "005", Params: []string{"nick", "PREFIX=(qaohv)~&@%+", "CHANMODES=beI,k,fl,CMnst", "are supported"}})
+	if modes, prefixes := is.Prefix(); modes != "qaohv" || prefixes != "~&@%+" {
+		t.Errorf("expecting prefix (qaohv)~&@%%+, got (%s)%s", modes, prefixes)
+	}
+	if a, b, c, d := is.ChanModes(); a != "beI" || b != "k" || c != "fl" || d != "CMnst" {
+		t.Errorf("unexpected CHANMODES %q %q %q %q", a, b, c, d)
+	}
+	is.Update(Message{Command: "005", Params: []string{"nick", "PREFIX=(ov)@", "CHANMODES=b", "are supported"}})
+	if modes, _ := is.Prefix(); modes != "ov" {
+		t.Errorf("expecting a malformed PREFIX to give the default, got %q", modes)
+	}
+	if a, b, c, d := is.ChanModes(); a != "b" || b != "" || c != "" || d != "" {
+		t.Errorf("unexpected CHANMODES %q %q %q %q", a, b, c, d)
+	}
+}
@@ -0,0 +1,177 @@
+package ircmessage
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrJournalCorrupt is returned when a journal record cannot be read.
+var ErrJournalCorrupt = errors.New("corrupt journal record")
+
+// JournalWriter appends messages to a journal, an append-only record of
+// messages and the times they were received, for bouncer playback and
+// auditing. Each record is the receive time in RFC 3339 format with
+// nanoseconds, a space, the length of the line in bytes, a space, and the
+// line itself, ending with CRLF:
+//
+//	2026-10-16T12:00:00.5Z 22 :nick PRIVMSG #c :hi\r\n
+//
+// The length lets a reader notice a record cut short by a crash.
+//
+// A JournalWriter is safe for concurrent use, and writes each record with a
+// single call to Write, so that a file opened for appending is never left
+// with interleaved records.
+type JournalWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf []byte
+}
+
+// NewJournalWriter returns a JournalWriter that writes to w.
+func NewJournalWriter(w io.Writer) *JournalWriter {
+	return &JournalWriter{w: w}
+}
+
+// OpenJournal opens the named journal file for appending, creating it if
+// necessary.
+func OpenJournal(name string) (*JournalWriter, error) {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewJournalWriter(f), nil
+}
+
+// Append records m as received at t. The Raw field of m is recorded if set,
+// so that messages are kept exactly as they arrived apart from the line
+// ending, which is always CRLF, and otherwise its encoding.
+func (j *JournalWriter) Append(m Message, t time.Time) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var line []byte
+	if m.Raw == "" {
+		var err error
+		if line, err = AppendMessage(nil, m); err != nil {
+			return err
+		}
+	} else {
+		line = []byte(strings.TrimSuffix(strings.TrimSuffix(m.Raw, "\n"), "\r"))
+		line = append(line, '\r', '\n')
+	}
+	b := t.UTC().AppendFormat(j.buf[:0], time.RFC3339Nano)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, int64(len(line)), 10)
+	b = append(b, ' ')
+	b = append(b, line...)
+	j.buf = b
+	_, err := j.w.Write(b)
+	return err
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (j *JournalWriter) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if c, ok := j.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// JournalReader replays a journal through an embedded Scanner, whose
+// methods and settings, such as its profile, limits and pipeline, apply to
+// the recorded lines as they would to live input. A message dropped by the
+// pipeline is skipped.
+type JournalReader struct {
+	*Scanner
+	src  *bufio.Reader
+	line strings.Reader
+	t    time.Time
+	err  error
+}
+
+// NewJournalReader returns a JournalReader that reads a journal from r.
+func NewJournalReader(r io.Reader) *JournalReader {
+	j := &JournalReader{src: bufio.NewReader(r)}
+	j.Scanner = NewScanner(&j.line)
+	return j
+}
+
+// Scan advances to the next recorded message, with the same semantics as
+// Scanner.Scan. A journal ending part way through a record, as after a
+// crash, stops the scan with io.ErrUnexpectedEOF.
+func (j *JournalReader) Scan() bool {
+	for j.err == nil {
+		t, line, err := j.readRecord()
+		if err != nil {
+			j.err = err
+			return false
+		}
+		j.line.Reset(line)
+		j.Scanner.src.Reset(&j.line)
+		j.Scanner.err = nil
+		if j.Scanner.Scan() {
+			j.t = t
+			return true
+		}
+		if j.Scanner.err != io.EOF {
+			j.err = j.Scanner.err
+			return false
+		}
+	}
+	return false
+}
+
+// readRecord reads the next record, returning io.EOF if there are no more.
+func (j *JournalReader) readRecord() (time.Time, string, error) {
+	stamp, err := j.src.ReadString(' ')
+	if err != nil {
+		if err == io.EOF && stamp != "" {
+			err = io.ErrUnexpectedEOF
+		}
+		return time.Time{}, "", err
+	}
+	t, err := time.Parse(time.RFC3339Nano, stamp[:len(stamp)-1])
+	if err != nil {
+		return time.Time{}, "", ErrJournalCorrupt
+	}
+	size, err := j.src.ReadString(' ')
+	if err == io.EOF {
+		return time.Time{}, "", io.ErrUnexpectedEOF
+	} else if err != nil {
+		return time.Time{}, "", err
+	}
+	n, err := strconv.Atoi(size[:len(size)-1])
+	if err != nil || n < 2 || n > 1<<20 {
+		return time.Time{}, "", ErrJournalCorrupt
+	}
+	line := make([]byte, n)
+	if _, err := io.ReadFull(j.src, line); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return time.Time{}, "", err
+	}
+	if line[n-2] != '\r' || line[n-1] != '\n' {
+		return time.Time{}, "", ErrJournalCorrupt
+	}
+	return t, string(line), nil
+}
+
+// Time returns the time at which the most recent message returned by Scan
+// was received.
+func (j *JournalReader) Time() time.Time { return j.t }
+
+// Err returns the first error encountered, other than io.EOF.
+func (j *JournalReader) Err() error {
+	if j.err == nil || j.err == io.EOF {
+		return nil
+	}
+	return j.err
+}
@@ -0,0 +1,95 @@
+package ircmessage
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	var buf bytes.Buffer
+	j := NewJournalWriter(&buf)
+	t0 := time.Date(2026, 10, 16, 12, 0, 0, 500000000, time.UTC)
+	s := NewScanner(strings.NewReader("@a=b  :nick PRIVMSG #c :hi\r\nPING x\r\n"))
+	for i := 0; s.Scan(); i++ {
+		if err := j.Append(s.Message(), t0.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A Raw with a bare LF, as from a log written with TerminatorLF.
+	if err := j.Append(Message{Raw: "PING y\n", Command: "PING", Params: []string{"y"}}, t0.Add(2*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Append(Message{Command: "PONG", Params: []string{"x"}}, t0.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	expected := "2026-10-16T12:00:00.5Z 28 @a=b  :nick PRIVMSG #c :hi\r\n" +
+		"2026-10-16T12:00:01.5Z 8 PING x\r\n" +
+		"2026-10-16T12:00:02.5Z 8 PING y\r\n" +
+		"2026-10-16T12:01:00.5Z 8 PONG x\r\n"
+	if buf.String() != expected {
+		t.Fatalf("expecting %q, got %q", expected, buf.String())
+	}
+
+	r := NewJournalReader(strings.NewReader(expected))
+	r.SetPipeline(Pipeline{Filter(func(m Message) bool { return m.Command != "PING" })})
+	var got []string
+	for r.Scan() {
+		got = append(got, r.Time().Format(time.TimeOnly)+" "+r.Message().Raw)
+	}
+	if r.Err() != nil {
+		t.Fatal(r.Err())
+	}
+	want := []string{"12:00:00 @a=b  :nick PRIVMSG #c :hi\r\n", "12:01:00 PONG x\r\n"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expecting %q, got %q", want, got)
+	}
+}
+
+var journalErrorTests = []struct {
+	in  string
+	err error
+}{
+	{"2026-10-16T12:00:00Z 8 PING", io.ErrUnexpectedEOF},
+	{"2026-10-16T12:00:00Z 8", io.ErrUnexpectedEOF},
+	{"2026-10-16T12:00:00Z", io.ErrUnexpectedEOF},
+	{"yesterday 8 PING x\r\n", ErrJournalCorrupt},
+	{"2026-10-16T12:00:00Z eight PING x\r\n", ErrJournalCorrupt},
+	{"2026-10-16T12:00:00Z 7 PING x\r\n", ErrJournalCorrupt},
+}
+
+func TestJournalErrors(t *testing.T) {
+	for i, tt := range journalErrorTests {
+		r := NewJournalReader(strings.NewReader(tt.in))
+		if r.Scan() || r.Err() != tt.err {
+			t.Errorf("%d. expecting error %v, got %v", i, tt.err, r.Err())
+		}
+	}
+}
+
+func TestOpenJournal(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "journal")
+	for i := 0; i < 2; i++ {
+		j, err := OpenJournal(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := j.Append(Message{Command: "PING", Params: []string{"x"}}, time.Unix(0, 0)); err != nil {
+			t.Fatal(err)
+		}
+		if err := j.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(b), "PING x\r\n"); n != 2 {
+		t.Errorf("expecting 2 appended records, got %d in %q", n, b)
+	}
+}
@@ -0,0 +1,169 @@
+package ircmessage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// jsonMessage is the JSON form of a message used by JSONEncoder and
+// JSONDecoder, such as:
+//
+//	{"tags":{"time":"2026-10-16T12:00:00.000Z"},"prefix":"nick!user@host","command":"PRIVMSG","params":["#chan","hi"]}
+type jsonMessage struct {
+	Tags    map[string]string `json:"tags,omitempty"`
+	Prefix  string            `json:"prefix,omitempty"`
+	Command string            `json:"command"`
+	Params  []string          `json:"params,omitempty"`
+	Raw     string            `json:"raw,omitempty"`
+}
+
+// JSONEncoder writes messages as line-delimited JSON events, one object per
+// line with the fields tags, prefix, command, params and raw, the last
+// holding the Raw field without its line ending. Empty fields are omitted.
+// JSON strings hold UTF-8, so any invalid UTF-8 in a message, which the
+// Scanner keeps verbatim, is replaced with U+FFFD.
+// A JSONEncoder is safe for concurrent use.
+type JSONEncoder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONEncoder returns a JSONEncoder that writes to w.
+func NewJSONEncoder(w io.Writer) *JSONEncoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONEncoder{enc: enc}
+}
+
+// Encode writes m as a line of JSON.
+func (e *JSONEncoder) Encode(m Message) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enc.Encode(jsonMessage{
+		Tags:    m.Tags,
+		Prefix:  m.Prefix,
+		Command: m.Command,
+		Params:  m.Params,
+		Raw:     strings.TrimSuffix(m.Raw, "\r\n"),
+	})
+}
+
+// jsonError is the JSON event reporting a command that could not be sent,
+// such as:
+//
+//	{"error":"message malformed","command":"PRIVMSG"}
+type jsonError struct {
+	Error   string `json:"error"`
+	Command string `json:"command,omitempty"`
+}
+
+// encodeError writes an event reporting that a message with command could
+// not be sent because of err.
+func (e *JSONEncoder) encodeError(command string, err error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enc.Encode(jsonError{Error: err.Error(), Command: command})
+}
+
+// JSONDecoder reads messages from line-delimited JSON commands, in the
+// form written by JSONEncoder. The raw field, and any field not in that
+// form, is rejected, as is a message without a command. Messages are not
+// otherwise checked; an Encoder checks them as they are sent.
+type JSONDecoder struct {
+	sc  *bufio.Scanner
+	msg Message
+	err error
+}
+
+// NewJSONDecoder returns a JSONDecoder that reads from r.
+func NewJSONDecoder(r io.Reader) *JSONDecoder {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64*1024)
+	return &JSONDecoder{sc: sc}
+}
+
+// Scan advances to the next message, which is then available through the
+// Message method. Blank lines are skipped. It returns false at the end of
+// the input or on an error, which stops the scan.
+func (d *JSONDecoder) Scan() bool {
+	for d.err == nil && d.sc.Scan() {
+		line := bytes.TrimSpace(d.sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.DisallowUnknownFields()
+		var jm jsonMessage
+		if err := dec.Decode(&jm); err != nil {
+			d.err = err
+			return false
+		}
+		if jm.Raw != "" || jm.Command == "" {
+			d.err = ErrMessageMalformed
+			return false
+		}
+		d.msg = Message{Tags: jm.Tags, Prefix: jm.Prefix, Command: jm.Command, Params: jm.Params}
+		return true
+	}
+	return false
+}
+
+// Message returns the most recent message read by Scan.
+func (d *JSONDecoder) Message() Message { return d.msg }
+
+// Err returns the first error encountered, other than io.EOF.
+func (d *JSONDecoder) Err() error {
+	if d.err != nil {
+		return d.err
+	}
+	return d.sc.Err()
+}
+
+// ServeJSON runs a gateway between IRC and JSON: messages from src are
+// written to w as JSON events, and JSON commands read from r are encoded
+// to enc. A command that cannot be encoded is answered with an event such
+// as {"error":"message malformed","command":"PRIVMSG"} and skipped. It
+// returns when either direction stops, with its error, or nil if its input
+// ended. The other direction is left to stop when its input does, so
+// callers should then close both connections.
+func ServeJSON(src MessageSource, enc *Encoder, r io.Reader, w io.Writer) error {
+	errc := make(chan error, 2)
+	je := NewJSONEncoder(w)
+	go func() {
+		for src.Scan() {
+			if err := je.Encode(src.Message()); err != nil {
+				errc <- err
+				return
+			}
+		}
+		errc <- src.Err()
+	}()
+	go func() {
+		jd := NewJSONDecoder(r)
+		for jd.Scan() {
+			m := jd.Message()
+			err := enc.Encode(m)
+			if isEncodingError(err) {
+				err = je.encodeError(m.Command, err)
+			}
+			if err != nil {
+				errc <- err
+				return
+			}
+		}
+		errc <- jd.Err()
+	}()
+	return <-errc
+}
+
+// isEncodingError reports whether err is an Encoder's refusal to encode a
+// message, rather than a failure to write it.
+func isEncodingError(err error) bool {
+	return errors.Is(err, ErrMessageMalformed) || errors.Is(err, ErrLineTooLong) ||
+		errors.Is(err, ErrTagTooLong)
+}
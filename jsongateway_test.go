@@ -0,0 +1,106 @@
+package ircmessage
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewScanner(strings.NewReader("@time=2026-10-16T12:00:00.000Z :nick!u@h PRIVMSG #chan :<hi> & bye\r\nPING\r\n"))
+	je := NewJSONEncoder(&buf)
+	for s.Scan() {
+		if err := je.Encode(s.Message()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := `{"tags":{"time":"2026-10-16T12:00:00.000Z"},"prefix":"nick!u@h","command":"PRIVMSG","params":["#chan","<hi> & bye"],"raw":"@time=2026-10-16T12:00:00.000Z :nick!u@h PRIVMSG #chan :<hi> & bye"}` + "\n" +
+		`{"command":"PING","raw":"PING"}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expecting\n%s, got\n%s", expected, buf.String())
+	}
+}
+
+var jsonDecoderTests = []struct {
+	in       string
+	expected []Message
+	err      error
+}{
+	{
+		`{"command":"PRIVMSG","params":["#chan","hi there"]}` + "\n\n" + `{"tags":{"+typing":"active"},"command":"TAGMSG","params":["#chan"]}`,
+		[]Message{
+			{Command: "PRIVMSG", Params: []string{"#chan", "hi there"}},
+			{Tags: map[string]string{"+typing": "active"}, Command: "TAGMSG", Params: []string{"#chan"}},
+		},
+		nil,
+	},
+	{`{"command":"PING"}` + "\n" + `{"params":["x"]}`, []Message{{Command: "PING"}}, ErrMessageMalformed},
+	{`{"command":"PING","raw":"PONG"}`, nil, ErrMessageMalformed},
+}
+
+func TestJSONDecoder(t *testing.T) {
+	for i, tt := range jsonDecoderTests {
+		d := NewJSONDecoder(strings.NewReader(tt.in))
+		var got []Message
+		for d.Scan() {
+			got = append(got, d.Message())
+		}
+		if d.Err() != tt.err || !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%d. expecting %v %v, got %v %v", i, tt.expected, tt.err, got, d.Err())
+		}
+	}
+	for i, in := range []string{`{"command":"PING","extra":1}`, `{"command":`} {
+		d := NewJSONDecoder(strings.NewReader(in))
+		if d.Scan() || d.Err() == nil {
+			t.Errorf("%d. expecting an error decoding %s", i, in)
+		}
+	}
+}
+
+func TestServeJSON(t *testing.T) {
+	// Each direction is checked with the other idle, so that ServeJSON
+	// returns once the direction under test ends.
+	var events bytes.Buffer
+	src := NewScanner(strings.NewReader(":s 001 me :Welcome\r\n"))
+	if err := ServeJSON(src, NewEncoder(io.Discard), blockingReader{}, &events); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"prefix":"s","command":"001","params":["me","Welcome"],"raw":":s 001 me :Welcome"}` + "\n"; events.String() != expected {
+		t.Errorf("expecting %s, got %s", expected, events.String())
+	}
+
+	var irc bytes.Buffer
+	commands := strings.NewReader(`{"command":"JOIN","params":["#chan"]}` + "\n")
+	if err := ServeJSON(NewScanner(blockingReader{}), NewEncoder(&irc), commands, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if irc.String() != "JOIN #chan\r\n" {
+		t.Errorf("expecting JOIN #chan, got %q", irc.String())
+	}
+
+	// A command that cannot be encoded is reported and skipped.
+	irc.Reset()
+	events.Reset()
+	commands = strings.NewReader(`{"command":"PRIVMSG","params":["#chan","a\r\nQUIT"]}` + "\n" +
+		`{"command":"PRIVMSG","params":["two words","hi"]}` + "\n" +
+		`{"command":"JOIN","params":["#chan"]}` + "\n")
+	if err := ServeJSON(NewScanner(blockingReader{}), NewEncoder(&irc), commands, &events); err != nil {
+		t.Fatal(err)
+	}
+	if irc.String() != "JOIN #chan\r\n" {
+		t.Errorf("expecting JOIN #chan, got %q", irc.String())
+	}
+	expected := `{"error":"message malformed","command":"PRIVMSG"}` + "\n" +
+		`{"error":"message malformed","command":"PRIVMSG"}` + "\n"
+	if events.String() != expected {
+		t.Errorf("expecting %s, got %s", expected, events.String())
+	}
+}
+
+// blockingReader never returns, standing in for an idle connection.
+type blockingReader struct{}
+
+func (blockingReader) Read([]byte) (int, error) { select {} }
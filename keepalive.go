@@ -0,0 +1,125 @@
+package ircmessage
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrStalled is returned by Conn.ReadMessage when keepalive is enabled and
+// the server fails to respond to a PING in time.
+var ErrStalled = errors.New("connection stalled")
+
+const keepaliveToken = "ircmessage-keepalive"
+
+type keepaliveAction int
+
+const (
+	keepaliveNone keepaliveAction = iota
+	keepalivePing
+	keepaliveStall
+)
+
+type keepalive struct {
+	idle, timeout time.Duration
+	done          chan struct{}
+
+	mu       sync.Mutex
+	lastRead time.Time
+	pingSent time.Time // Zero when no PING is outstanding.
+	stalled  bool
+}
+
+// seen records that a message arrived from the server, which is taken as
+// proof that the connection is alive.
+func (ka *keepalive) seen(now time.Time) {
+	ka.mu.Lock()
+	ka.lastRead = now
+	ka.pingSent = time.Time{}
+	ka.mu.Unlock()
+}
+
+// check decides what to do at time now, returning the action to take and
+// how long to wait before checking again.
+func (ka *keepalive) check(now time.Time) (keepaliveAction, time.Duration) {
+	ka.mu.Lock()
+	defer ka.mu.Unlock()
+	if !ka.pingSent.IsZero() {
+		if wait := ka.pingSent.Add(ka.timeout).Sub(now); wait > 0 {
+			return keepaliveNone, wait
+		}
+		ka.stalled = true
+		return keepaliveStall, 0
+	}
+	if wait := ka.lastRead.Add(ka.idle).Sub(now); wait > 0 {
+		return keepaliveNone, wait
+	}
+	ka.pingSent = now
+	return keepalivePing, ka.timeout
+}
+
+func (ka *keepalive) isStalled() bool {
+	ka.mu.Lock()
+	defer ka.mu.Unlock()
+	return ka.stalled
+}
+
+// Keepalive enables automatic keepalive on the connection. Server PINGs are
+// answered as they are read by ReadMessage; they are still returned so that
+// they may be logged, but must not be answered again. If nothing is received
+// for the idle period a PING is sent to the server, and if nothing arrives
+// within timeout of that the connection is closed and ReadMessage returns
+// ErrStalled.
+//
+// Keepalive must be called at most once, before the first call to ReadMessage.
+func (c *Conn) Keepalive(idle, timeout time.Duration) {
+	ka := &keepalive{
+		idle:     idle,
+		timeout:  timeout,
+		done:     make(chan struct{}),
+		lastRead: time.Now(),
+	}
+	c.ka = ka
+	go c.keepaliveLoop(ka)
+}
+
+func (c *Conn) keepaliveLoop(ka *keepalive) {
+	t := time.NewTimer(ka.idle)
+	defer t.Stop()
+	for {
+		select {
+		case <-ka.done:
+			return
+		case <-t.C:
+		}
+		action, wait := ka.check(time.Now())
+		switch action {
+		case keepalivePing:
+			c.writeNow(Message{Command: "PING", Params: []string{keepaliveToken}})
+		case keepaliveStall:
+			c.conn.Close()
+			return
+		}
+		t.Reset(wait)
+	}
+}
+
+// handleKeepalive updates the keepalive state with a message that has just
+// been read, answering it if it is a PING.
+func (c *Conn) handleKeepalive(m Message) {
+	c.ka.seen(time.Now())
+	if HasCommand(m, "PING") {
+		c.writeNow(Message{Command: "PONG", Params: m.Params})
+	}
+}
+
+// writeNow writes m, flushing it and anything buffered before it.
+func (c *Conn) writeNow(m Message) error {
+	if err := c.WriteMessage(m); err != nil {
+		return err
+	}
+	if c.flushPolicy == FlushEach {
+		return nil
+	}
+	return c.Flush()
+}
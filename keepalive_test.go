@@ -0,0 +1,72 @@
+package ircmessage
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestKeepaliveCheck(t *testing.T) {
+	start := time.Unix(0, 0)
+	ka := &keepalive{idle: time.Minute, timeout: 10 * time.Second, lastRead: start}
+	steps := []struct {
+		at     time.Duration
+		seen   bool
+		action keepaliveAction
+		wait   time.Duration
+	}{
+		{30 * time.Second, false, keepaliveNone, 30 * time.Second},
+		{time.Minute, false, keepalivePing, 10 * time.Second},
+		{65 * time.Second, false, keepaliveNone, 5 * time.Second},
+		{68 * time.Second, true, keepaliveNone, time.Minute},
+		{128 * time.Second, false, keepalivePing, 10 * time.Second},
+		{138 * time.Second, false, keepaliveStall, 0},
+	}
+	for i, s := range steps {
+		now := start.Add(s.at)
+		if s.seen {
+			ka.seen(now)
+		}
+		action, wait := ka.check(now)
+		if action != s.action || wait != s.wait {
+			t.Errorf("%d. expecting action %d and wait %v, got %d and %v", i, s.action, s.wait, action, wait)
+		}
+	}
+	if !ka.isStalled() {
+		t.Error("expecting keepalive to be stalled")
+	}
+}
+
+func TestConnKeepalive(t *testing.T) {
+	client, server := net.Pipe()
+	c, s := NewConn(client), NewConn(server)
+	defer s.Close()
+	c.Keepalive(20*time.Millisecond, 20*time.Millisecond)
+	received := make(chan Message, 2)
+	go func() {
+		s.WriteMessage(Message{Command: "PING", Params: []string{"token"}})
+		for i := 0; i < 2; i++ {
+			m, err := s.ReadMessage()
+			if err != nil {
+				break
+			}
+			received <- m
+		}
+		close(received)
+	}()
+	if _, err := c.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	// The automatic PONG and then the keepalive PING should arrive, after
+	// which the server stays silent and the client should stall.
+	if _, err := c.ReadMessage(); err != ErrStalled {
+		t.Errorf("expecting %v, got %v", ErrStalled, err)
+	}
+	var got []string
+	for m := range received {
+		got = append(got, m.Command)
+	}
+	if len(got) != 2 || got[0] != "PONG" || got[1] != "PING" {
+		t.Errorf("expecting PONG then PING from client, got %v", got)
+	}
+}
@@ -0,0 +1,83 @@
+package ircmessage
+
+// Knock is a KNOCK message, asking the operators of an invite only channel
+// for an invitation.
+type Knock struct {
+	Prefix  string // The user knocking, when received.
+	Channel string
+	Text    string // An optional message for the operators.
+}
+
+// Message returns the KNOCK message for k.
+func (k Knock) Message() Message {
+	m := Message{Command: "KNOCK", Params: []string{k.Channel}}
+	if k.Text != "" {
+		m.Params = append(m.Params, k.Text)
+	}
+	return m
+}
+
+// ParseKnock parses a KNOCK message.
+func ParseKnock(m Message) (Knock, error) {
+	if !HasCommand(m, "KNOCK") || len(m.Params) == 0 || m.Params[0] == "" {
+		return Knock{}, ErrMessageMalformed
+	}
+	k := Knock{Prefix: m.Prefix, Channel: m.Params[0]}
+	if len(m.Params) > 1 {
+		k.Text = m.Params[1]
+	}
+	return k, nil
+}
+
+// KnockReplyKind identifies the numeric a KnockReply was parsed from.
+type KnockReplyKind int
+
+const (
+	KnockReceived      KnockReplyKind = iota // RPL_KNOCK (710): a user knocked on a channel we operate.
+	KnockDelivered                           // RPL_KNOCKDLVR (711): our knock was delivered.
+	KnockTooMany                             // ERR_TOOMANYKNOCK (712): we have knocked too often.
+	KnockChannelOpen                         // ERR_CHANOPEN (713): the channel can be joined.
+	KnockAlreadyJoined                       // ERR_KNOCKONCHAN (714): we are on the channel.
+)
+
+var knockReplyKinds = map[string]KnockReplyKind{
+	"710": KnockReceived,
+	"711": KnockDelivered,
+	"712": KnockTooMany,
+	"713": KnockChannelOpen,
+	"714": KnockAlreadyJoined,
+}
+
+// KnockReply is a numeric reply concerning a KNOCK.
+type KnockReply struct {
+	Kind    KnockReplyKind
+	Channel string
+	Knocker string // The prefix of the user knocking, for KnockReceived.
+	Text    string
+}
+
+// Failed reports whether r is an error reply, meaning the knock was not
+// sent.
+func (r KnockReply) Failed() bool { return r.Kind >= KnockTooMany }
+
+// ParseKnockReply parses an RPL_KNOCK, RPL_KNOCKDLVR, ERR_TOOMANYKNOCK,
+// ERR_CHANOPEN or ERR_KNOCKONCHAN message. It returns false for any other
+// message.
+func ParseKnockReply(m Message) (KnockReply, bool) {
+	kind, ok := knockReplyKinds[m.Command]
+	if !ok || len(m.Params) < 2 {
+		return KnockReply{}, false
+	}
+	r := KnockReply{Kind: kind, Channel: m.Params[1]}
+	rest := m.Params[2:]
+	if kind == KnockReceived {
+		if len(rest) == 0 {
+			return KnockReply{}, false
+		}
+		r.Knocker, rest = rest[0], rest[1:]
+	}
+	if len(rest) > 0 {
+		r.Text = rest[len(rest)-1]
+	}
+	return r, true
+}
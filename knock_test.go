@@ -0,0 +1,59 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKnock(t *testing.T) {
+	m := Knock{Channel: "#secret", Text: "let me in"}.Message()
+	if expected := []string{"#secret", "let me in"}; m.Command != "KNOCK" || !reflect.DeepEqual(m.Params, expected) {
+		t.Errorf("unexpected message %v", m)
+	}
+	k, err := ParseKnock(Message{Prefix: "nick!u@h", Command: "knock", Params: []string{"#secret"}})
+	if expected := (Knock{Prefix: "nick!u@h", Channel: "#secret"}); err != nil || k != expected {
+		t.Errorf("expecting %+v, got %+v %v", expected, k, err)
+	}
+	if _, err := ParseKnock(Message{Command: "KNOCK"}); err != ErrMessageMalformed {
+		t.Errorf("expecting %v, got %v", ErrMessageMalformed, err)
+	}
+}
+
+var knockReplyTests = []struct {
+	in       Message
+	expected KnockReply
+	ok       bool
+}{
+	{
+		Message{Command: "710", Params: []string{"me", "#secret", "nick!u@h", "has asked for an invite."}},
+		KnockReply{Kind: KnockReceived, Channel: "#secret", Knocker: "nick!u@h", Text: "has asked for an invite."},
+		true,
+	},
+	{
+		Message{Command: "711", Params: []string{"me", "#secret", "Your KNOCK has been delivered."}},
+		KnockReply{Kind: KnockDelivered, Channel: "#secret", Text: "Your KNOCK has been delivered."},
+		true,
+	},
+	{
+		Message{Command: "713", Params: []string{"me", "#open", "Channel is open."}},
+		KnockReply{Kind: KnockChannelOpen, Channel: "#open", Text: "Channel is open."},
+		true,
+	},
+	{Message{Command: "710", Params: []string{"me", "#secret"}}, KnockReply{}, false},
+	{Message{Command: "715", Params: []string{"me", "#secret", "x"}}, KnockReply{}, false},
+}
+
+func TestParseKnockReply(t *testing.T) {
+	for i, tt := range knockReplyTests {
+		r, ok := ParseKnockReply(tt.in)
+		if ok != tt.ok || r != tt.expected {
+			t.Errorf("%d. expecting %+v %v, got %+v %v", i, tt.expected, tt.ok, r, ok)
+		}
+	}
+	if r, _ := ParseKnockReply(knockReplyTests[2].in); !r.Failed() {
+		t.Error("expecting ERR_CHANOPEN to be a failure")
+	}
+	if r, _ := ParseKnockReply(knockReplyTests[1].in); r.Failed() {
+		t.Error("expecting RPL_KNOCKDLVR not to be a failure")
+	}
+}
@@ -0,0 +1,147 @@
+package ircmessage
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const lagTokenPrefix = "lag-"
+
+// LagStats summarises the latency samples held by a LagMonitor.
+type LagStats struct {
+	Samples int
+	Last    time.Duration
+	Min     time.Duration
+	Max     time.Duration
+	Mean    time.Duration
+}
+
+// LagMonitor measures connection latency by timestamping outgoing PINGs and
+// matching them with the PONGs the server sends in reply. It keeps a rolling
+// window of the most recent samples. On servers supporting labeled-response,
+// LabeledPing may be used instead to match replies by their label tag.
+//
+// A LagMonitor is safe for concurrent use.
+type LagMonitor struct {
+	mu      sync.Mutex
+	window  int
+	seq     uint64
+	pending map[string]time.Time
+	samples []time.Duration // Ring buffer of the last window samples.
+	next    int
+	clock   Clock
+}
+
+// NewLagMonitor returns a LagMonitor that keeps the last window samples.
+func NewLagMonitor(window int) *LagMonitor {
+	if window < 1 {
+		window = 1
+	}
+	return &LagMonitor{
+		window:  window,
+		pending: make(map[string]time.Time),
+		clock:   SystemClock,
+	}
+}
+
+// SetClock sets the Clock round trips are timed by.
+func (l *LagMonitor) SetClock(c Clock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clock = c
+}
+
+// Ping returns a PING message carrying a unique token and records the time
+// it was created. The message should be sent immediately.
+func (l *LagMonitor) Ping() Message {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	token := lagTokenPrefix + strconv.FormatUint(l.seq, 10)
+	l.pending[token] = l.clock.Now()
+	return Message{Command: "PING", Params: []string{token}}
+}
+
+// LabeledPing is like Ping but also carries its token in a label tag, so
+// the reply is matched by its label even if the server does not echo the
+// PING parameter. It requires the labeled-response capability.
+func (l *LagMonitor) LabeledPing() Message {
+	m := l.Ping()
+	m.Tags = map[string]string{"label": m.Params[0]}
+	return m
+}
+
+// Observe matches m against outstanding PINGs. If m is the PONG for one of
+// them, or any reply labeled with the token of a LabeledPing, the round trip
+// time is recorded and returned with true.
+func (l *LagMonitor) Observe(m Message) (time.Duration, bool) {
+	token, ok := m.Tags["label"]
+	if !ok || !strings.HasPrefix(token, lagTokenPrefix) {
+		if !HasCommand(m, "PONG") || len(m.Params) == 0 {
+			return 0, false
+		}
+		token = m.Params[len(m.Params)-1]
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sent, ok := l.pending[token]
+	if !ok {
+		return 0, false
+	}
+	delete(l.pending, token)
+	// Any PINGs sent before this one are never going to be answered.
+	for t, at := range l.pending {
+		if at.Before(sent) {
+			delete(l.pending, t)
+		}
+	}
+	d := l.clock.Now().Sub(sent)
+	if len(l.samples) < l.window {
+		l.samples = append(l.samples, d)
+	} else {
+		l.samples[l.next] = d
+	}
+	l.next = (l.next + 1) % l.window
+	return d, true
+}
+
+// Pending returns how long the oldest unanswered PING has been outstanding,
+// which is a lower bound on the current lag, or zero if there is none.
+func (l *LagMonitor) Pending() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var lag time.Duration
+	now := l.clock.Now()
+	for _, at := range l.pending {
+		if d := now.Sub(at); d > lag {
+			lag = d
+		}
+	}
+	return lag
+}
+
+// Stats returns statistics over the samples currently in the window.
+func (l *LagMonitor) Stats() LagStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	st := LagStats{Samples: len(l.samples)}
+	if st.Samples == 0 {
+		return st
+	}
+	st.Last = l.samples[(l.next+l.window-1)%l.window]
+	st.Min, st.Max = l.samples[0], l.samples[0]
+	var total time.Duration
+	for _, d := range l.samples {
+		total += d
+		if d < st.Min {
+			st.Min = d
+		}
+		if d > st.Max {
+			st.Max = d
+		}
+	}
+	st.Mean = total / time.Duration(st.Samples)
+	return st
+}
@@ -0,0 +1,69 @@
+package ircmessage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLagMonitor(t *testing.T) {
+	var now time.Time
+	l := NewLagMonitor(3)
+	l.SetClock(funcClock{now: func() time.Time { return now }})
+	pong := func(p Message) Message {
+		return Message{Prefix: "server", Command: "PONG", Params: []string{"server", p.Params[0]}}
+	}
+	for _, rtt := range []time.Duration{100, 300, 200, 400} {
+		p := l.Ping()
+		now = now.Add(rtt * time.Millisecond)
+		if l.Pending() != rtt*time.Millisecond {
+			t.Errorf("expecting pending %v, got %v", rtt*time.Millisecond, l.Pending())
+		}
+		d, ok := l.Observe(pong(p))
+		if !ok || d != rtt*time.Millisecond {
+			t.Errorf("expecting sample %v, got %v (%v)", rtt*time.Millisecond, d, ok)
+		}
+	}
+	expected := LagStats{
+		Samples: 3,
+		Last:    400 * time.Millisecond,
+		Min:     200 * time.Millisecond,
+		Max:     400 * time.Millisecond,
+		Mean:    300 * time.Millisecond,
+	}
+	if st := l.Stats(); st != expected {
+		t.Errorf("expecting stats %+v, got %+v", expected, st)
+	}
+	if _, ok := l.Observe(Message{Command: "PONG", Params: []string{"server", "unknown"}}); ok {
+		t.Error("expecting unknown PONG to be ignored")
+	}
+	// A lost PING is dropped once a later one is answered.
+	l.Ping()
+	p := l.Ping()
+	l.Observe(pong(p))
+	if l.Pending() != 0 {
+		t.Errorf("expecting no pending PINGs, got %v", l.Pending())
+	}
+}
+
+func TestLagMonitorLabeled(t *testing.T) {
+	var now time.Time
+	l := NewLagMonitor(3)
+	l.SetClock(funcClock{now: func() time.Time { return now }})
+	p := l.LabeledPing()
+	if p.Tags["label"] != p.Params[0] {
+		t.Fatalf("expecting label %q, got %q", p.Params[0], p.Tags["label"])
+	}
+	now = now.Add(250 * time.Millisecond)
+	// The reply is matched by its label alone.
+	ack := Message{Tags: map[string]string{"label": p.Tags["label"]}, Prefix: "server", Command: "ACK"}
+	d, ok := l.Observe(ack)
+	if !ok || d != 250*time.Millisecond {
+		t.Errorf("expecting sample %v, got %v (%v)", 250*time.Millisecond, d, ok)
+	}
+	if _, ok := l.Observe(ack); ok {
+		t.Error("expecting repeated label to be ignored")
+	}
+	if _, ok := l.Observe(Message{Tags: map[string]string{"label": "other"}, Command: "ACK"}); ok {
+		t.Error("expecting foreign label to be ignored")
+	}
+}
@@ -0,0 +1,68 @@
+package ircmessage
+
+// Limits holds the maximum message sizes enforced by a Scanner or Encoder.
+// A zero Tags or Body is replaced by the default of 512.
+type Limits struct {
+	// Tags is the maximum size of the tag section, including the
+	// leading @ and the space that ends it.
+	Tags int
+	// Body is the maximum size of the rest of the message, including
+	// the CRLF. Networks may advertise a larger value with LINELEN.
+	Body int
+	// MaxParams is the maximum number of parameters, or zero for no
+	// limit. RFC 2812 allows 15. A Scanner treats a message with more as
+	// malformed, and an Encoder folds the excess into the trailing
+	// parameter.
+	MaxParams int
+	// TagValue is the maximum length of a single unescaped tag value, or
+	// zero for no limit beyond that of the tag section.
+	TagValue int
+}
+
+// DefaultLimits are the limits used unless others are set, allowing 512
+// bytes of tags and a 512 byte body.
+var DefaultLimits = Limits{Tags: maxMessageSize, Body: maxMessageSize}
+
+// Role-based limits as per:
+// https://ircv3.net/specs/extensions/message-tags#size-limit
+var (
+	// ClientLimits apply to messages sent by clients, which may carry
+	// 4094 bytes of tag data. A server should scan messages from clients
+	// with these limits, and a client encode with them.
+	ClientLimits = Limits{Tags: 4096, Body: maxMessageSize}
+	// ServerLimits apply to messages sent by servers, which may add a
+	// further 4094 bytes of tag data to those sent by a client. A client
+	// should scan messages from its server with these limits, as should a
+	// server linked to other servers.
+	ServerLimits = Limits{Tags: 8191, Body: maxMessageSize}
+)
+
+// SetLimits sets the limits the Scanner enforces on incoming messages. It
+// must be called before the first call to Scan.
+func (s *Scanner) SetLimits(l Limits) {
+	s.limits = l.orDefault()
+}
+
+// SetLimits sets the limits the Encoder enforces on outgoing messages.
+func (e *Encoder) SetLimits(l Limits) {
+	e.limits = l.orDefault()
+}
+
+func (l Limits) orDefault() Limits {
+	if l.Tags == 0 {
+		l.Tags = maxMessageSize
+	}
+	if l.Body == 0 {
+		l.Body = maxMessageSize
+	}
+	return l
+}
+
+// LineLen returns the maximum message length advertised by the LINELEN
+// token, or 512 if there is none.
+func (is *ISupport) LineLen() int {
+	if n := is.getInt("LINELEN", 0); n > maxMessageSize {
+		return n
+	}
+	return maxMessageSize
+}
@@ -0,0 +1,126 @@
+package ircmessage
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var limitsTests = []struct {
+	limits Limits
+	in     string
+	err    error
+}{
+	{DefaultLimits, "PRIVMSG #c : " + strings.Repeat("a", 497), nil},
+	{DefaultLimits, "PRIVMSG #c : " + strings.Repeat("a", 498), ErrLineTooLong},
+	{Limits{Tags: 512, Body: 1024}, "PRIVMSG #c : " + strings.Repeat("a", 1009), nil},
+	{Limits{Tags: 512, Body: 1024}, "PRIVMSG #c : " + strings.Repeat("a", 1010), ErrLineTooLong},
+	{Limits{Tags: 10, Body: 512}, "@a=123456 PING", nil},
+	{Limits{Tags: 10, Body: 512}, "@a=1234567 PING", ErrLineTooLong},
+	{ClientLimits, "@+a=" + strings.Repeat("b", 4091) + " PING", nil},
+	{ClientLimits, "@+a=" + strings.Repeat("b", 4092) + " PING", ErrLineTooLong},
+	{ServerLimits, "@+a=" + strings.Repeat("b", 8186) + " PING", nil},
+	{ServerLimits, "@+a=" + strings.Repeat("b", 8187) + " PING", ErrLineTooLong},
+	{Limits{Tags: 512, Body: 512, MaxParams: 15}, "FOO 1 2 3 4 5 6 7 8 9 10 11 12 13 14 :15 x", nil},
+	{Limits{Tags: 512, Body: 512, MaxParams: 15}, "FOO 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16", ErrTooManyParams},
+	{Limits{Tags: 512, Body: 512, TagValue: 3}, "@a=a\\sb;b PING", nil},
+	{Limits{Tags: 512, Body: 512, TagValue: 3}, "@a=abcd PING", ErrTagTooLong},
+}
+
+func TestScannerLimits(t *testing.T) {
+	for i, tt := range limitsTests {
+		s := NewScanner(strings.NewReader(tt.in + "\r\n"))
+		s.SetLimits(tt.limits)
+		s.Scan()
+		if !errors.Is(s.Err(), tt.err) {
+			t.Errorf("%d. expecting error %v, got %v", i, tt.err, s.Err())
+		}
+	}
+}
+
+func TestEncoderLimits(t *testing.T) {
+	for i, tt := range limitsTests {
+		s := NewScanner(strings.NewReader(tt.in + "\r\n"))
+		s.SetLimits(Limits{Tags: 8192, Body: 4096})
+		if !s.Scan() {
+			t.Fatalf("%d. %v", i, s.Err())
+		}
+		expected := tt.err
+		if tt.limits.MaxParams > 0 {
+			// Excess parameters are folded rather than rejected.
+			expected = nil
+		}
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		e.SetLimits(tt.limits)
+		if err := e.Encode(s.Message()); err != expected {
+			t.Errorf("%d. expecting error %v, got %v", i, expected, err)
+		}
+	}
+}
+
+var foldParamsTests = []struct {
+	params   []string
+	expected string
+	err      error
+}{
+	{[]string{"1", "2", "3"}, "FOO 1 2 3\r\n", nil},
+	{[]string{"1", "2", "3", "4"}, "FOO 1 2 :3 4\r\n", nil},
+	{[]string{"1", "2", "3", "4", "five six"}, "FOO 1 2 :3 4 five six\r\n", nil},
+	{[]string{"1", "2", "3", "4", ":5"}, "FOO 1 2 :3 4 :5\r\n", nil},
+	{[]string{"1", "2", "3", ""}, "FOO 1 2 :3 \r\n", nil},
+	{[]string{"1", "2", "3 4", "5"}, "FOO 1 2 :3 4 5\r\n", nil},
+	{[]string{"a", "b", "c d", "e"}, "FOO a b :c d e\r\n", nil},
+	{[]string{"1", "2", "3", "", "5"}, "FOO 1 2 :3  5\r\n", nil},
+	{[]string{"1", "2 3", "4", "5"}, "", ErrMessageMalformed},
+}
+
+func TestEncoderFoldParams(t *testing.T) {
+	for i, tt := range foldParamsTests {
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		e.SetLimits(Limits{Tags: 512, Body: 512, MaxParams: 3})
+		if err := e.Encode(Message{Command: "FOO", Params: tt.params}); err != tt.err {
+			t.Errorf("%d. expecting error %v, got %v", i, tt.err, err)
+		}
+		if buf.String() != tt.expected {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, buf.String())
+		}
+	}
+}
+
+func TestISupportLineLen(t *testing.T) {
+	var is ISupport
+	if n := is.LineLen(); n != 512 {
+		t.Errorf("expecting 512, got %d", n)
+	}
+	is.Update(Message{Command: "005", Params: []string{"nick", "LINELEN=2048", "are supported"}})
+	if n := is.LineLen(); n != 2048 {
+		t.Errorf("expecting 2048, got %d", n)
+	}
+}
+
+var truncatedTests = []struct {
+	limits    Limits
+	in        string
+	truncated bool
+}{
+	{DefaultLimits, "PRIVMSG #c :" + strings.Repeat("a", 497), false},
+	{DefaultLimits, "PRIVMSG #c :" + strings.Repeat("a", 498), true},
+	{DefaultLimits, "@a=b PRIVMSG #c :" + strings.Repeat("a", 498), true},
+	{Limits{Tags: 512, Body: 1024}, "PRIVMSG #c :" + strings.Repeat("a", 498), false},
+}
+
+func TestScannerTruncated(t *testing.T) {
+	for i, tt := range truncatedTests {
+		s := NewScanner(strings.NewReader(tt.in + "\r\n"))
+		s.SetLimits(tt.limits)
+		if !s.Scan() {
+			t.Fatalf("%d. %v", i, s.Err())
+		}
+		if s.Message().Truncated != tt.truncated {
+			t.Errorf("%d. expecting Truncated %t", i, tt.truncated)
+		}
+	}
+}
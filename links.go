@@ -0,0 +1,151 @@
+package ircmessage
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ServerNode is a server in the tree of a network.
+type ServerNode struct {
+	Name string
+	// Info is the server's description, from LINKS.
+	Info string
+	// Hops is the distance from the server that replied.
+	Hops int
+	// Users is the number of users on the server, from MAP when it is
+	// shown, and otherwise 0.
+	Users    int
+	Children []*ServerNode
+}
+
+// TopologyCollector builds the tree of a network from the replies to LINKS,
+// RPL_LINKS (364) and RPL_ENDOFLINKS (365), or to MAP, whose output differs
+// between servers but is drawn as an indented tree with RPL_MAP (006 or
+// 015) and ended with RPL_MAPEND (007 or 017). The zero value is ready to
+// use, and is not safe for concurrent use.
+type TopologyCollector struct {
+	links []linksEntry
+	stack []mapLevel // The path to the last MAP server, root first.
+	roots []*ServerNode
+}
+
+type mapLevel struct {
+	node  *ServerNode
+	depth int // The indentation of the server's line.
+}
+
+type linksEntry struct {
+	node   *ServerNode
+	uplink string
+}
+
+// Add feeds a message to the collector. It reports whether the message was
+// a LINKS or MAP reply, and returns the servers at the roots of the tree
+// once the replies have ended. There is usually a single root, but a LINKS
+// request with a mask may leave servers whose uplink was not listed, which
+// are returned as roots of their own.
+func (c *TopologyCollector) Add(m Message) ([]*ServerNode, bool) {
+	switch m.Command {
+	case "364": // RPL_LINKS
+		if len(m.Params) < 4 {
+			return nil, false
+		}
+		n := &ServerNode{Name: m.Params[1]}
+		hops, info, _ := strings.Cut(m.Params[3], " ")
+		n.Hops, _ = strconv.Atoi(hops)
+		n.Info = info
+		c.links = append(c.links, linksEntry{node: n, uplink: m.Params[2]})
+	case "365": // RPL_ENDOFLINKS
+		roots := linkServers(c.links)
+		c.links = nil
+		return roots, true
+	case "006", "015": // RPL_MAP
+		if len(m.Params) < 2 {
+			return nil, false
+		}
+		depth, n, ok := parseMapLine(m.Params[len(m.Params)-1])
+		if !ok {
+			return nil, true
+		}
+		c.addMapServer(depth, n)
+	case "007", "017": // RPL_MAPEND
+		roots := c.roots
+		c.roots, c.stack = nil, nil
+		return roots, true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// linkServers arranges the servers of a LINKS reply into trees.
+func linkServers(links []linksEntry) []*ServerNode {
+	byName := make(map[string]*ServerNode, len(links))
+	for _, l := range links {
+		byName[strings.ToLower(l.node.Name)] = l.node
+	}
+	var roots []*ServerNode
+	for _, l := range links {
+		parent, ok := byName[strings.ToLower(l.uplink)]
+		if !ok || parent == l.node {
+			roots = append(roots, l.node)
+			continue
+		}
+		parent.Children = append(parent.Children, l.node)
+	}
+	return roots
+}
+
+// addMapServer adds n, drawn at the given indentation, beneath the last
+// server drawn with less.
+func (c *TopologyCollector) addMapServer(depth int, n *ServerNode) {
+	for len(c.stack) > 0 && c.stack[len(c.stack)-1].depth >= depth {
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+	if len(c.stack) == 0 {
+		c.roots = append(c.roots, n)
+	} else {
+		parent := c.stack[len(c.stack)-1].node
+		parent.Children = append(parent.Children, n)
+	}
+	n.Hops = len(c.stack)
+	c.stack = append(c.stack, mapLevel{node: n, depth: depth})
+}
+
+// mapTreeChars are the characters servers use to draw the tree in MAP
+// output.
+const mapTreeChars = " |`-+└├─│"
+
+// parseMapLine parses a line of MAP output, such as
+// "  `- leaf.example.org[00B] ----- | Users: 10 ( 50.0%)", returning its
+// indentation and the server it names.
+func parseMapLine(text string) (depth int, n *ServerNode, ok bool) {
+	rest := strings.TrimLeft(text, mapTreeChars)
+	depth = len([]rune(text[:len(text)-len(rest)]))
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return 0, nil, false
+	}
+	// The server id may follow the name, as in "name[00A]" or
+	// "name (00A)".
+	name := fields[0]
+	if i := strings.IndexAny(name, "[("); i > 0 {
+		name = name[:i]
+	}
+	fields = fields[1:]
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "(") || strings.HasPrefix(fields[0], "[")) {
+		fields = fields[1:]
+	}
+	n = &ServerNode{Name: name}
+	for i, f := range fields {
+		if f == "Users:" && i+1 < len(fields) {
+			n.Users, _ = strconv.Atoi(fields[i+1])
+			break
+		}
+		if users, err := strconv.Atoi(f); err == nil {
+			n.Users = users
+			break
+		}
+	}
+	return depth, n, true
+}
@@ -0,0 +1,88 @@
+package ircmessage
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// describeTree returns the servers of a tree with their hops, users and
+// children, for comparison.
+func describeTree(nodes []*ServerNode) string {
+	var parts []string
+	for _, n := range nodes {
+		s := fmt.Sprintf("%s/%d/%d", n.Name, n.Hops, n.Users)
+		if len(n.Children) > 0 {
+			s += "(" + describeTree(n.Children) + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+var topologyTests = []struct {
+	in       string
+	expected string
+}{
+	{
+		":hub.example.org 364 me leaf1.example.org hub.example.org :1 First leaf\r\n" +
+			":hub.example.org 364 me leaf2.example.org hub.example.org :1 Second leaf\r\n" +
+			":hub.example.org 364 me deep.example.org leaf2.example.org :2 Deep leaf\r\n" +
+			":hub.example.org 364 me hub.example.org hub.example.org :0 The hub\r\n" +
+			":hub.example.org 365 me * :End of /LINKS list.\r\n",
+		"hub.example.org/0/0(leaf1.example.org/1/0 leaf2.example.org/1/0(deep.example.org/2/0))",
+	},
+	{
+		// Solanum.
+		":hub.example.org 015 me :hub.example.org[00A] ---------------- | Users:    10 ( 50.0%)\r\n" +
+			":hub.example.org 015 me :|-leaf1.example.org[00B] --------- | Users:     4 ( 20.0%)\r\n" +
+			":hub.example.org 015 me :| `-deep.example.org[00D] ------- | Users:     1 (  5.0%)\r\n" +
+			":hub.example.org 015 me :`-leaf2.example.org[00C] --------- | Users:     5 ( 25.0%)\r\n" +
+			":hub.example.org 017 me :End of /MAP\r\n",
+		"hub.example.org/0/10(leaf1.example.org/1/4(deep.example.org/2/1) leaf2.example.org/1/5)",
+	},
+	{
+		// UnrealIRCd.
+		":hub.example.org 006 me :hub.example.org (00A)        10 [50%]\r\n" +
+			":hub.example.org 006 me :|-leaf1.example.org (00B)     4 [20%]\r\n" +
+			":hub.example.org 006 me :| `-deep.example.org (00D)    1 [5%]\r\n" +
+			":hub.example.org 006 me :`-leaf2.example.org (00C)     5 [25%]\r\n" +
+			":hub.example.org 007 me :End of /MAP\r\n",
+		"hub.example.org/0/10(leaf1.example.org/1/4(deep.example.org/2/1) leaf2.example.org/1/5)",
+	},
+	{
+		// InspIRCd.
+		":hub.example.org 006 me :hub.example.org (00A) 10 [50%]\r\n" +
+			":hub.example.org 006 me :├─leaf1.example.org (00B) 4 [20%]\r\n" +
+			":hub.example.org 006 me :│ └─deep.example.org (00D) 1 [5%]\r\n" +
+			":hub.example.org 006 me :└─leaf2.example.org (00C) 5 [25%]\r\n" +
+			":hub.example.org 007 me :End of /MAP\r\n",
+		"hub.example.org/0/10(leaf1.example.org/1/4(deep.example.org/2/1) leaf2.example.org/1/5)",
+	},
+}
+
+func TestTopologyCollector(t *testing.T) {
+	for i, tt := range topologyTests {
+		var (
+			c     TopologyCollector
+			roots []*ServerNode
+		)
+		s := NewScanner(strings.NewReader(tt.in))
+		for s.Scan() {
+			if r, ok := c.Add(s.Message()); !ok {
+				t.Errorf("%d. expecting %v to be consumed", i, s.Message())
+			} else if r != nil {
+				roots = r
+			}
+		}
+		if got := describeTree(roots); got != tt.expected {
+			t.Errorf("%d. expecting %s, got %s", i, tt.expected, got)
+		}
+	}
+	var c TopologyCollector
+	c.Add(Message{Command: "364", Params: []string{"me", "leaf.example.org", "hub.example.org", "1 Leaf"}})
+	roots, _ := c.Add(Message{Command: "365", Params: []string{"me", "leaf*", "End of /LINKS list."}})
+	if len(roots) != 1 || roots[0].Info != "Leaf" || roots[0].Hops != 1 {
+		t.Errorf("expecting an orphaned leaf as a root, got %s", describeTree(roots))
+	}
+}
@@ -0,0 +1,337 @@
+package ircmessage
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// LogFormat identifies the plain text log format read by a LogReader.
+type LogFormat int
+
+const (
+	// LogZNC is the format of ZNC's log module, in which each line
+	// begins with a [15:04:05] timestamp and each file holds one day.
+	LogZNC LogFormat = iota
+	// LogWeeChat is the tab separated format of WeeChat's logger
+	// plugin, with a full date on every line.
+	LogWeeChat
+	// LogIrssi is irssi's default format, in which each line begins with
+	// a 15:04 timestamp and the date is given by "Log opened" and "Day
+	// changed" lines.
+	LogIrssi
+)
+
+// LogReader reads a plain text channel log written by a client or bouncer
+// back into messages, so that archives can be processed like live traffic.
+// Each message has a server-time tag holding the time it was logged, but
+// no Raw field.
+//
+// Chat, actions, notices, joins, parts, quits, kicks, nick changes, topic
+// changes and mode changes are recognised. Actions are returned as CTCP
+// ACTION messages. Other lines are skipped.
+type LogReader struct {
+	format  LogFormat
+	channel string
+	date    time.Time // Midnight on the day being read.
+	sc      *bufio.Scanner
+	msg     Message
+}
+
+// NewLogReader returns a LogReader that reads a log in the given format
+// from r. Since logs do not record it on every line, channel is used as
+// the target of messages. Lines holding only a time of day are taken to be
+// on the day of date, which irssi logs update as they go, and every
+// timestamp is read in the location of date.
+func NewLogReader(r io.Reader, format LogFormat, channel string, date time.Time) *LogReader {
+	y, m, d := date.Date()
+	return &LogReader{
+		format:  format,
+		channel: channel,
+		date:    time.Date(y, m, d, 0, 0, 0, 0, date.Location()),
+		sc:      bufio.NewScanner(r),
+	}
+}
+
+// Scan advances the LogReader to the next message, which is then available
+// through the Message method. It returns false at the end of the input or
+// on an error reading it.
+func (lr *LogReader) Scan() bool {
+	for lr.sc.Scan() {
+		line := strings.TrimSuffix(lr.sc.Text(), "\r")
+		var ok bool
+		switch lr.format {
+		case LogZNC:
+			lr.msg, ok = lr.parseZNC(line)
+		case LogWeeChat:
+			lr.msg, ok = lr.parseWeeChat(line)
+		case LogIrssi:
+			lr.msg, ok = lr.parseIrssi(line)
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Message returns the most recent Message read by a call to Scan.
+func (lr *LogReader) Message() Message { return lr.msg }
+
+// Err returns the first error encountered reading the log.
+func (lr *LogReader) Err() error { return lr.sc.Err() }
+
+func (lr *LogReader) parseZNC(line string) (Message, bool) {
+	if len(line) < 11 || line[0] != '[' || line[9] != ']' || line[10] != ' ' {
+		return Message{}, false
+	}
+	t, ok := lr.clock(line[1:9], "15:04:05")
+	if !ok {
+		return Message{}, false
+	}
+	text := line[11:]
+	switch {
+	case strings.HasPrefix(text, "*** "):
+		return lr.zncEvent(t, text[4:])
+	case strings.HasPrefix(text, "* "):
+		nick, action, _ := strings.Cut(text[2:], " ")
+		return lr.action(t, nick, action), true
+	case strings.HasPrefix(text, "<"):
+		if nick, text, ok := strings.Cut(text[1:], "> "); ok {
+			return lr.message(t, nick, "PRIVMSG", lr.channel, text), true
+		}
+	case strings.HasPrefix(text, "-"):
+		if nick, text, ok := strings.Cut(text[1:], "- "); ok {
+			return lr.message(t, nick, "NOTICE", lr.channel, text), true
+		}
+	}
+	return Message{}, false
+}
+
+func (lr *LogReader) zncEvent(t time.Time, ev string) (Message, bool) {
+	if rest, ok := strings.CutPrefix(ev, "Joins: "); ok {
+		prefix, _ := splitUser(rest, '(', ')')
+		return lr.message(t, prefix, "JOIN", lr.channel), true
+	}
+	if rest, ok := strings.CutPrefix(ev, "Parts: "); ok {
+		prefix, reason := splitUser(rest, '(', ')')
+		return withReason(lr.message(t, prefix, "PART", lr.channel), enclosed(reason, '(', ')')), true
+	}
+	if rest, ok := strings.CutPrefix(ev, "Quits: "); ok {
+		prefix, reason := splitUser(rest, '(', ')')
+		return withReason(lr.message(t, prefix, "QUIT"), enclosed(reason, '(', ')')), true
+	}
+	nick, rest, _ := strings.Cut(ev, " ")
+	if rest, ok := strings.CutPrefix(rest, "was kicked by "); ok {
+		op, reason, _ := strings.Cut(rest, " ")
+		return lr.message(t, op, "KICK", lr.channel, nick, enclosed(reason, '(', ')')), true
+	}
+	if to, ok := strings.CutPrefix(rest, "is now known as "); ok {
+		return lr.message(t, nick, "NICK", to), true
+	}
+	if topic, ok := strings.CutPrefix(rest, "changes topic to "); ok {
+		return lr.message(t, nick, "TOPIC", lr.channel, enclosed(topic, '\'', '\'')), true
+	}
+	if modes, ok := strings.CutPrefix(rest, "sets mode: "); ok {
+		return lr.message(t, nick, "MODE", append([]string{lr.channel}, strings.Fields(modes)...)...), true
+	}
+	return Message{}, false
+}
+
+func (lr *LogReader) parseWeeChat(line string) (Message, bool) {
+	fields := strings.SplitN(line, "\t", 3)
+	if len(fields) != 3 {
+		return Message{}, false
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", fields[0], lr.date.Location())
+	if err != nil {
+		return Message{}, false
+	}
+	text := fields[2]
+	switch fields[1] {
+	case "-->", "<--":
+		prefix, rest := splitUser(text, '(', ')')
+		if m, ok := lr.userEvent(t, prefix, rest, '(', ')'); ok {
+			return m, true
+		}
+		if rest, ok := strings.CutPrefix(rest, "has kicked "); ok {
+			nick, reason, _ := strings.Cut(rest, " ")
+			return lr.message(t, prefix, "KICK", lr.channel, nick, enclosed(reason, '(', ')')), true
+		}
+	case "--":
+		return lr.weeChatEvent(t, text)
+	case " *":
+		nick, action, _ := strings.Cut(text, " ")
+		return lr.action(t, nick, action), true
+	case "", "=!=":
+	default:
+		return lr.message(t, strings.TrimLeft(fields[1], "~&@%+"), "PRIVMSG", lr.channel, text), true
+	}
+	return Message{}, false
+}
+
+func (lr *LogReader) weeChatEvent(t time.Time, ev string) (Message, bool) {
+	if rest, ok := strings.CutPrefix(ev, "Notice("); ok {
+		if nick, text, ok := strings.Cut(rest, "): "); ok {
+			return lr.message(t, nick, "NOTICE", lr.channel, text), true
+		}
+		return Message{}, false
+	}
+	if rest, ok := strings.CutPrefix(ev, "Mode "); ok {
+		return lr.modeEvent(t, rest)
+	}
+	nick, rest, _ := strings.Cut(ev, " ")
+	if to, ok := strings.CutPrefix(rest, "is now known as "); ok {
+		return lr.message(t, nick, "NICK", to), true
+	}
+	if rest, ok := strings.CutPrefix(rest, "has changed topic for "); ok {
+		if i := strings.LastIndex(rest, ` to "`); i >= 0 {
+			return lr.message(t, nick, "TOPIC", lr.channel, enclosed(rest[i+4:], '"', '"')), true
+		}
+	}
+	return Message{}, false
+}
+
+func (lr *LogReader) parseIrssi(line string) (Message, bool) {
+	if rest, ok := strings.CutPrefix(line, "--- Log opened "); ok {
+		lr.setDate(rest, "Mon Jan _2 15:04:05 2006")
+		return Message{}, false
+	}
+	if rest, ok := strings.CutPrefix(line, "--- Day changed "); ok {
+		lr.setDate(rest, "Mon Jan _2 2006")
+		return Message{}, false
+	}
+	if len(line) < 6 || line[5] != ' ' {
+		return Message{}, false
+	}
+	t, ok := lr.clock(line[:5], "15:04")
+	if !ok {
+		return Message{}, false
+	}
+	text := line[6:]
+	switch {
+	case strings.HasPrefix(text, "-!- "):
+		return lr.irssiEvent(t, text[4:])
+	case strings.HasPrefix(text, " * "):
+		nick, action, _ := strings.Cut(text[3:], " ")
+		return lr.action(t, nick, action), true
+	case strings.HasPrefix(text, "<"):
+		if nick, text, ok := strings.Cut(text[1:], "> "); ok {
+			return lr.message(t, strings.TrimLeft(nick, " ~&@%+"), "PRIVMSG", lr.channel, text), true
+		}
+	}
+	return Message{}, false
+}
+
+func (lr *LogReader) irssiEvent(t time.Time, ev string) (Message, bool) {
+	if rest, ok := strings.CutPrefix(ev, "mode/"); ok {
+		return lr.modeEvent(t, rest)
+	}
+	prefix, rest := splitUser(ev, '[', ']')
+	if m, ok := lr.userEvent(t, prefix, rest, '[', ']'); ok {
+		return m, true
+	}
+	if rest, ok := strings.CutPrefix(rest, "was kicked from "); ok {
+		_, rest, _ = strings.Cut(rest, " by ")
+		op, reason, _ := strings.Cut(rest, " ")
+		return lr.message(t, op, "KICK", lr.channel, prefix, enclosed(reason, '[', ']')), true
+	}
+	if to, ok := strings.CutPrefix(rest, "is now known as "); ok {
+		return lr.message(t, prefix, "NICK", to), true
+	}
+	if rest, ok := strings.CutPrefix(rest, "changed the topic of "); ok {
+		if _, topic, ok := strings.Cut(rest, " to: "); ok {
+			return lr.message(t, prefix, "TOPIC", lr.channel, topic), true
+		}
+	}
+	return Message{}, false
+}
+
+// userEvent parses the joins, parts and quits logged by WeeChat and irssi,
+// in which rest follows the user's prefix and reasons are enclosed by the
+// given brackets.
+func (lr *LogReader) userEvent(t time.Time, prefix, rest string, open, close byte) (Message, bool) {
+	if channel, ok := strings.CutPrefix(rest, "has joined "); ok {
+		return lr.message(t, prefix, "JOIN", channel), true
+	}
+	if rest, ok := strings.CutPrefix(rest, "has left "); ok {
+		channel, reason, _ := strings.Cut(rest, " ")
+		return withReason(lr.message(t, prefix, "PART", channel), enclosed(reason, open, close)), true
+	}
+	if reason, ok := strings.CutPrefix(rest, "has quit"); ok {
+		reason = strings.TrimPrefix(reason, " ")
+		return withReason(lr.message(t, prefix, "QUIT"), enclosed(reason, open, close)), true
+	}
+	return Message{}, false
+}
+
+// modeEvent parses a mode change logged by WeeChat or irssi in the form
+// "#chan [+o nick] by op".
+func (lr *LogReader) modeEvent(t time.Time, ev string) (Message, bool) {
+	channel, rest, _ := strings.Cut(ev, " ")
+	modes, op, ok := strings.Cut(rest, "] by ")
+	if !ok || !strings.HasPrefix(modes, "[") {
+		return Message{}, false
+	}
+	return lr.message(t, op, "MODE", append([]string{channel}, strings.Fields(modes[1:])...)...), true
+}
+
+// clock returns the time of day s, in the given layout, on the current date.
+func (lr *LogReader) clock(s, layout string) (time.Time, bool) {
+	c, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	y, m, d := lr.date.Date()
+	return time.Date(y, m, d, c.Hour(), c.Minute(), c.Second(), 0, lr.date.Location()), true
+}
+
+func (lr *LogReader) setDate(s, layout string) {
+	if t, err := time.ParseInLocation(layout, s, lr.date.Location()); err == nil {
+		y, m, d := t.Date()
+		lr.date = time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+func (lr *LogReader) message(t time.Time, prefix, command string, params ...string) Message {
+	return Message{
+		Tags:    map[string]string{"time": t.UTC().Format(serverTimeLayout)},
+		Prefix:  prefix,
+		Command: command,
+		Params:  params,
+	}
+}
+
+func (lr *LogReader) action(t time.Time, nick, action string) Message {
+	return lr.message(t, nick, "PRIVMSG", lr.channel, "\x01ACTION "+action+"\x01")
+}
+
+// withReason appends reason to the params of m unless it is empty.
+func withReason(m Message, reason string) Message {
+	if reason != "" {
+		m.Params = append(m.Params, reason)
+	}
+	return m
+}
+
+// splitUser splits s, which begins with a nickname that may be followed by
+// a user@host enclosed in the given brackets, into a prefix and the text
+// following it.
+func splitUser(s string, open, close byte) (prefix, rest string) {
+	nick, rest, _ := strings.Cut(s, " ")
+	if rest != "" && rest[0] == open {
+		if i := strings.IndexByte(rest, close); i > 0 {
+			return nick + "!" + rest[1:i], strings.TrimPrefix(rest[i+1:], " ")
+		}
+	}
+	return nick, rest
+}
+
+// enclosed returns s without the given brackets around it, if present.
+func enclosed(s string, open, close byte) string {
+	if len(s) >= 2 && s[0] == open && s[len(s)-1] == close {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,131 @@
+package ircmessage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var logReaderTests = []struct {
+	format   LogFormat
+	in       string
+	expected []string // As encoded, with the time tag first.
+}{
+	{
+		LogZNC,
+		"[00:00:01] *** Joins: nick (~u@host)\n" +
+			"[00:00:02] <nick> hello there\n" +
+			"[00:00:03] * nick waves\n" +
+			"[00:00:04] -server- notice text\n" +
+			"[00:00:05] *** op sets mode: +o nick\n" +
+			"[00:00:06] *** op changes topic to 'new topic'\n" +
+			"[00:00:07] *** nick is now known as nick2\n" +
+			"[00:00:08] *** nick2 was kicked by op (go away)\n" +
+			"[00:00:09] *** Parts: other (~o@host) (bye (for now))\n" +
+			"[00:00:10] *** Quits: third (~t@host) (Quit: leaving)\n" +
+			"not a log line\r\n",
+		[]string{
+			"@time=2017-09-26T00:00:01.000Z :nick!~u@host JOIN #c",
+			"@time=2017-09-26T00:00:02.000Z :nick PRIVMSG #c :hello there",
+			"@time=2017-09-26T00:00:03.000Z :nick PRIVMSG #c :\x01ACTION waves\x01",
+			"@time=2017-09-26T00:00:04.000Z :server NOTICE #c :notice text",
+			"@time=2017-09-26T00:00:05.000Z :op MODE #c +o nick",
+			"@time=2017-09-26T00:00:06.000Z :op TOPIC #c :new topic",
+			"@time=2017-09-26T00:00:07.000Z :nick NICK nick2",
+			"@time=2017-09-26T00:00:08.000Z :op KICK #c nick2 :go away",
+			"@time=2017-09-26T00:00:09.000Z :other!~o@host PART #c :bye (for now)",
+			"@time=2017-09-26T00:00:10.000Z :third!~t@host QUIT :Quit: leaving",
+		},
+	},
+	{
+		LogWeeChat,
+		"2017-09-27 10:00:01\t-->\tnick (~u@host) has joined #c\n" +
+			"2017-09-27 10:00:02\t@nick\thello there\n" +
+			"2017-09-27 10:00:03\t *\tnick waves\n" +
+			"2017-09-27 10:00:04\t--\tNotice(server): notice text\n" +
+			"2017-09-27 10:00:05\t--\tMode #c [+o nick] by op\n" +
+			"2017-09-27 10:00:06\t--\top has changed topic for #c from \"old\" to \"new topic\"\n" +
+			"2017-09-27 10:00:07\t--\tnick is now known as nick2\n" +
+			"2017-09-27 10:00:08\t<--\top has kicked nick2 (go away)\n" +
+			"2017-09-27 10:00:09\t<--\tother (~o@host) has left #c (bye)\n" +
+			"2017-09-27 10:00:10\t<--\tthird (~t@host) has quit (Quit: leaving)\n" +
+			"2017-09-27 10:00:11\t=!=\tsome error\n",
+		[]string{
+			"@time=2017-09-27T10:00:01.000Z :nick!~u@host JOIN #c",
+			"@time=2017-09-27T10:00:02.000Z :nick PRIVMSG #c :hello there",
+			"@time=2017-09-27T10:00:03.000Z :nick PRIVMSG #c :\x01ACTION waves\x01",
+			"@time=2017-09-27T10:00:04.000Z :server NOTICE #c :notice text",
+			"@time=2017-09-27T10:00:05.000Z :op MODE #c +o nick",
+			"@time=2017-09-27T10:00:06.000Z :op TOPIC #c :new topic",
+			"@time=2017-09-27T10:00:07.000Z :nick NICK nick2",
+			"@time=2017-09-27T10:00:08.000Z :op KICK #c nick2 :go away",
+			"@time=2017-09-27T10:00:09.000Z :other!~o@host PART #c bye",
+			"@time=2017-09-27T10:00:10.000Z :third!~t@host QUIT :Quit: leaving",
+		},
+	},
+	{
+		LogIrssi,
+		"--- Log opened Tue Sep 26 23:59:00 2017\n" +
+			"23:59 -!- nick [~u@host] has joined #c\n" +
+			"--- Day changed Wed Sep 27 2017\n" +
+			"00:01 <@nick> hello there\n" +
+			"00:02 < other> hi\n" +
+			"00:03  * nick waves\n" +
+			"00:04 -!- mode/#c [+o other] by nick\n" +
+			"00:05 -!- nick changed the topic of #c to: new topic\n" +
+			"00:06 -!- nick is now known as nick2\n" +
+			"00:07 -!- other was kicked from #c by nick2 [go away]\n" +
+			"00:08 -!- third [~t@host] has left #c []\n" +
+			"00:09 -!- fourth [~f@host] has quit [Quit: leaving]\n" +
+			"--- Log closed Wed Sep 27 00:10:00 2017\n",
+		[]string{
+			"@time=2017-09-26T23:59:00.000Z :nick!~u@host JOIN #c",
+			"@time=2017-09-27T00:01:00.000Z :nick PRIVMSG #c :hello there",
+			"@time=2017-09-27T00:02:00.000Z :other PRIVMSG #c hi",
+			"@time=2017-09-27T00:03:00.000Z :nick PRIVMSG #c :\x01ACTION waves\x01",
+			"@time=2017-09-27T00:04:00.000Z :nick MODE #c +o other",
+			"@time=2017-09-27T00:05:00.000Z :nick TOPIC #c :new topic",
+			"@time=2017-09-27T00:06:00.000Z :nick NICK nick2",
+			"@time=2017-09-27T00:07:00.000Z :nick2 KICK #c other :go away",
+			"@time=2017-09-27T00:08:00.000Z :third!~t@host PART #c",
+			"@time=2017-09-27T00:09:00.000Z :fourth!~f@host QUIT :Quit: leaving",
+		},
+	},
+}
+
+func TestLogReader(t *testing.T) {
+	date := time.Date(2017, 9, 26, 12, 0, 0, 0, time.UTC)
+	for i, tt := range logReaderTests {
+		lr := NewLogReader(strings.NewReader(tt.in), tt.format, "#c", date)
+		var got []string
+		for lr.Scan() {
+			m := lr.Message()
+			if m.Raw != "" {
+				t.Errorf("%d. expecting no Raw field, got %q", i, m.Raw)
+			}
+			b, err := AppendMessage(nil, m)
+			if err != nil {
+				t.Fatalf("%d. %v: %v", i, err, m)
+			}
+			got = append(got, strings.TrimSuffix(string(b), "\r\n"))
+		}
+		if lr.Err() != nil {
+			t.Fatalf("%d. %v", i, lr.Err())
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%d. expecting\n%s\ngot\n%s", i, strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+		}
+	}
+}
+
+func TestLogReaderLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	lr := NewLogReader(strings.NewReader("[01:00:00] <nick> hi\n"), LogZNC, "#c", time.Date(2017, 9, 26, 0, 0, 0, 0, loc))
+	if !lr.Scan() {
+		t.Fatal(lr.Err())
+	}
+	if v := lr.Message().Tags["time"]; v != "2017-09-25T23:00:00.000Z" {
+		t.Errorf("expecting the time in UTC, got %q", v)
+	}
+}
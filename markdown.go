@@ -0,0 +1,194 @@
+package ircmessage
+
+import "strings"
+
+// Markdown emphasis markers, in the order they are opened.
+var markdownMarkers = []struct {
+	marker string
+	on     func(formatState) bool
+}{
+	{"**", func(f formatState) bool { return f.bold }},
+	{"_", func(f formatState) bool { return f.italic }},
+	{"~~", func(f formatState) bool { return f.strikethrough }},
+	{"`", func(f formatState) bool { return f.monospace }},
+}
+
+// IRCToMarkdown converts text with mIRC formatting codes to Markdown, for
+// bridging IRC to services that render it. Bold, italic, strikethrough and
+// monospace become **, _, ~~ and ` respectively, and other formatting,
+// such as colors, is dropped. Characters that Markdown would otherwise
+// interpret are escaped, except in code spans and URLs.
+func IRCToMarkdown(text string) string {
+	var (
+		b     strings.Builder
+		state formatState
+		open  []int // Indices into markdownMarkers, outermost first.
+	)
+	for i := 0; i < len(text); {
+		if n := formatCodeLen(text[i:]); n > 0 {
+			state.apply(text[i : i+n])
+			i += n
+			continue
+		}
+		open = reconcileMarkers(&b, open, state)
+		if !state.monospace && (strings.HasPrefix(text[i:], "http://") || strings.HasPrefix(text[i:], "https://")) {
+			end := strings.IndexAny(text[i:], " \x02\x03\x04\x0f\x11\x16\x1d\x1e\x1f")
+			if end < 0 {
+				end = len(text) - i
+			}
+			b.WriteString(text[i : i+end])
+			i += end
+			continue
+		}
+		if c := text[i]; !state.monospace && strings.IndexByte("\\*_~`[]", c) >= 0 {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(text[i])
+		i++
+	}
+	reconcileMarkers(&b, open, formatState{})
+	return b.String()
+}
+
+// reconcileMarkers writes the markers needed to go from the open markers to
+// those required by state, returning the markers then open. Markers are
+// closed in the reverse of the order they were opened.
+func reconcileMarkers(b *strings.Builder, open []int, state formatState) []int {
+	keep := 0
+	for keep < len(open) && markdownMarkers[open[keep]].on(state) {
+		keep++
+	}
+	for i := len(open) - 1; i >= keep; i-- {
+		b.WriteString(markdownMarkers[open[i]].marker)
+	}
+	open = open[:keep]
+	for i, m := range markdownMarkers {
+		if m.on(state) && !containsInt(open, i) {
+			b.WriteString(m.marker)
+			open = append(open, i)
+		}
+	}
+	return open
+}
+
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkdownToIRC converts a small subset of Markdown to text with mIRC
+// formatting codes: **bold** or __bold__, *italic* or _italic_,
+// ~~strikethrough~~, `code` and [links](url), which are written as the
+// label followed by the URL in parentheses. Backslash escapes are honored.
+// Anything else, including delimiters without a match, is left as it is.
+func MarkdownToIRC(text string) string {
+	var b strings.Builder
+	appendMarkdown(&b, text)
+	return b.String()
+}
+
+func appendMarkdown(b *strings.Builder, s string) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			b.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end > 0 {
+				b.WriteByte(fmtMonospace)
+				b.WriteString(s[i+1 : i+1+end])
+				b.WriteByte(fmtMonospace)
+				i += end + 2
+				continue
+			}
+		case c == '[':
+			if label, url, n := markdownLink(s[i:]); n > 0 {
+				appendMarkdown(b, label)
+				if label != url {
+					b.WriteString(" (" + url + ")")
+				}
+				i += n
+				continue
+			}
+		case c == '*' || c == '_' || c == '~':
+			if code, delim, end := markdownEmphasis(s, i); end > 0 {
+				b.WriteByte(code)
+				appendMarkdown(b, s[i+len(delim):end])
+				b.WriteByte(code)
+				i = end + len(delim)
+				continue
+			}
+		}
+		b.WriteByte(c)
+		i++
+	}
+}
+
+// markdownEmphasis looks for emphasis opening at s[i], returning the
+// formatting code it stands for, its delimiter and the index of the
+// closing delimiter, or an end of 0 if there is none.
+func markdownEmphasis(s string, i int) (code byte, delim string, end int) {
+	switch {
+	case strings.HasPrefix(s[i:], "**"), strings.HasPrefix(s[i:], "__"):
+		code, delim = fmtBold, s[i:i+2]
+	case strings.HasPrefix(s[i:], "~~"):
+		code, delim = fmtStrikethrough, "~~"
+	case s[i] == '*' || s[i] == '_':
+		code, delim = fmtItalic, s[i:i+1]
+	default:
+		return 0, "", 0
+	}
+	start := i + len(delim)
+	// An opening delimiter must be followed by text, and an underscore
+	// must not be within a word, as in snake_case.
+	if start == len(s) || s[start] == ' ' || delim[0] == '_' && i > 0 && isWordByte(s[i-1]) {
+		return 0, "", 0
+	}
+	for j := start + 1; j+len(delim) <= len(s); j++ {
+		if s[j:j+len(delim)] != delim || s[j-1] == ' ' {
+			continue
+		}
+		// Close at the end of a longer run, such as *** ending both
+		// bold and italic text, leaving the rest of it to the inner text.
+		for j+len(delim) < len(s) && s[j+len(delim)] == delim[0] {
+			j++
+		}
+		if after := j + len(delim); delim[0] == '_' && after < len(s) && isWordByte(s[after]) {
+			continue
+		}
+		return code, delim, j
+	}
+	return 0, "", 0
+}
+
+// markdownLink parses a [label](url) link at the start of s, returning its
+// parts and length, or a length of 0 if there is none.
+func markdownLink(s string) (label, url string, n int) {
+	end := strings.Index(s, "](")
+	if end < 0 {
+		return "", "", 0
+	}
+	rparen := strings.IndexByte(s[end+2:], ')')
+	if rparen < 0 {
+		return "", "", 0
+	}
+	label, url = s[1:end], s[end+2:end+2+rparen]
+	if label == "" || url == "" || strings.ContainsAny(url, " ") {
+		return "", "", 0
+	}
+	return label, url, end + 3 + rparen
+}
+
+func isWordByte(c byte) bool {
+	return isLetter(c) || isDigit(c) || c >= 0x80
+}
+
+func isASCIIPunct(c byte) bool {
+	return c >= '!' && c <= '/' || c >= ':' && c <= '@' || c >= '[' && c <= '`' || c >= '{' && c <= '~'
+}
@@ -0,0 +1,50 @@
+package ircmessage
+
+import "testing"
+
+var ircToMarkdownTests = []struct {
+	in, expected string
+}{
+	{"plain text", "plain text"},
+	{"\x02bold\x02 and \x1ditalic\x1d", "**bold** and _italic_"},
+	{"\x02bold \x1dboth\x02 italic\x1d", "**bold _both_**_ italic_"},
+	{"\x02\x1dboth\x0f plain", "**_both_** plain"},
+	{"\x11code *x*\x11 and \x1estrike\x1e", "`code *x*` and ~~strike~~"},
+	{"\x0304red\x03 \x1funderline\x1f", "red underline"},
+	{"2*3 snake_case [x]", "2\\*3 snake\\_case \\[x\\]"},
+	{"see https://example.com/a_b*c ok", "see https://example.com/a_b*c ok"},
+	{"\x02unterminated", "**unterminated**"},
+}
+
+func TestIRCToMarkdown(t *testing.T) {
+	for i, tt := range ircToMarkdownTests {
+		if s := IRCToMarkdown(tt.in); s != tt.expected {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, s)
+		}
+	}
+}
+
+var markdownToIRCTests = []struct {
+	in, expected string
+}{
+	{"plain text", "plain text"},
+	{"**bold** and __bold__", "\x02bold\x02 and \x02bold\x02"},
+	{"*italic* and _italic_", "\x1ditalic\x1d and \x1ditalic\x1d"},
+	{"**bold *both***", "\x02bold \x1dboth\x1d\x02"},
+	{"~~strike~~ `code **x**`", "\x1estrike\x1e \x11code **x**\x11"},
+	{"[docs](https://example.com) and [https://a.b](https://a.b)", "docs (https://example.com) and https://a.b"},
+	{"[**bold** label](https://example.com)", "\x02bold\x02 label (https://example.com)"},
+	{"2 * 3 * 4", "2 * 3 * 4"},
+	{"snake_case_name and file_", "snake_case_name and file_"},
+	{"\\*not italic\\*", "*not italic*"},
+	{"*unterminated", "*unterminated"},
+	{"[not a link] (x)", "[not a link] (x)"},
+}
+
+func TestMarkdownToIRC(t *testing.T) {
+	for i, tt := range markdownToIRCTests {
+		if s := MarkdownToIRC(tt.in); s != tt.expected {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, s)
+		}
+	}
+}
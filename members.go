@@ -0,0 +1,391 @@
+package ircmessage
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Member is a user in a channel, as tracked by a MemberTracker.
+type Member struct {
+	Nick string
+	// Prefixes holds the membership prefixes of the member, such as "@+",
+	// highest ranking first.
+	Prefixes string
+	// Account is the account the member is logged in to, if known, from
+	// extended-join or account tags.
+	Account string
+	User    string // Known from a JOIN or userhost-in-names.
+	Host    string
+}
+
+// MemberEventKind identifies the change a MemberEvent reports.
+type MemberEventKind int
+
+const (
+	// MemberJoined reports a member joining a channel.
+	MemberJoined MemberEventKind = iota
+	// MemberLeft reports a member parting, being kicked or quitting.
+	MemberLeft
+	// MemberRenamed reports a member changing nickname. OldNick holds
+	// the previous nickname.
+	MemberRenamed
+	// MemberChanged reports a change to a member's prefixes or account.
+	MemberChanged
+	// MembersListed reports that the member list of a channel has been
+	// replaced by a names list. Member is not set.
+	MembersListed
+)
+
+// MemberEvent reports a change to the membership of a channel.
+type MemberEvent struct {
+	Kind    MemberEventKind
+	Channel string
+	Member  Member
+	OldNick string
+}
+
+// MemberTracker maintains the members of the channels a client is in from
+// the JOIN, PART, QUIT, KICK, NICK and MODE messages and names lists (353
+// and 366) the server sends, along with account tags. The URL and creation
+// time of each channel are taken from 328 and 329, and its modes from 324
+// and MODE. Every incoming message should be passed to Handle. The ISUPPORT
+// parameters are kept in a NetworkConfig fed by Handle, unless one is shared
+// by SetNetworkConfig.
+//
+// A MemberTracker is safe for concurrent use.
+type MemberTracker struct {
+	// OnChange, if set, is called with each change after it has been
+	// applied, while the tracker is locked, so it must not call the
+	// tracker's methods.
+	OnChange func(MemberEvent)
+
+	mu       sync.RWMutex
+	nick     string
+	config   *NetworkConfig
+	shared   bool                       // Whether config is fed elsewhere.
+	channels map[string]*channelMembers // By folded name.
+	names    map[string][]Member        // Names lists being received.
+}
+
+type channelMembers struct {
+	name    string
+	members map[string]*Member // By folded nickname.
+	url     string
+	created time.Time
+	modes   ChannelModes
+}
+
+// NewMemberTracker returns a MemberTracker for a client registering as
+// nick.
+func NewMemberTracker(nick string) *MemberTracker {
+	return &MemberTracker{
+		nick:     nick,
+		config:   NewNetworkConfig(),
+		channels: make(map[string]*channelMembers),
+		names:    make(map[string][]Member),
+	}
+}
+
+// SetNetworkConfig sets the store the ISUPPORT parameters are taken from, in
+// place of the one the tracker keeps itself. The store must be kept current
+// by passing messages to its own Handle.
+func (t *MemberTracker) SetNetworkConfig(c *NetworkConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.config, t.shared = c, true
+}
+
+// Handle updates the membership from a message sent by the server.
+func (t *MemberTracker) Handle(m Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.shared {
+		t.config.Handle(m)
+	}
+	p := ParsePrefix(m.Prefix)
+	var nick string
+	if p != nil && !p.IsServer {
+		nick = p.Nickname
+		if account, ok := m.Tags["account"]; ok {
+			t.setAccount(nick, account)
+		}
+	}
+	switch upperCommand(m.Command) {
+	case "001":
+		if len(m.Params) > 0 {
+			t.nick = m.Params[0]
+		}
+	case "JOIN":
+		if nick != "" && len(m.Params) > 0 {
+			mem := Member{Nick: nick, User: p.User, Host: p.Host}
+			if len(m.Params) > 1 && m.Params[1] != "*" { // extended-join
+				mem.Account = m.Params[1]
+			} else if a := m.Tags["account"]; a != "*" {
+				mem.Account = a
+			}
+			t.join(m.Params[0], mem)
+		}
+	case "PART":
+		if nick != "" && len(m.Params) > 0 {
+			for _, c := range strings.Split(m.Params[0], ",") {
+				t.leave(c, nick)
+			}
+		}
+	case "KICK":
+		if len(m.Params) > 1 {
+			t.leave(m.Params[0], m.Params[1])
+		}
+	case "QUIT":
+		for _, c := range t.channels {
+			t.leave(c.name, nick)
+		}
+	case "NICK":
+		if nick != "" && len(m.Params) > 0 {
+			t.rename(nick, m.Params[0])
+		}
+	case "MODE":
+		if len(m.Params) > 1 {
+			t.mode(m.Params[0], ParseModeChanges(m.Params[1:], t.config.ISupport()))
+		}
+	case "353": // RPL_NAMREPLY
+		if len(m.Params) > 3 {
+			key := t.config.ISupport().Fold(m.Params[2])
+			for _, name := range strings.Fields(m.Params[3]) {
+				t.names[key] = append(t.names[key], t.parseName(name))
+			}
+		}
+	case "366": // RPL_ENDOFNAMES
+		if len(m.Params) > 1 {
+			t.endNames(m.Params[1])
+		}
+	case "328":
+		if channel, url, ok := ParseChannelURL(m); ok {
+			if c, ok := t.channels[t.config.ISupport().Fold(channel)]; ok {
+				c.url = url
+			}
+		}
+	case "324": // RPL_CHANNELMODEIS
+		if channel, modes, ok := ParseChannelModeIs(m, t.config.ISupport()); ok {
+			if c, ok := t.channels[t.config.ISupport().Fold(channel)]; ok {
+				c.modes = modes
+			}
+		}
+	case "329":
+		if channel, created, ok := ParseCreationTime(m); ok {
+			if c, ok := t.channels[t.config.ISupport().Fold(channel)]; ok {
+				c.created = created
+			}
+		}
+	}
+}
+
+func (t *MemberTracker) notify(e MemberEvent) {
+	if t.OnChange != nil {
+		t.OnChange(e)
+	}
+}
+
+func (t *MemberTracker) isSelf(nick string) bool {
+	return t.config.ISupport().Fold(nick) == t.config.ISupport().Fold(t.nick)
+}
+
+func (t *MemberTracker) join(channel string, mem Member) {
+	key := t.config.ISupport().Fold(channel)
+	c, ok := t.channels[key]
+	if !ok {
+		if !t.isSelf(mem.Nick) {
+			return
+		}
+		c = &channelMembers{name: channel, members: make(map[string]*Member)}
+		t.channels[key] = c
+	}
+	c.members[t.config.ISupport().Fold(mem.Nick)] = &mem
+	t.notify(MemberEvent{Kind: MemberJoined, Channel: c.name, Member: mem})
+}
+
+func (t *MemberTracker) leave(channel, nick string) {
+	key := t.config.ISupport().Fold(channel)
+	c, ok := t.channels[key]
+	if !ok {
+		return
+	}
+	folded := t.config.ISupport().Fold(nick)
+	mem, ok := c.members[folded]
+	if !ok {
+		return
+	}
+	delete(c.members, folded)
+	if t.isSelf(nick) {
+		delete(t.channels, key)
+	}
+	t.notify(MemberEvent{Kind: MemberLeft, Channel: c.name, Member: *mem})
+}
+
+func (t *MemberTracker) rename(old, nick string) {
+	if t.isSelf(old) {
+		t.nick = nick
+	}
+	folded, newFolded := t.config.ISupport().Fold(old), t.config.ISupport().Fold(nick)
+	for _, c := range t.channels {
+		if mem, ok := c.members[folded]; ok {
+			delete(c.members, folded)
+			mem.Nick = nick
+			c.members[newFolded] = mem
+			t.notify(MemberEvent{Kind: MemberRenamed, Channel: c.name, Member: *mem, OldNick: old})
+		}
+	}
+}
+
+func (t *MemberTracker) setAccount(nick, account string) {
+	if account == "*" {
+		account = ""
+	}
+	folded := t.config.ISupport().Fold(nick)
+	for _, c := range t.channels {
+		if mem, ok := c.members[folded]; ok && mem.Account != account {
+			mem.Account = account
+			t.notify(MemberEvent{Kind: MemberChanged, Channel: c.name, Member: *mem})
+		}
+	}
+}
+
+func (t *MemberTracker) mode(channel string, changes []ModeChange) {
+	c, ok := t.channels[t.config.ISupport().Fold(channel)]
+	if !ok {
+		return
+	}
+	c.modes = c.modes.Apply(changes, t.config.ISupport())
+	modes, prefixes := t.config.ISupport().Prefix()
+	for _, mc := range changes {
+		i := strings.IndexByte(modes, mc.Mode)
+		if i < 0 {
+			continue
+		}
+		mem, ok := c.members[t.config.ISupport().Fold(mc.Param)]
+		if !ok {
+			continue
+		}
+		has := strings.IndexByte(mem.Prefixes, prefixes[i]) >= 0
+		if has == mc.Add {
+			continue
+		}
+		// Rebuild the prefixes in rank order.
+		var b strings.Builder
+		for j := 0; j < len(prefixes); j++ {
+			if j == i && mc.Add || j != i && strings.IndexByte(mem.Prefixes, prefixes[j]) >= 0 {
+				b.WriteByte(prefixes[j])
+			}
+		}
+		mem.Prefixes = b.String()
+		t.notify(MemberEvent{Kind: MemberChanged, Channel: c.name, Member: *mem})
+	}
+}
+
+// parseName parses an entry of a names list, which may carry several
+// prefixes under multi-prefix and a full prefix under userhost-in-names.
+func (t *MemberTracker) parseName(name string) Member {
+	_, prefixes := t.config.ISupport().Prefix()
+	i := 0
+	for i < len(name) && strings.IndexByte(prefixes, name[i]) >= 0 {
+		i++
+	}
+	var mem Member
+	if p := ParsePrefix(name[i:]); p != nil {
+		mem = Member{Nick: p.Nickname, User: p.User, Host: p.Host}
+		if p.IsServer {
+			mem.Nick = p.Host
+		}
+	}
+	for j := 0; j < len(prefixes); j++ {
+		if strings.IndexByte(name[:i], prefixes[j]) >= 0 {
+			mem.Prefixes += string(prefixes[j])
+		}
+	}
+	return mem
+}
+
+func (t *MemberTracker) endNames(channel string) {
+	key := t.config.ISupport().Fold(channel)
+	names := t.names[key]
+	delete(t.names, key)
+	c, ok := t.channels[key]
+	if !ok {
+		return
+	}
+	members := make(map[string]*Member, len(names))
+	for _, mem := range names {
+		folded := t.config.ISupport().Fold(mem.Nick)
+		if old, ok := c.members[folded]; ok {
+			// Names lists do not carry accounts, or without
+			// userhost-in-names, users and hosts.
+			mem.Account = old.Account
+			if mem.Host == "" {
+				mem.User, mem.Host = old.User, old.Host
+			}
+		}
+		members[folded] = &mem
+	}
+	c.members = members
+	t.notify(MemberEvent{Kind: MembersListed, Channel: c.name})
+}
+
+// Channels returns the channels the client is in, sorted.
+func (t *MemberTracker) Channels() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	names := make([]string, 0, len(t.channels))
+	for _, c := range t.channels {
+		names = append(names, c.name)
+	}
+	sortStrings(names)
+	return names
+}
+
+// Members returns the members of channel, sorted by nickname, or nil if the
+// client is not in it.
+func (t *MemberTracker) Members(channel string) []Member {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	c, ok := t.channels[t.config.ISupport().Fold(channel)]
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(c.members))
+	for k := range c.members {
+		keys = append(keys, k)
+	}
+	sortStrings(keys)
+	members := make([]Member, len(keys))
+	for i, k := range keys {
+		members[i] = *c.members[k]
+	}
+	return members
+}
+
+// Member returns the member of channel with the given nickname.
+func (t *MemberTracker) Member(channel, nick string) (Member, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	c, ok := t.channels[t.config.ISupport().Fold(channel)]
+	if !ok {
+		return Member{}, false
+	}
+	mem, ok := c.members[t.config.ISupport().Fold(nick)]
+	if !ok {
+		return Member{}, false
+	}
+	return *mem, true
+}
+
+// Info returns what is known about channel, or false if the client is not
+// in it.
+func (t *MemberTracker) Info(channel string) (ChannelInfo, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	c, ok := t.channels[t.config.ISupport().Fold(channel)]
+	if !ok {
+		return ChannelInfo{}, false
+	}
+	return ChannelInfo{Name: c.name, URL: c.url, Created: c.created, Modes: c.modes}, true
+}
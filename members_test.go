@@ -0,0 +1,71 @@
+package ircmessage
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMemberTracker(t *testing.T) {
+	in := ":irc.example.com 001 me :Welcome\r\n" +
+		":irc.example.com 005 me PREFIX=(ov)@+ :are supported\r\n" +
+		":me!u@h JOIN #chan\r\n" +
+		":irc.example.com 353 me = #chan :me @+op!o@oh +voice\r\n" +
+		":irc.example.com 366 me #chan :End of /NAMES list.\r\n" +
+		":new!n@nh JOIN #chan acct :Real Name\r\n" +
+		":stranger!s@sh JOIN #elsewhere\r\n" +
+		":op!o@oh MODE #chan +o-v+v new voice new\r\n" +
+		":voice!v@vh NICK Loud\r\n" +
+		"@account=vacct :Loud!v@vh PRIVMSG #chan :hi\r\n" +
+		":op!o@oh KICK #chan me :bye\r\n" +
+		":me!u@h JOIN #chan\r\n" +
+		":op!o@oh JOIN #chan\r\n" +
+		":op!o@oh QUIT :gone\r\n"
+	var events []string
+	tr := NewMemberTracker("me")
+	tr.OnChange = func(e MemberEvent) {
+		events = append(events, fmt.Sprintf("%d %s %s%s %s", e.Kind, e.Channel, e.Member.Prefixes, e.Member.Nick, e.OldNick))
+	}
+	s := NewScanner(strings.NewReader(in))
+	for i := 0; s.Scan(); i++ {
+		tr.Handle(s.Message())
+		if i == 9 {
+			expected := []Member{
+				{Nick: "Loud", Account: "vacct"},
+				{Nick: "me", User: "u", Host: "h"},
+				{Nick: "new", Prefixes: "@+", Account: "acct", User: "n", Host: "nh"},
+				{Nick: "op", Prefixes: "@+", User: "o", Host: "oh"},
+			}
+			if got := tr.Members("#CHAN"); !reflect.DeepEqual(got, expected) {
+				t.Errorf("expecting members %+v, got %+v", expected, got)
+			}
+		}
+	}
+	expected := []string{
+		"0 #chan me ",
+		"4 #chan  ",
+		"0 #chan new ",
+		"3 #chan @new ",
+		"3 #chan voice ",
+		"3 #chan @+new ",
+		"2 #chan Loud voice",
+		"3 #chan Loud ",
+		"1 #chan me ",
+		"0 #chan me ",
+		"0 #chan op ",
+		"1 #chan op ",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expecting events\n%q, got\n%q", expected, events)
+	}
+	if got := tr.Channels(); !reflect.DeepEqual(got, []string{"#chan"}) {
+		t.Errorf("expecting to be in #chan only, got %v", got)
+	}
+	if _, ok := tr.Member("#chan", "new"); ok {
+		t.Error("expecting members from before the kick to be forgotten")
+	}
+	if m, ok := tr.Member("#chan", "ME"); !ok || m.Nick != "me" {
+		t.Errorf("expecting to find me, got %+v %v", m, ok)
+	}
+}
@@ -0,0 +1,157 @@
+package ircmessage
+
+import "sync"
+
+// Metadata commands as per:
+// https://ircv3.net/specs/extensions/metadata
+
+func metadata(target, sub string, args ...string) Message {
+	return Message{Command: "METADATA", Params: append([]string{target, sub}, args...)}
+}
+
+// MetadataGet requests the values of keys on target.
+func MetadataGet(target string, keys ...string) Message {
+	return metadata(target, "GET", keys...)
+}
+
+// MetadataList requests every key set on target.
+func MetadataList(target string) Message { return metadata(target, "LIST") }
+
+// MetadataSet sets key on target to value.
+func MetadataSet(target, key, value string) Message {
+	return metadata(target, "SET", key, value)
+}
+
+// MetadataUnset removes key from target.
+func MetadataUnset(target, key string) Message { return metadata(target, "SET", key) }
+
+// MetadataClear removes every key from target.
+func MetadataClear(target string) Message { return metadata(target, "CLEAR") }
+
+// MetadataSub subscribes to changes of keys.
+func MetadataSub(keys ...string) Message { return metadata("*", "SUB", keys...) }
+
+// MetadataUnsub unsubscribes from changes of keys.
+func MetadataUnsub(keys ...string) Message { return metadata("*", "UNSUB", keys...) }
+
+// MetadataSubs requests the list of subscribed keys.
+func MetadataSubs() Message { return metadata("*", "SUBS") }
+
+// MetadataSync requests the metadata of target that was deferred by the
+// server.
+func MetadataSync(target string) Message { return metadata(target, "SYNC") }
+
+// MetadataEntry is a metadata key and its value on a target.
+type MetadataEntry struct {
+	Target     string
+	Key        string
+	Visibility string
+	Value      string
+	// Unset is true when the key has no value.
+	Unset bool
+}
+
+// ParseMetadata parses a METADATA notification, RPL_KEYVALUE (761) or
+// RPL_KEYNOTSET (766) message into an entry. It returns false for any other
+// message.
+func ParseMetadata(m Message) (MetadataEntry, bool) {
+	p := m.Params
+	switch upperCommand(m.Command) {
+	case "METADATA":
+		if len(p) < 3 {
+			return MetadataEntry{}, false
+		}
+		e := MetadataEntry{Target: p[0], Key: p[1], Visibility: p[2]}
+		if len(p) > 3 {
+			e.Value = p[3]
+		} else {
+			e.Unset = true
+		}
+		return e, true
+	case "761": // RPL_KEYVALUE
+		if len(p) < 5 {
+			return MetadataEntry{}, false
+		}
+		return MetadataEntry{Target: p[1], Key: p[2], Visibility: p[3], Value: p[4]}, true
+	case "766": // RPL_KEYNOTSET
+		if len(p) < 3 {
+			return MetadataEntry{}, false
+		}
+		return MetadataEntry{Target: p[1], Key: p[2], Unset: true}, true
+	}
+	return MetadataEntry{}, false
+}
+
+// MetadataBatch returns the entries carried by a metadata batch.
+func MetadataBatch(b *Batch) []MetadataEntry {
+	var entries []MetadataEntry
+	for _, m := range b.Messages {
+		if e, ok := ParseMetadata(m); ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// MetadataCache holds the metadata of each target as reported by the
+// server. The zero value is ready to use, and a MetadataCache is safe for
+// concurrent use.
+type MetadataCache struct {
+	mu      sync.RWMutex
+	targets map[string]map[string]string
+}
+
+// Update applies a message to the cache, returning the entry it carried and
+// true if it was a metadata message.
+func (c *MetadataCache) Update(m Message) (MetadataEntry, bool) {
+	e, ok := ParseMetadata(m)
+	if !ok {
+		return e, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.targets == nil {
+		c.targets = make(map[string]map[string]string)
+	}
+	keys := c.targets[e.Target]
+	if e.Unset {
+		delete(keys, e.Key)
+		if len(keys) == 0 {
+			delete(c.targets, e.Target)
+		}
+		return e, true
+	}
+	if keys == nil {
+		keys = make(map[string]string)
+		c.targets[e.Target] = keys
+	}
+	keys[e.Key] = e.Value
+	return e, true
+}
+
+// Get returns the value of key on target, and whether it is set.
+func (c *MetadataCache) Get(target, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	v, ok := c.targets[target][key]
+	return v, ok
+}
+
+// Keys returns a copy of every key and value known for target.
+func (c *MetadataCache) Keys(target string) map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make(map[string]string, len(c.targets[target]))
+	for k, v := range c.targets[target] {
+		keys[k] = v
+	}
+	return keys
+}
+
+// Forget removes everything known about target, for example when a user
+// quits or a channel is parted.
+func (c *MetadataCache) Forget(target string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.targets, target)
+}
@@ -0,0 +1,52 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetadataBuilders(t *testing.T) {
+	tests := []struct {
+		m        Message
+		expected []string
+	}{
+		{MetadataGet("nick", "avatar", "url"), []string{"nick", "GET", "avatar", "url"}},
+		{MetadataList("#chan"), []string{"#chan", "LIST"}},
+		{MetadataSet("*", "url", "https://example.com"), []string{"*", "SET", "url", "https://example.com"}},
+		{MetadataUnset("*", "url"), []string{"*", "SET", "url"}},
+		{MetadataClear("*"), []string{"*", "CLEAR"}},
+		{MetadataSub("avatar"), []string{"*", "SUB", "avatar"}},
+		{MetadataSync("#chan"), []string{"#chan", "SYNC"}},
+	}
+	for i, tt := range tests {
+		if tt.m.Command != "METADATA" || !reflect.DeepEqual(tt.m.Params, tt.expected) {
+			t.Errorf("%d. expecting params %q, got %v", i, tt.expected, tt.m)
+		}
+	}
+}
+
+func TestMetadataCache(t *testing.T) {
+	var c MetadataCache
+	updates := []Message{
+		{Command: "761", Params: []string{"me", "nick", "avatar", "*", "https://example.com/a.png"}},
+		{Command: "METADATA", Params: []string{"nick", "url", "*", "https://example.com"}},
+		{Command: "METADATA", Params: []string{"nick", "avatar", "*"}},
+		{Command: "766", Params: []string{"me", "nick", "display-name", "key not set"}},
+	}
+	for i, m := range updates {
+		if _, ok := c.Update(m); !ok {
+			t.Errorf("%d. expecting %v to update the cache", i, m)
+		}
+	}
+	if _, ok := c.Update(Message{Command: "PRIVMSG", Params: []string{"#chan", "hi"}}); ok {
+		t.Error("expecting PRIVMSG to be ignored")
+	}
+	expected := map[string]string{"url": "https://example.com"}
+	if keys := c.Keys("nick"); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expecting %v, got %v", expected, keys)
+	}
+	c.Forget("nick")
+	if _, ok := c.Get("nick", "url"); ok {
+		t.Error("expecting nick to be forgotten")
+	}
+}
@@ -0,0 +1,140 @@
+package ircmessage
+
+import "strings"
+
+// ModeChange is a single mode being set or unset by a MODE message.
+type ModeChange struct {
+	Add   bool
+	Mode  byte
+	Param string // The parameter of the mode, if it takes one.
+}
+
+// ParseModeChanges parses the mode string and parameters of a channel MODE
+// message, which follow the target, such as {"+ov-k", "a", "b", "key"}.
+// The modes that take a parameter are those of the CHANMODES and PREFIX
+// parameters of isupport, which may be nil. A mode missing its parameter is
+// given an empty one.
+func ParseModeChanges(params []string, isupport *ISupport) []ModeChange {
+	if len(params) == 0 {
+		return nil
+	}
+	members, _ := isupport.Prefix()
+	list, always, set, _ := isupport.ChanModes()
+	var changes []ModeChange
+	args := params[1:]
+	add := true
+	for i := 0; i < len(params[0]); i++ {
+		c := params[0][i]
+		switch c {
+		case '+', '-':
+			add = c == '+'
+			continue
+		}
+		mc := ModeChange{Add: add, Mode: c}
+		if takesParam(c, add, members+list+always, set) && len(args) > 0 {
+			mc.Param, args = args[0], args[1:]
+		}
+		changes = append(changes, mc)
+	}
+	return changes
+}
+
+// takesParam reports whether mode takes a parameter, given the modes that
+// always do and those that do when being set.
+func takesParam(mode byte, add bool, always, set string) bool {
+	return strings.IndexByte(always, mode) >= 0 || add && strings.IndexByte(set, mode) >= 0
+}
+
+// RepeatMode returns the changes setting, or unsetting if add is false, mode
+// with each of params, such as the bans of a mass ban for ModeMessages.
+func RepeatMode(add bool, mode byte, params ...string) []ModeChange {
+	changes := make([]ModeChange, len(params))
+	for i, p := range params {
+		changes[i] = ModeChange{Add: add, Mode: mode, Param: p}
+	}
+	return changes
+}
+
+// ModeMessages returns the fewest MODE messages making changes to channel
+// in order, each changing at most as many modes with a parameter as the
+// MODES parameter of isupport allows and being at most budget bytes,
+// including CRLF. Any prefix the server adds when relaying the messages
+// must be accounted for by the caller in budget. Changes with a non-empty
+// Param are taken to be modes with a parameter. A change too long to fit
+// in budget on its own is given a message of its own regardless.
+func ModeMessages(channel string, changes []ModeChange, isupport *ISupport, budget int) []Message {
+	limit := isupport.Modes()
+	// The length of "MODE <channel> " and CRLF, to which each change adds
+	// its mode letter, any sign and any parameter.
+	base := len("MODE ") + len(channel) + len(" ") + len("\r\n")
+	var (
+		msgs   []Message
+		start  int    // The first change of the message being built.
+		params int    // The number of those changes with a parameter.
+		n      = base // The length of the message being built.
+		colon  int    // 1 if its last parameter must be trailing.
+	)
+	for i, c := range changes {
+		if i > start {
+			p, col := params, colon
+			if c.Param != "" {
+				p, col = p+1, trailingColon(c.Param)
+			}
+			if limit > 0 && p > limit || n+modeChangeLen(c, c.Add != changes[i-1].Add)+col > budget {
+				msgs = append(msgs, modeMessage(channel, changes[start:i]))
+				start, params, n, colon = i, 0, base, 0
+			}
+		}
+		n += modeChangeLen(c, i == start || c.Add != changes[i-1].Add)
+		if c.Param != "" {
+			params++
+			colon = trailingColon(c.Param)
+		}
+	}
+	if start < len(changes) {
+		msgs = append(msgs, modeMessage(channel, changes[start:]))
+	}
+	return msgs
+}
+
+// modeChangeLen returns the number of bytes c adds to a MODE message,
+// including its sign if sign is set.
+func modeChangeLen(c ModeChange, sign bool) int {
+	n := 1
+	if sign {
+		n++
+	}
+	if c.Param != "" {
+		n += 1 + len(c.Param)
+	}
+	return n
+}
+
+// trailingColon returns 1 if param must be sent as a trailing parameter
+// with a colon, or 0 otherwise.
+func trailingColon(param string) int {
+	if param[0] == ':' || strings.IndexByte(param, ' ') >= 0 {
+		return 1
+	}
+	return 0
+}
+
+func modeMessage(channel string, changes []ModeChange) Message {
+	var modes strings.Builder
+	params := []string{channel, ""}
+	for i, c := range changes {
+		if i == 0 || c.Add != changes[i-1].Add {
+			if c.Add {
+				modes.WriteByte('+')
+			} else {
+				modes.WriteByte('-')
+			}
+		}
+		modes.WriteByte(c.Mode)
+		if c.Param != "" {
+			params = append(params, c.Param)
+		}
+	}
+	params[1] = modes.String()
+	return Message{Command: "MODE", Params: params}
+}
@@ -0,0 +1,84 @@
+package ircmessage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var modeChangeTests = []struct {
+	params   []string
+	expected []ModeChange
+}{
+	{nil, nil},
+	{[]string{"+nt"}, []ModeChange{{true, 'n', ""}, {true, 't', ""}}},
+	{[]string{"+ov-v", "a", "b", "c"}, []ModeChange{{true, 'o', "a"}, {true, 'v', "b"}, {false, 'v', "c"}}},
+	{[]string{"+kl-l", "key", "10"}, []ModeChange{{true, 'k', "key"}, {true, 'l', "10"}, {false, 'l', ""}}},
+	{[]string{"-k+b", "key", "*!*@*"}, []ModeChange{{false, 'k', "key"}, {true, 'b', "*!*@*"}}},
+	{[]string{"+b"}, []ModeChange{{true, 'b', ""}}},
+}
+
+func TestParseModeChanges(t *testing.T) {
+	for i, tt := range modeChangeTests {
+		if got := ParseModeChanges(tt.params, nil); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%d. expecting %v, got %v", i, tt.expected, got)
+		}
+	}
+	is := NewISupport()
+	is.Update(Message{Command: "005", Params: []string{"nick", "PREFIX=(qov)~@+", "CHANMODES=beI,k,fl,imnpst", "are supported"}})
+	expected := []ModeChange{{true, 'q', "a"}, {true, 'f', "x"}, {true, 'e', "m"}}
+	if got := ParseModeChanges([]string{"+qfe", "a", "x", "m"}, is); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expecting %v, got %v", expected, got)
+	}
+}
+
+func TestModeMessages(t *testing.T) {
+	is := NewISupport()
+	is.Update(Message{Command: "005", Params: []string{"nick", "MODES=4", "are supported"}})
+	changes := append(RepeatMode(true, 'v', "a", "b", "c", "d", "e"), ModeChange{Add: true, Mode: 'm'})
+	changes = append(changes, RepeatMode(false, 'b', "x!*@*")...)
+	msgs := ModeMessages("#chan", changes, is, 512)
+	expected := []Message{
+		{Command: "MODE", Params: []string{"#chan", "+vvvv", "a", "b", "c", "d"}},
+		{Command: "MODE", Params: []string{"#chan", "+vm-b", "e", "x!*@*"}},
+	}
+	if !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expecting %v, got %v", expected, msgs)
+	}
+
+	// Without a limit on modes, only the budget splits the changes.
+	is.Update(Message{Command: "005", Params: []string{"nick", "MODES", "are supported"}})
+	var masks []string
+	for i := 0; i < 100; i++ {
+		masks = append(masks, fmt.Sprintf("*!*@host%02d.example.org", i))
+	}
+	msgs = ModeMessages("#chan", RepeatMode(true, 'b', masks...), is, 512)
+	total := 0
+	for _, m := range msgs {
+		b, err := AppendMessage(nil, m)
+		if err != nil || len(b) > 512 {
+			t.Errorf("expecting at most 512 bytes, got %d %v", len(b), err)
+		}
+		total += len(m.Params) - 2
+	}
+	// Each ban takes 24 bytes besides the 14 of "MODE #chan +\r\n", so 20
+	// fit in a message.
+	if len(msgs) != 5 || total != len(masks) {
+		t.Errorf("expecting 100 bans in 5 messages, got %d in %d", total, len(msgs))
+	}
+	// "MODE #c +bb a :x\r\n" is 19 bytes, with the colon the last
+	// parameter needs.
+	for _, tt := range []struct {
+		budget, msgs int
+	}{{19, 1}, {18, 2}} {
+		if msgs := ModeMessages("#c", RepeatMode(true, 'b', "a", ":x"), nil, tt.budget); len(msgs) != tt.msgs {
+			t.Errorf("expecting %d messages in %d bytes, got %v", tt.msgs, tt.budget, msgs)
+		}
+	}
+	if msgs := ModeMessages("#chan", nil, nil, 512); msgs != nil {
+		t.Errorf("expecting no messages, got %v", msgs)
+	}
+	if msgs := ModeMessages("#chan", RepeatMode(true, 'o', "a", "b", "c", "d"), nil, 512); len(msgs) != 2 {
+		t.Errorf("expecting the default of 3 modes per message, got %v", msgs)
+	}
+}
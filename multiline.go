@@ -0,0 +1,104 @@
+package ircmessage
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	batchMultiline = "draft/multiline"
+	tagConcat      = "draft/multiline-concat"
+)
+
+// ErrMultilineTooLong is returned when text cannot be sent as a single
+// multiline batch within the limits advertised by the server.
+var ErrMultilineTooLong = errors.New("multiline message exceeds limits")
+
+// MultilineLimits holds the limits advertised as the value of the
+// draft/multiline capability. A zero field means no limit was advertised.
+type MultilineLimits struct {
+	MaxBytes int
+	MaxLines int
+}
+
+// ParseMultilineLimits parses a draft/multiline capability value such as
+// "max-bytes=4096,max-lines=24".
+func ParseMultilineLimits(value string) MultilineLimits {
+	var l MultilineLimits
+	for _, kv := range strings.Split(value, ",") {
+		k, v, _ := strings.Cut(kv, "=")
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			continue
+		}
+		switch k {
+		case "max-bytes":
+			l.MaxBytes = n
+		case "max-lines":
+			l.MaxLines = n
+		}
+	}
+	return l
+}
+
+// MultilineBatch returns the messages required to send text to target as a
+// draft/multiline batch with the given reference tag, including the opening
+// and closing BATCH messages. Command is typically PRIVMSG or NOTICE.
+//
+// Text is split into lines on newlines, and lines too long to fit in a
+// message of budget bytes (as described for SplitText) are split further
+// and marked for concatenation.
+func MultilineBatch(ref, command, target, text string, budget int, limits MultilineLimits) ([]Message, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	// Only the bytes of the lines count against max-bytes, not the
+	// newlines separating them.
+	size := len(text) - (len(lines) - 1)
+	msgs := []Message{{Command: "BATCH", Params: []string{"+" + ref, batchMultiline, target}}}
+	avail := textBudget(target, budget)
+	for _, line := range lines {
+		chunks := splitText(line, avail, false)
+		if len(chunks) == 0 {
+			chunks = []string{""}
+		}
+		for i, chunk := range chunks {
+			tags := map[string]string{"batch": ref}
+			if i > 0 {
+				tags[tagConcat] = ""
+			}
+			msgs = append(msgs, Message{Tags: tags, Command: command, Params: []string{target, chunk}})
+		}
+	}
+	if limits.MaxBytes > 0 && size > limits.MaxBytes ||
+		limits.MaxLines > 0 && len(msgs)-1 > limits.MaxLines {
+		return nil, ErrMultilineTooLong
+	}
+	return append(msgs, Message{Command: "BATCH", Params: []string{"-" + ref}}), nil
+}
+
+// JoinMultiline reassembles a completed draft/multiline batch into a single
+// message whose text contains the batch lines separated by newlines. The
+// tags of the returned message are those of the opening BATCH message.
+func JoinMultiline(b *Batch) (Message, error) {
+	if b.Type != batchMultiline || len(b.Params) == 0 || len(b.Messages) == 0 {
+		return Message{}, ErrMessageMalformed
+	}
+	var text strings.Builder
+	for i, m := range b.Messages {
+		if len(m.Params) < 2 {
+			return Message{}, ErrMessageMalformed
+		}
+		if _, concat := m.Tags[tagConcat]; i > 0 && !concat {
+			text.WriteByte('\n')
+		}
+		text.WriteString(m.Params[len(m.Params)-1])
+	}
+	first := b.Messages[0]
+	return Message{
+		Tags:    b.Tags,
+		Prefix:  first.Prefix,
+		Command: first.Command,
+		Params:  []string{b.Params[0], text.String()},
+	}, nil
+}
@@ -0,0 +1,63 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMultilineLimits(t *testing.T) {
+	l := ParseMultilineLimits("max-bytes=4096,max-lines=24,future=1")
+	if l != (MultilineLimits{MaxBytes: 4096, MaxLines: 24}) {
+		t.Errorf("unexpected limits %+v", l)
+	}
+}
+
+func TestMultilineBatch(t *testing.T) {
+	msgs, err := MultilineBatch("ref", "PRIVMSG", "#c", "first lines\nhello world foo", 24, MultilineLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Message{
+		{Command: "BATCH", Params: []string{"+ref", "draft/multiline", "#c"}},
+		{Tags: map[string]string{"batch": "ref"}, Command: "PRIVMSG", Params: []string{"#c", "first "}},
+		{Tags: map[string]string{"batch": "ref", "draft/multiline-concat": ""}, Command: "PRIVMSG", Params: []string{"#c", "lines"}},
+		{Tags: map[string]string{"batch": "ref"}, Command: "PRIVMSG", Params: []string{"#c", "hello "}},
+		{Tags: map[string]string{"batch": "ref", "draft/multiline-concat": ""}, Command: "PRIVMSG", Params: []string{"#c", "world foo"}},
+		{Command: "BATCH", Params: []string{"-ref"}},
+	}
+	if !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expecting %v\ngot %v", expected, msgs)
+	}
+	var c BatchCollector
+	var b *Batch
+	for _, m := range msgs {
+		if done, _ := c.Add(m); done != nil {
+			b = done
+		}
+	}
+	joined, err := JoinMultiline(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if joined.Command != "PRIVMSG" || !reflect.DeepEqual(joined.Params, []string{"#c", "first lines\nhello world foo"}) {
+		t.Errorf("unexpected joined message %v", joined)
+	}
+}
+
+func TestMultilineBatchLimits(t *testing.T) {
+	if _, err := MultilineBatch("r", "PRIVMSG", "#c", "a\nb\nc", 512, MultilineLimits{MaxLines: 2}); err != ErrMultilineTooLong {
+		t.Errorf("expecting %v for too many lines, got %v", ErrMultilineTooLong, err)
+	}
+	if _, err := MultilineBatch("r", "PRIVMSG", "#c", "abcdef", 512, MultilineLimits{MaxBytes: 5}); err != ErrMultilineTooLong {
+		t.Errorf("expecting %v for too many bytes, got %v", ErrMultilineTooLong, err)
+	}
+	if _, err := MultilineBatch("r", "PRIVMSG", "#c", "a\nb", 512, MultilineLimits{MaxBytes: 3, MaxLines: 2}); err != nil {
+		t.Errorf("expecting no error within limits, got %v", err)
+	}
+	if _, err := MultilineBatch("r", "PRIVMSG", "#c", "ab\ncd", 512, MultilineLimits{MaxBytes: 4}); err != nil {
+		t.Errorf("expecting separators not to count against max-bytes, got %v", err)
+	}
+	if _, err := MultilineBatch("r", "PRIVMSG", "#c", "ab\ncde", 512, MultilineLimits{MaxBytes: 4}); err != ErrMultilineTooLong {
+		t.Errorf("expecting %v one byte over max-bytes, got %v", ErrMultilineTooLong, err)
+	}
+}
@@ -0,0 +1,98 @@
+package ircmessage
+
+import (
+	"strconv"
+	"sync"
+)
+
+// A HandlerFunc handles a message dispatched by a Mux.
+type HandlerFunc func(Message)
+
+// Mux dispatches messages to handlers registered by command, in the manner
+// of http.ServeMux. When several handlers match a message the most specific
+// is chosen: a handler for its exact command, then a handler for a range of
+// numerics containing it, then the wildcard handler.
+//
+// The zero value is ready to use, and a Mux is safe for concurrent use.
+type Mux struct {
+	mu       sync.RWMutex
+	commands map[string]HandlerFunc
+	ranges   []numericRange
+	wildcard HandlerFunc
+}
+
+type numericRange struct {
+	from, to int
+	h        HandlerFunc
+}
+
+// Handle registers h for messages with the given command, compared
+// case-insensitively. A command of "*" registers the wildcard handler.
+// Registering a second handler for a command replaces the first.
+func (mux *Mux) Handle(command string, h HandlerFunc) {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	if command == "*" {
+		mux.wildcard = h
+		return
+	}
+	if mux.commands == nil {
+		mux.commands = make(map[string]HandlerFunc)
+	}
+	mux.commands[upperCommand(command)] = h
+}
+
+// HandleRange registers h for numeric replies from from to to inclusive,
+// for example 400 to 599 for errors. Where ranges overlap the one
+// registered first is used.
+func (mux *Mux) HandleRange(from, to int, h HandlerFunc) {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	mux.ranges = append(mux.ranges, numericRange{from, to, h})
+}
+
+// Handler returns the handler that m would be dispatched to, or nil if
+// there is none.
+func (mux *Mux) Handler(m Message) HandlerFunc {
+	mux.mu.RLock()
+	defer mux.mu.RUnlock()
+	if h, ok := mux.commands[upperCommand(m.Command)]; ok {
+		return h
+	}
+	if n, ok := numeric(m.Command); ok {
+		for _, r := range mux.ranges {
+			if n >= r.from && n <= r.to {
+				return r.h
+			}
+		}
+	}
+	return mux.wildcard
+}
+
+// Dispatch calls the handler for m, reporting whether there was one.
+func (mux *Mux) Dispatch(m Message) bool {
+	h := mux.Handler(m)
+	if h == nil {
+		return false
+	}
+	h(m)
+	return true
+}
+
+// Serve dispatches each message scanned by s until the scan stops, and
+// returns the scanner's error.
+func (mux *Mux) Serve(s *Scanner) error {
+	for s.Scan() {
+		mux.Dispatch(s.Message())
+	}
+	return s.Err()
+}
+
+// numeric parses a three digit numeric reply.
+func numeric(command string) (int, bool) {
+	if len(command) != 3 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(command)
+	return n, err == nil && command[0] != '-' && command[0] != '+'
+}
@@ -0,0 +1,51 @@
+package ircmessage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMux(t *testing.T) {
+	var got []string
+	record := func(name string) HandlerFunc {
+		return func(m Message) { got = append(got, name+":"+m.Command) }
+	}
+	var mux Mux
+	mux.Handle("privmsg", record("privmsg"))
+	mux.Handle("433", record("nick"))
+	mux.HandleRange(400, 599, record("error"))
+	mux.HandleRange(1, 999, record("numeric"))
+	in := "PRIVMSG #c :hi\r\n433 * n :in use\r\n401 * n :no such nick\r\n001 n :welcome\r\nPING :x\r\n"
+	if err := mux.Serve(NewScanner(strings.NewReader(in))); err != nil {
+		t.Fatal(err)
+	}
+	expected := "privmsg:PRIVMSG,nick:433,error:401,numeric:001"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("expecting %s, got %s", expected, strings.Join(got, ","))
+	}
+	mux.Handle("*", record("any"))
+	if !mux.Dispatch(Message{Command: "PING"}) || got[len(got)-1] != "any:PING" {
+		t.Errorf("expecting wildcard handler for PING, got %v", got)
+	}
+}
+
+var numericTests = []struct {
+	in string
+	n  int
+	ok bool
+}{
+	{"001", 1, true},
+	{"433", 433, true},
+	{"PRIVMSG", 0, false},
+	{"+12", 0, false},
+	{"12", 0, false},
+}
+
+func TestNumeric(t *testing.T) {
+	for i, tt := range numericTests {
+		n, ok := numeric(tt.in)
+		if ok != tt.ok || ok && n != tt.n {
+			t.Errorf("%d. expecting %d %t, got %d %t", i, tt.n, tt.ok, n, ok)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package ircmessage
+
+import (
+	"strings"
+	"sync"
+)
+
+// NetworkConfig is a shared store of the parameters of a network: its
+// ISUPPORT tokens, including the casemapping, and the capabilities
+// enabled. Passing every incoming message to Handle keeps it current, and
+// components that need network parameters can then consult the same store
+// so that they agree: SessionState, MemberTracker, Triggers, HistoryDeduper,
+// WhowasCollector and EchoTracker take one with SetNetworkConfig.
+//
+// A NetworkConfig is safe for concurrent use. The *ISupport returned by
+// ISupport is never modified, and is replaced when the server advertises
+// new tokens, so it may be used without locking.
+type NetworkConfig struct {
+	mu       sync.RWMutex
+	isupport *ISupport
+	caps     map[string]string
+}
+
+// NewNetworkConfig returns a NetworkConfig holding the defaults that apply
+// before the server has advertised anything.
+func NewNetworkConfig() *NetworkConfig {
+	return &NetworkConfig{isupport: NewISupport(), caps: make(map[string]string)}
+}
+
+// Handle updates the store from a message sent by the server, applying
+// RPL_ISUPPORT (005) tokens and the capabilities acknowledged or deleted by
+// CAP ACK and CAP DEL.
+func (c *NetworkConfig) Handle(m Message) {
+	switch {
+	case m.Command == "005":
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		is := &ISupport{tokens: make(map[string]string, len(c.isupport.tokens)+len(m.Params))}
+		for k, v := range c.isupport.tokens {
+			is.tokens[k] = v
+		}
+		if is.Update(m) {
+			c.isupport = is
+		}
+	case HasCommand(m, "CAP") && len(m.Params) > 2:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		updateEnabledCaps(c.caps, m.Params[1], m.Params[len(m.Params)-1])
+	}
+}
+
+// ISupport returns the current ISUPPORT parameters, for passing to the
+// functions of the package that take them.
+func (c *NetworkConfig) ISupport() *ISupport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.isupport
+}
+
+// HasCap reports whether the capability name is enabled.
+func (c *NetworkConfig) HasCap(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.caps[strings.ToLower(capName(name))]
+	return ok
+}
+
+// Caps returns the enabled capabilities, sorted, with any values.
+func (c *NetworkConfig) Caps() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	caps := make([]string, 0, len(c.caps))
+	for _, v := range c.caps {
+		caps = append(caps, v)
+	}
+	sortStrings(caps)
+	return caps
+}
+
+// Fold casefolds s according to the network's casemapping.
+func (c *NetworkConfig) Fold(s string) string { return c.ISupport().Fold(s) }
+
+// IsChannel reports whether name is a channel name on the network.
+func (c *NetworkConfig) IsChannel(name string) bool { return c.ISupport().IsChannel(name) }
+
+// ValidChannel checks name as ValidChannel does for the network.
+func (c *NetworkConfig) ValidChannel(name string) error { return ValidChannel(name, c.ISupport()) }
+
+// ValidNick checks nick as ValidNick does for the network.
+func (c *NetworkConfig) ValidNick(nick string) error { return ValidNick(nick, c.ISupport()) }
+
+// ParseModeChanges parses the parameters of a channel MODE message as
+// ParseModeChanges does for the network.
+func (c *NetworkConfig) ParseModeChanges(params []string) []ModeChange {
+	return ParseModeChanges(params, c.ISupport())
+}
+
+// isupportRef refers to the ISUPPORT parameters a component uses: the
+// current ones of a NetworkConfig if one is set, and otherwise those set
+// directly, which may be nil.
+type isupportRef struct {
+	isupport *ISupport
+	config   *NetworkConfig
+}
+
+func (r *isupportRef) get() *ISupport {
+	if r.config != nil {
+		return r.config.ISupport()
+	}
+	return r.isupport
+}
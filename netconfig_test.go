@@ -0,0 +1,90 @@
+package ircmessage
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestNetworkConfig(t *testing.T) {
+	c := NewNetworkConfig()
+	before := c.ISupport()
+	if c.IsChannel("&local") != true || c.Fold("A[") != "a{" {
+		t.Error("unexpected defaults")
+	}
+	c.Handle(Message{Command: "005", Params: []string{"nick", "CHANTYPES=#", "CASEMAPPING=ascii", "PREFIX=(qov)~@+", "are supported"}})
+	c.Handle(Message{Command: "005", Params: []string{"nick", "NICKLEN=30", "are supported"}})
+	if before.ChanTypes() != "#&" {
+		t.Error("expecting an earlier ISupport to be left unchanged")
+	}
+	if c.IsChannel("&local") || c.Fold("A[") != "a[" || c.ISupport().NickLen() != 30 {
+		t.Error("expecting the advertised tokens to apply")
+	}
+	if err := c.ValidChannel("&local"); err != ErrInvalidChannel {
+		t.Errorf("expecting %v, got %v", ErrInvalidChannel, err)
+	}
+	if err := c.ValidNick("a-very-long-nickname"); err != nil {
+		t.Errorf("expecting a nickname within NICKLEN to be valid, got %v", err)
+	}
+	expected := []ModeChange{{true, 'q', "a"}}
+	if got := c.ParseModeChanges([]string{"+q", "a"}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expecting %v, got %v", expected, got)
+	}
+
+	c.Handle(Message{Command: "CAP", Params: []string{"*", "ACK", "sasl=PLAIN multi-prefix"}})
+	c.Handle(Message{Command: "CAP", Params: []string{"*", "DEL", "SASL"}})
+	if c.HasCap("sasl") || !c.HasCap("Multi-Prefix") || !reflect.DeepEqual(c.Caps(), []string{"multi-prefix"}) {
+		t.Errorf("unexpected caps %v", c.Caps())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Handle(Message{Command: "005", Params: []string{"nick", "NETWORK=x", "are supported"}})
+				c.IsChannel("#chan")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNetworkConfigShared(t *testing.T) {
+	c := NewNetworkConfig()
+	session := NewSessionState("me")
+	session.SetNetworkConfig(c)
+	members := NewMemberTracker("me")
+	members.SetNetworkConfig(c)
+	var triggers Triggers
+	triggers.SetNetworkConfig(c)
+	var hits int
+	triggers.Subscribe(Trigger{Target: "#a[b]"}, func(Message) { hits++ })
+
+	// Only the shared store sees the 005 and CAP messages.
+	c.Handle(Message{Command: "005", Params: []string{"me", "CASEMAPPING=ascii", "are supported"}})
+	c.Handle(Message{Command: "CAP", Params: []string{"me", "ACK", "account-tag"}})
+	for _, m := range []Message{
+		{Prefix: "me!u@h", Command: "JOIN", Params: []string{"#A[B]"}},
+		{Prefix: "you!u@h", Command: "JOIN", Params: []string{"#a[b]"}},
+	} {
+		session.Handle(m)
+		members.Handle(m)
+	}
+	if !session.HasCap("ACCOUNT-TAG") || session.Snapshot().ISupport.Casemapping() != "ascii" {
+		t.Error("expecting the session to use the shared store")
+	}
+	// Under ascii casemapping, #a{b} is a different channel.
+	if session.InChannel("#a{b}") || !session.InChannel("#a[b]") {
+		t.Error("expecting the session to fold with ascii casemapping")
+	}
+	if _, ok := members.Member("#a{b}", "you"); ok {
+		t.Error("expecting the tracker to fold with ascii casemapping")
+	}
+	triggers.Dispatch(Message{Command: "PRIVMSG", Params: []string{"#A{B}", "hi"}})
+	triggers.Dispatch(Message{Command: "PRIVMSG", Params: []string{"#A[B]", "hi"}})
+	if hits != 1 {
+		t.Errorf("expecting one match under ascii casemapping, got %d", hits)
+	}
+}
@@ -0,0 +1,70 @@
+package ircmessage
+
+// normalizedParams lists, by command, the indices of parameters holding
+// channel names or nicknames.
+var normalizedParams = map[string][]int{
+	"INVITE":  {0, 1},
+	"JOIN":    {0},
+	"KICK":    {0, 1},
+	"MODE":    {0},
+	"NAMES":   {0},
+	"NICK":    {0},
+	"NOTICE":  {0},
+	"PART":    {0},
+	"PRIVMSG": {0},
+	"TAGMSG":  {0},
+	"TOPIC":   {0},
+	"WHO":     {0},
+	"311":     {1},    // RPL_WHOISUSER
+	"324":     {1},    // RPL_CHANNELMODEIS
+	"329":     {1},    // RPL_CREATIONTIME
+	"331":     {1},    // RPL_NOTOPIC
+	"332":     {1},    // RPL_TOPIC
+	"333":     {1},    // RPL_TOPICWHOTIME
+	"341":     {1, 2}, // RPL_INVITING
+	"352":     {1, 5}, // RPL_WHOREPLY
+	"353":     {2},    // RPL_NAMREPLY
+	"366":     {1},    // RPL_ENDOFNAMES
+	"367":     {1},    // RPL_BANLIST
+	"368":     {1},    // RPL_ENDOFBANLIST
+}
+
+// Normalize sets the Scanner to casefold the channel and nickname
+// parameters of the commands and numerics that carry them, using the
+// casemapping advertised in is, so that they can be used directly as map
+// keys. Lists of targets, as taken by JOIN and PRIVMSG, are folded as a
+// whole. The prefix and all other parameters are left as received.
+//
+// The casemapping is looked up for every message, so is may be updated as
+// RPL_ISUPPORT replies arrive, but not concurrently with Scan. A nil is
+// uses the default rfc1459 casemapping. Normalize must be called before the
+// first call to Scan.
+func (s *Scanner) Normalize(is *ISupport) {
+	s.normalize = true
+	s.isupport = is
+}
+
+// normalizeMessage returns m with its channel and nickname parameters
+// casefolded according to is. The params of m are replaced rather than
+// modified if any change.
+func normalizeMessage(m Message, is *ISupport) Message {
+	indices := normalizedParams[upperCommand(m.Command)]
+	var params []string
+	for _, i := range indices {
+		if i >= len(m.Params) {
+			break
+		}
+		folded := is.Fold(m.Params[i])
+		if folded == m.Params[i] {
+			continue
+		}
+		if params == nil {
+			params = append(make([]string, 0, len(m.Params)), m.Params...)
+		}
+		params[i] = folded
+	}
+	if params != nil {
+		m.Params = params
+	}
+	return m
+}
@@ -0,0 +1,41 @@
+package ircmessage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var normalizeTests = []struct {
+	casemapping string
+	in          string
+	expected    []string
+}{
+	{"", ":N!u@h PRIVMSG #Chan[1] :Hello World", []string{"#chan{1}", "Hello World"}},
+	{"ascii", ":N!u@h PRIVMSG #Chan[1] :Hello World", []string{"#chan[1]", "Hello World"}},
+	{"", "JOIN #A,#B KeyA,KeyB", []string{"#a,#b", "KeyA,KeyB"}},
+	{"", ":s KICK #Chan Nick :Bye Now", []string{"#chan", "nick", "Bye Now"}},
+	{"", ":s 353 Me = #Chan :@Nick Other", []string{"Me", "=", "#chan", "@Nick Other"}},
+	{"", ":s 352 Me #Chan User Host Server Nick H :0 Real", []string{"Me", "#chan", "User", "Host", "Server", "nick", "H", "0 Real"}},
+	{"", "privmsg @#Chan :Hi", []string{"@#chan", "Hi"}},
+	{"", "QUIT :Gone Away", []string{"Gone Away"}},
+	{"", "KICK", nil},
+}
+
+func TestScannerNormalize(t *testing.T) {
+	for i, tt := range normalizeTests {
+		is := NewISupport()
+		if tt.casemapping != "" {
+			is.Update(Message{Command: "005", Params: []string{"me", "CASEMAPPING=" + tt.casemapping, "are supported"}})
+		}
+		s := NewScanner(strings.NewReader(tt.in + "\r\n"))
+		s.ReuseStorage()
+		s.Normalize(is)
+		if !s.Scan() {
+			t.Fatalf("%d. %v", i, s.Err())
+		}
+		if m := s.Message(); !reflect.DeepEqual(m.Params, tt.expected) {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, m.Params)
+		}
+	}
+}
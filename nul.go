@@ -0,0 +1,41 @@
+package ircmessage
+
+import "bytes"
+
+// NULPolicy determines how a Scanner treats NUL bytes within a line. The RFCs
+// forbid them, but they occur in real traffic.
+type NULPolicy int
+
+const (
+	// NULPass keeps NUL bytes as part of the message. This is the default.
+	NULPass NULPolicy = iota
+	// NULReject treats a line containing a NUL byte as malformed.
+	NULReject
+	// NULTruncate discards everything from the first NUL byte to the end
+	// of the line, as many C servers effectively do. The Raw field still
+	// holds the line as received.
+	NULTruncate
+)
+
+// applyNULPolicy enforces the profile's NUL policy on the components of the
+// message read into rawBuf.
+func (s *Scanner) applyNULPolicy(tags, prefix, command, params *span) error {
+	if s.profile.NUL == NULPass {
+		return nil
+	}
+	i := bytes.IndexByte(s.rawBuf, 0)
+	if i < 0 {
+		return nil
+	}
+	if s.profile.NUL == NULReject {
+		return ErrMessageMalformed
+	}
+	for _, sp := range []*span{tags, prefix, command, params} {
+		sp.start = min(sp.start, i)
+		sp.end = min(sp.end, i)
+	}
+	if command.start == command.end {
+		return ErrMessageMalformed
+	}
+	return nil
+}
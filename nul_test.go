@@ -0,0 +1,38 @@
+package ircmessage
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var nulTests = []struct {
+	in       string
+	policy   NULPolicy
+	expected Message
+	err      error
+}{
+	{"PRIVMSG #c :a\x00b", NULPass, Message{Command: "PRIVMSG", Params: []string{"#c", "a\x00b"}}, nil},
+	{"PRIVMSG #c :a\x00b", NULReject, Message{}, ErrMessageMalformed},
+	{"PRIVMSG #c :a\x00b", NULTruncate, Message{Command: "PRIVMSG", Params: []string{"#c", "a"}}, nil},
+	{"PRIVMSG #c\x00 :ab", NULTruncate, Message{Command: "PRIVMSG", Params: []string{"#c"}}, nil},
+	{":n\x00ick PRIVMSG #c :ab", NULTruncate, Message{}, ErrMessageMalformed},
+	{"PRIVMSG #c :ab", NULReject, Message{Command: "PRIVMSG", Params: []string{"#c", "ab"}}, nil},
+}
+
+func TestScannerNULPolicy(t *testing.T) {
+	for i, tt := range nulTests {
+		s := NewScanner(strings.NewReader(tt.in + "\r\n"))
+		s.SetProfile(Profile{NUL: tt.policy})
+		if s.Scan() {
+			tt.expected.Raw = tt.in + "\r\n"
+		}
+		if !errors.Is(s.Err(), tt.err) {
+			t.Errorf("%d. expecting error %v, got %v", i, tt.err, s.Err())
+		}
+		if m := s.Message(); !reflect.DeepEqual(m, tt.expected) {
+			t.Errorf("%d. expecting %#v, got %#v", i, tt.expected, m)
+		}
+	}
+}
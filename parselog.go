@@ -0,0 +1,122 @@
+package ircmessage
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime"
+)
+
+// logChunkSize is the approximate number of bytes parsed by each worker.
+var logChunkSize int64 = 1 << 20
+
+type logChunk struct {
+	msgs []Message
+	err  error
+}
+
+// ParseLog parses a log of raw IRC traffic of the given size, such as one
+// recorded with Scanner.Tee. The log is split into chunks on line boundaries
+// which are parsed concurrently by up to GOMAXPROCS workers.
+//
+// fn is called from a single goroutine with each message in the order it
+// appears in the log. Parsing stops at the first error, including one
+// returned by fn, and that error is returned.
+func ParseLog(r io.ReaderAt, size int64, fn func(Message) error) error {
+	workers, chunkSize := runtime.GOMAXPROCS(0), logChunkSize
+	order := make(chan chan logChunk, workers)
+	done := make(chan struct{})
+	defer func() {
+		// Wait for outstanding workers so that r is no longer in use
+		// once ParseLog returns.
+		close(done)
+		for res := range order {
+			<-res
+		}
+	}()
+	go func() {
+		defer close(order)
+		for off := int64(0); off < size; {
+			end, err := nextLineBoundary(r, off+chunkSize, size)
+			res := make(chan logChunk, 1)
+			select {
+			case order <- res:
+			case <-done:
+				return
+			}
+			if err != nil {
+				res <- logChunk{err: err}
+				return
+			}
+			go func(off, end int64) {
+				res <- parseLogChunk(io.NewSectionReader(r, off, end-off))
+			}(off, end)
+			off = end
+		}
+	}()
+	for res := range order {
+		chunk := <-res
+		for _, m := range chunk.msgs {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+		if chunk.err != nil {
+			return chunk.err
+		}
+	}
+	return nil
+}
+
+// ParseLogFiles calls ParseLog for each of the named files in turn.
+func ParseLogFiles(fn func(Message) error, names ...string) error {
+	for _, name := range names {
+		if err := parseLogFile(name, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseLogFile(name string, fn func(Message) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return ParseLog(f, fi.Size(), fn)
+}
+
+// nextLineBoundary returns the offset just past the first newline at or
+// after pos, or size if there is none.
+func nextLineBoundary(r io.ReaderAt, pos, size int64) (int64, error) {
+	var buf [512]byte
+	for pos < size {
+		n, err := r.ReadAt(buf[:], pos)
+		if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+			return pos + int64(i) + 1, nil
+		}
+		pos += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return size, nil
+}
+
+func parseLogChunk(r io.Reader) logChunk {
+	var c logChunk
+	s := NewScanner(r)
+	for s.Scan() {
+		c.msgs = append(c.msgs, s.Message())
+	}
+	c.err = s.Err()
+	return c
+}
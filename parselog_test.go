@@ -0,0 +1,79 @@
+package ircmessage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testLog(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, ":nick!u@h PRIVMSG #chan :line %d\r\n", i)
+	}
+	return b.String()
+}
+
+func TestParseLog(t *testing.T) {
+	defer func(n int64) { logChunkSize = n }(logChunkSize)
+	logChunkSize = 100
+	log := testLog(1000)
+	var i int
+	err := ParseLog(strings.NewReader(log), int64(len(log)), func(m Message) error {
+		if expected := fmt.Sprintf("line %d", i); m.Params[1] != expected {
+			return fmt.Errorf("expecting %q, got %q", expected, m.Params[1])
+		}
+		i++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != 1000 {
+		t.Errorf("expecting 1000 messages, got %d", i)
+	}
+}
+
+func TestParseLogErrors(t *testing.T) {
+	defer func(n int64) { logChunkSize = n }(logChunkSize)
+	logChunkSize = 100
+	log := testLog(100) + "@a=b=c FOO\r\n" + testLog(100)
+	var n int
+	err := ParseLog(strings.NewReader(log), int64(len(log)), func(Message) error {
+		n++
+		return nil
+	})
+	if !errors.Is(err, ErrMessageMalformed) {
+		t.Errorf("expecting error %v, got %v", ErrMessageMalformed, err)
+	}
+	if n != 100 {
+		t.Errorf("expecting 100 messages before the error, got %d", n)
+	}
+	stop := errors.New("stop")
+	err = ParseLog(strings.NewReader(log), int64(len(log)), func(Message) error { return stop })
+	if err != stop {
+		t.Errorf("expecting error %v, got %v", stop, err)
+	}
+}
+
+func TestParseLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	var names []string
+	for i := 0; i < 2; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("%d.log", i))
+		if err := os.WriteFile(name, []byte(testLog(10)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	var n int
+	if err := ParseLogFiles(func(Message) error { n++; return nil }, names...); err != nil {
+		t.Fatal(err)
+	}
+	if n != 20 {
+		t.Errorf("expecting 20 messages, got %d", n)
+	}
+}
@@ -0,0 +1,54 @@
+package ircmessage
+
+// A Stage transforms a message, returning false to drop it. Stages must not
+// modify the Tags or Params of the message they are given, which may be
+// shared, and should instead replace them.
+type Stage func(Message) (Message, bool)
+
+// Pipeline is a sequence of stages applied in order.
+type Pipeline []Stage
+
+// Apply passes m through each stage in turn, stopping if one drops it.
+func (p Pipeline) Apply(m Message) (Message, bool) {
+	for _, stage := range p {
+		var ok bool
+		if m, ok = stage(m); !ok {
+			return Message{}, false
+		}
+	}
+	return m, true
+}
+
+// Filter returns a Stage that drops messages for which keep returns false.
+func Filter(keep func(Message) bool) Stage {
+	return func(m Message) (Message, bool) {
+		return m, keep(m)
+	}
+}
+
+// SetTag returns a Stage that sets the tag key to value on every message.
+func SetTag(key, value string) Stage {
+	return func(m Message) (Message, bool) {
+		tags := make(map[string]string, len(m.Tags)+1)
+		for k, v := range m.Tags {
+			tags[k] = v
+		}
+		tags[key] = value
+		m.Tags = tags
+		return m, true
+	}
+}
+
+// SetPipeline sets p to be applied to every message scanned. Messages that
+// p drops are skipped by Scan, although they are still counted in Stats and
+// written to any Tee. SetPipeline must be called before the first call to
+// Scan.
+func (s *Scanner) SetPipeline(p Pipeline) {
+	s.pipeline = p
+}
+
+// SetPipeline sets p to be applied to every message before it is encoded.
+// Encode returns nil without writing anything for messages that p drops.
+func (e *Encoder) SetPipeline(p Pipeline) {
+	e.pipeline = p
+}
@@ -0,0 +1,53 @@
+package ircmessage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	upper := func(m Message) (Message, bool) {
+		m.Params = []string{m.Params[0], strings.ToUpper(m.Params[1])}
+		return m, true
+	}
+	p := Pipeline{
+		Filter(func(m Message) bool { return m.Command == "PRIVMSG" }),
+		upper,
+		SetTag("seen", "1"),
+	}
+	in := "PING :x\r\n:n PRIVMSG #c :hi\r\n:n NOTICE #c :no\r\n:n PRIVMSG #c :there\r\n"
+	s := NewScanner(strings.NewReader(in))
+	s.SetPipeline(p)
+	var got []string
+	for s.Scan() {
+		m := s.Message()
+		got = append(got, m.Tags["seen"]+m.Params[1])
+	}
+	if s.Err() != nil {
+		t.Fatal(s.Err())
+	}
+	if expected := "1HI,1THERE"; strings.Join(got, ",") != expected {
+		t.Errorf("expecting %s, got %s", expected, strings.Join(got, ","))
+	}
+	if st := s.Stats(); st.Messages != 4 {
+		t.Errorf("expecting 4 messages counted, got %d", st.Messages)
+	}
+
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.SetPipeline(p)
+	if err := e.Encode(Message{Command: "PING", Params: []string{"x"}}); err != nil {
+		t.Fatal(err)
+	}
+	orig := Message{Tags: map[string]string{"a": "b"}, Command: "PRIVMSG", Params: []string{"#c", "hi"}}
+	if err := e.Encode(orig); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "@a=b;seen=1 PRIVMSG #c HI\r\n"; buf.String() != expected {
+		t.Errorf("expecting %q, got %q", expected, buf.String())
+	}
+	if len(orig.Tags) != 1 || orig.Params[1] != "hi" {
+		t.Errorf("pipeline modified the original message: %v", orig)
+	}
+}
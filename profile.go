@@ -0,0 +1,312 @@
+package ircmessage
+
+import "strings"
+
+// Profile selects how strictly messages are held to the IRC grammar, so that
+// lenient clients and strict servers can share a Scanner and Encoder. The
+// zero value is as lenient as the defaults.
+type Profile struct {
+	Name   string
+	Limits Limits
+	// NoTags rejects messages carrying an IRCv3 tag section.
+	NoTags bool
+	// StrictPrefix requires a prefix to be a servername, or a nickname
+	// optionally followed by !user and @host.
+	StrictPrefix bool
+	// LegacyNicks applies the RFC 1459 nickname grammar to strict
+	// prefixes, which requires nicknames to begin with a letter.
+	LegacyNicks bool
+	// StrictCommand requires a command to be letters or a three digit
+	// numeric.
+	StrictCommand bool
+	// NUL determines how the Scanner treats NUL bytes. The Encoder
+	// always rejects them.
+	NUL NULPolicy
+	// Spaces determines how the Scanner treats runs of spaces between
+	// the components of a message. The Encoder always writes one space.
+	Spaces SpacePolicy
+	// BadTags determines how the Scanner treats malformed tags.
+	BadTags TagPolicy
+	// StrictEscapes makes the Scanner treat a tag value containing an
+	// invalid escape sequence as a malformed tag, rather than dropping
+	// the backslash as the spec directs.
+	StrictEscapes bool
+	// NoControls rejects control characters in the prefix, params and
+	// tag values, other than the formatting codes used for bold, colour
+	// and the like, and the CTCP delimiter.
+	NoControls bool
+	// NoPrefix rejects messages carrying a prefix, which clients must
+	// not send to a server.
+	NoPrefix bool
+	// ClientTags rejects tags other than those a client may send to a
+	// server: client-only tags, prefixed with '+', and the label, batch
+	// and draft/multiline-concat tags.
+	ClientTags bool
+	// UpperCommand makes the Scanner convert commands to upper case.
+	UpperCommand bool
+}
+
+// Predefined profiles.
+var (
+	// RFC1459 follows RFC 1459: no tags, at most 15 parameters, and
+	// strict prefixes and commands.
+	RFC1459 = Profile{
+		Name:          "rfc1459",
+		Limits:        Limits{Tags: maxMessageSize, Body: maxMessageSize, MaxParams: 15},
+		NoTags:        true,
+		StrictPrefix:  true,
+		LegacyNicks:   true,
+		StrictCommand: true,
+	}
+	// RFC2812 follows RFC 2812, which differs from RFC 1459 in allowing
+	// nicknames to begin with a special character.
+	RFC2812 = Profile{
+		Name:          "rfc2812",
+		Limits:        Limits{Tags: maxMessageSize, Body: maxMessageSize, MaxParams: 15},
+		NoTags:        true,
+		StrictPrefix:  true,
+		StrictCommand: true,
+	}
+	// Modern follows the current state of IRC as described by
+	// https://modern.ircdocs.horse: tags up to the size servers may
+	// send, strict commands, and prefixes and parameter counts as
+	// found in practice.
+	Modern = Profile{
+		Name:          "modern",
+		Limits:        ServerLimits,
+		StrictCommand: true,
+	}
+	// Twitch suits Twitch chat, which sends tag sections beyond the IRCv3
+	// limit, bodies of up to 500 characters of UTF-8 text besides the
+	// command, and its own commands such as USERNOTICE. Prefixes such as
+	// tmi.twitch.tv and malformed tags are passed through rather than
+	// rejected.
+	Twitch = Profile{
+		Name:    "twitch",
+		Limits:  Limits{Tags: 16384, Body: 4096},
+		BadTags: TagsKeepRaw,
+	}
+)
+
+// Hardened returns a profile for parsing untrusted input, such as a server
+// reading from clients. It enforces the client size limits, at most 15
+// parameters and tag values of at most 512 bytes, and rejects malformed
+// tags, NUL bytes and control characters other than formatting codes.
+// Since every section of a message is bounded, so is the memory a Scanner
+// holds for any one message.
+func Hardened() Profile {
+	limits := ClientLimits
+	limits.MaxParams = 15
+	limits.TagValue = 512
+	return Profile{
+		Name:          "hardened",
+		Limits:        limits,
+		StrictPrefix:  true,
+		StrictCommand: true,
+		NUL:           NULReject,
+		BadTags:       TagsFail,
+		NoControls:    true,
+	}
+}
+
+// ServerSide returns a profile for an ircd reading from its clients. Besides
+// the rules of Hardened, it rejects messages with a prefix or with tags
+// only servers may send, such as time and account, and converts commands
+// to upper case.
+func ServerSide() Profile {
+	p := Hardened()
+	p.Name = "server"
+	p.NoPrefix = true
+	p.ClientTags = true
+	p.UpperCommand = true
+	return p
+}
+
+// SetProfile sets the profile the Scanner enforces, including its limits.
+// It must be called before the first call to Scan.
+func (s *Scanner) SetProfile(p Profile) {
+	s.profile = p
+	s.limits = p.Limits.orDefault()
+}
+
+// SetProfile sets the profile the Encoder enforces, including its limits.
+func (e *Encoder) SetProfile(p Profile) {
+	e.profile = p
+	e.limits = p.Limits.orDefault()
+}
+
+// check returns an error if m breaks the rules of the profile. Size limits
+// are checked separately.
+func (p *Profile) check(m Message) error {
+	switch {
+	case p.NoTags && len(m.Tags) > 0, p.ClientTags && hasServerTags(m.Tags):
+		return ErrBadTag
+	case p.NoPrefix && m.Prefix != "":
+		return ErrBadPrefix
+	case p.StrictPrefix && m.Prefix != "" && !validPrefix(m.Prefix, p.LegacyNicks):
+		return ErrBadPrefix
+	case p.StrictCommand && !validCommand(m.Command):
+		return ErrBadCommand
+	case p.NoControls && hasControls(m):
+		return ErrMessageMalformed
+	}
+	return nil
+}
+
+// hasServerTags reports whether tags holds a tag that clients may not send.
+func hasServerTags(tags map[string]string) bool {
+	for k := range tags {
+		if isServerTag(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientSendableTags are the tags without a '+' prefix that clients may
+// send, as specified by the capabilities defining them.
+var clientSendableTags = map[string]bool{
+	"label":   true,
+	"batch":   true,
+	tagConcat: true,
+}
+
+func isServerTag(key string) bool {
+	return !strings.HasPrefix(key, "+") && !clientSendableTags[key]
+}
+
+// hasControls reports whether the prefix, params or tag values of m
+// contain a forbidden control character.
+func hasControls(m Message) bool {
+	if hasControl(m.Prefix) {
+		return true
+	}
+	for _, p := range m.Params {
+		if hasControl(p) {
+			return true
+		}
+	}
+	for _, v := range m.Tags {
+		if hasControl(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasControl reports whether s contains a control character other than a
+// formatting code or the CTCP delimiter.
+func hasControl(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if b := s[i]; b < 0x20 && b != '\x01' && !isFormatCode(b) || b == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+func validCommand(c string) bool {
+	if len(c) == 3 && isDigit(c[0]) && isDigit(c[1]) && isDigit(c[2]) {
+		return true
+	}
+	for i := 0; i < len(c); i++ {
+		if !isLetter(c[i]) {
+			return false
+		}
+	}
+	return c != ""
+}
+
+// validPrefix reports whether p is a servername or nick[[!user]@host] as
+// per RFC 2812, or RFC 1459 if legacy is set.
+func validPrefix(p string, legacy bool) bool {
+	nick, host, hasHost := cutLast(p, '@')
+	nick, user, hasUser := cutFirst(nick, '!')
+	if hasUser && (!hasHost || !validUser(user)) || hasHost && !validHost(host) {
+		return false
+	}
+	if !hasUser && !hasHost && validServerName(p) {
+		return true
+	}
+	return validNick(nick, legacy)
+}
+
+func cutFirst(s string, b byte) (before, after string, found bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == b {
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+func cutLast(s string, b byte) (before, after string, found bool) {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == b {
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+func isLetter(b byte) bool { return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' }
+
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+
+// isNickSpecial reports whether b is one of the special characters
+// permitted in nicknames.
+func isNickSpecial(b byte) bool {
+	switch b {
+	case '[', ']', '\\', '`', '_', '^', '{', '|', '}':
+		return true
+	}
+	return false
+}
+
+func validNick(n string, legacy bool) bool {
+	if n == "" || !isLetter(n[0]) && (legacy || !isNickSpecial(n[0])) {
+		return false
+	}
+	for i := 1; i < len(n); i++ {
+		if !isLetter(n[i]) && !isDigit(n[i]) && !isNickSpecial(n[i]) && n[i] != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func validUser(u string) bool {
+	for i := 0; i < len(u); i++ {
+		switch u[i] {
+		case 0, '\r', '\n', ' ', '@':
+			return false
+		}
+	}
+	return u != ""
+}
+
+// validHost accepts hostnames, IPv4 and IPv6 addresses, and the cloaks
+// used by many networks, which may contain slashes.
+func validHost(h string) bool {
+	for i := 0; i < len(h); i++ {
+		b := h[i]
+		if !isLetter(b) && !isDigit(b) && b != '-' && b != '.' && b != ':' && b != '/' && b != '_' {
+			return false
+		}
+	}
+	return h != ""
+}
+
+func validServerName(s string) bool {
+	dot := false
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		switch {
+		case b == '.':
+			dot = true
+		case !isLetter(b) && !isDigit(b) && b != '-':
+			return false
+		}
+	}
+	return dot
+}
@@ -0,0 +1,147 @@
+package ircmessage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var profileTests = []struct {
+	in                       string
+	rfc1459, rfc2812, modern error
+}{
+	{":irc.example.com 001 nick :Welcome", nil, nil, nil},
+	{":nick!user@host/cloak PRIVMSG #c :hi", nil, nil, nil},
+	{":[bot]!~u@127.0.0.1 PRIVMSG #c :hi", ErrMessageMalformed, nil, nil},
+	{":nick!u PRIVMSG #c :hi", ErrMessageMalformed, ErrMessageMalformed, nil},
+	{":9nick PRIVMSG #c :hi", ErrMessageMalformed, ErrMessageMalformed, nil},
+	{"@a=b PING", ErrMessageMalformed, ErrMessageMalformed, nil},
+	{"PR1VMSG #c :hi", ErrMessageMalformed, ErrMessageMalformed, ErrMessageMalformed},
+	{"1234 x", ErrMessageMalformed, ErrMessageMalformed, ErrMessageMalformed},
+	{"FOO 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16", ErrMessageMalformed, ErrMessageMalformed, nil},
+}
+
+func TestScannerProfiles(t *testing.T) {
+	for i, tt := range profileTests {
+		for _, c := range []struct {
+			p   Profile
+			err error
+		}{{RFC1459, tt.rfc1459}, {RFC2812, tt.rfc2812}, {Modern, tt.modern}} {
+			s := NewScanner(strings.NewReader(tt.in + "\r\n"))
+			s.SetProfile(c.p)
+			s.Scan()
+			if !errors.Is(s.Err(), c.err) {
+				t.Errorf("%d. %s: expecting error %v, got %v", i, c.p.Name, c.err, s.Err())
+			}
+		}
+	}
+}
+
+func TestEncoderProfile(t *testing.T) {
+	e := NewEncoder(io.Discard)
+	e.SetProfile(RFC2812)
+	if err := e.Encode(Message{Tags: map[string]string{"a": "b"}, Command: "PING"}); err != ErrMessageMalformed {
+		t.Errorf("expecting error %v, got %v", ErrMessageMalformed, err)
+	}
+	if err := e.Encode(Message{Prefix: "nick!user@host", Command: "PING"}); err != nil {
+		t.Errorf("expecting no error, got %v", err)
+	}
+}
+
+var hardenedTests = []struct {
+	in  string
+	err error
+}{
+	{"PRIVMSG #c :\x02bold\x0f \x0304red\x03 \x01ACTION waves\x01", nil},
+	{"@+draft/reply=" + strings.Repeat("a", 512) + " PRIVMSG #c :hi", nil},
+	{"@+draft/reply=" + strings.Repeat("a", 513) + " PRIVMSG #c :hi", ErrTagTooLong},
+	{"@+a=" + strings.Repeat("b", 4092) + " PING", ErrLineTooLong},
+	{"@a=b=c PING", ErrBadTag},
+	{"PRIVMSG #c :a\x00b", ErrMessageMalformed},
+	{"PRIVMSG #c :a\x07b", ErrMessageMalformed},
+	{"PRIVMSG #c :a\x7fb", ErrMessageMalformed},
+	{"@a=x\\ny PING", ErrMessageMalformed},
+	{"FOO 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16", ErrTooManyParams},
+	{"PR1VMSG #c :hi", ErrBadCommand},
+}
+
+func TestScannerHardened(t *testing.T) {
+	for i, tt := range hardenedTests {
+		s := NewScanner(strings.NewReader(tt.in + "\r\n"))
+		s.SetProfile(Hardened())
+		s.Scan()
+		if !errors.Is(s.Err(), tt.err) {
+			t.Errorf("%d. expecting error %v, got %v", i, tt.err, s.Err())
+		}
+	}
+}
+
+var twitchTests = []string{
+	"@badge-info=subscriber/8;badges=subscriber/6,premium/1;color=#0D4200;display-name=Ronni;emotes=25:0-4,12-16/1902:6-10;id=b34ccfc7-4977-403a-8a94-33c6bac34fb8;mod=0;room-id=1337;subscriber=1;tmi-sent-ts=1507246572675;turbo=1;user-id=1337;user-type=global_mod :ronni!ronni@ronni.tmi.twitch.tv PRIVMSG #ronni :Kappa Keepo Kappa",
+	"@emote-only=0;followers-only=-1;r9k=0;room-id=12345678;slow=0;subs-only=0 :tmi.twitch.tv ROOMSTATE #bar",
+	"@room-id=12345678;target-user-id=87654321;tmi-sent-ts=1642715756806 :tmi.twitch.tv CLEARCHAT #dallas :ronni",
+	"@badges=staff/1;msg-id=resub;msg-param-cumulative-months=6;system-msg=ronni\\shas\\ssubscribed\\sfor\\s6\\smonths! :tmi.twitch.tv USERNOTICE #dallas :Great stream -- keep it up!",
+	"@custom=" + strings.Repeat("x", 6000) + " :tmi.twitch.tv USERNOTICE #c :" + strings.Repeat("é", 500),
+	":jtv MODE #c +o nick",
+}
+
+func TestScannerTwitch(t *testing.T) {
+	for i, in := range twitchTests {
+		s := NewScanner(strings.NewReader(in + "\r\n"))
+		s.SetProfile(Twitch)
+		if !s.Scan() {
+			t.Errorf("%d. %v", i, s.Err())
+		}
+	}
+}
+
+var serverSideTests = []struct {
+	in      string
+	command string
+	err     error
+}{
+	{"privmsg #c :hi", "PRIVMSG", nil},
+	{"@+draft/reply=1;label=a;batch=b TagMsg #c", "TAGMSG", nil},
+	{":nick!u@h PRIVMSG #c :hi", "", ErrBadPrefix},
+	{"@time=2026-10-16T12:00:00.000Z PRIVMSG #c :hi", "", ErrBadTag},
+	{"@account=me PRIVMSG #c :hi", "", ErrBadTag},
+	{"@+x=" + strings.Repeat("y", 4000) + " PRIVMSG #c :hi", "", ErrTagTooLong},
+	{"@+x=" + strings.Repeat("y", 500) + ";+z=" + strings.Repeat("y", 500) + " PRIVMSG #c :hi", "PRIVMSG", nil},
+}
+
+func TestScannerServerSide(t *testing.T) {
+	for i, tt := range serverSideTests {
+		s := NewScanner(strings.NewReader(tt.in + "\r\n"))
+		s.SetProfile(ServerSide())
+		s.Scan()
+		if !errors.Is(s.Err(), tt.err) || s.Message().Command != tt.command {
+			t.Errorf("%d. expecting %q %v, got %q %v", i, tt.command, tt.err, s.Message().Command, s.Err())
+		}
+	}
+}
+
+func TestScannerServerSideMultiline(t *testing.T) {
+	msgs, err := MultilineBatch("abc", "PRIVMSG", "#c", "hello world", 24, MultilineLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf []byte
+	for _, m := range msgs {
+		if buf, err = AppendMessage(buf, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !strings.Contains(string(buf), tagConcat) {
+		t.Fatalf("expecting a concatenated line, got %q", buf)
+	}
+	s := NewScanner(strings.NewReader(string(buf)))
+	s.SetProfile(ServerSide())
+	n := 0
+	for s.Scan() {
+		n++
+	}
+	if s.Err() != nil || n != len(msgs) {
+		t.Errorf("expecting %d messages, got %d %v", len(msgs), n, s.Err())
+	}
+}
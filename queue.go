@@ -0,0 +1,137 @@
+package ircmessage
+
+import "sync"
+
+// Queue is an outgoing message queue for busy clients. Control messages
+// jump ahead of queued chat traffic so that, for example, a PONG is never
+// stuck behind a backlog of PRIVMSGs, and the remaining traffic is
+// interleaved fairly between targets.
+//
+// A Queue is safe for concurrent use. It is typically drained by a single
+// goroutine writing to a RateLimitedWriter.
+type Queue struct {
+	mu      sync.Mutex
+	cond    sync.Cond
+	urgent  []Message
+	targets map[string][]Message
+	quit    []Message // Sent once everything else has been.
+	order   []string  // Targets with pending messages, in round-robin order.
+	size    int
+	closed  bool
+}
+
+// NewQueue returns an empty Queue.
+func NewQueue() *Queue {
+	q := &Queue{targets: make(map[string][]Message)}
+	q.cond.L = &q.mu
+	return q
+}
+
+// isControl reports whether m is a connection control message that should
+// be sent ahead of other traffic.
+func isControl(m Message) bool {
+	switch upperCommand(m.Command) {
+	case "PING", "PONG", "CAP", "AUTHENTICATE":
+		return true
+	}
+	return false
+}
+
+// Push adds m to the queue. Control messages (PING, PONG, CAP and
+// AUTHENTICATE) are placed ahead of all other traffic, and a QUIT behind
+// it, so that queued messages are sent before disconnecting.
+func (q *Queue) Push(m Message) {
+	if isControl(m) {
+		q.PushUrgent(m)
+		return
+	}
+	if HasCommand(m, "QUIT") {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if !q.closed {
+			q.quit = append(q.quit, m)
+			q.size++
+			q.cond.Signal()
+		}
+		return
+	}
+	var target string
+	if len(m.Params) > 0 {
+		target = m.Params[0]
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	if len(q.targets[target]) == 0 {
+		q.order = append(q.order, target)
+	}
+	q.targets[target] = append(q.targets[target], m)
+	q.size++
+	q.cond.Signal()
+}
+
+// PushUrgent adds m ahead of all queued traffic other than previously
+// pushed urgent messages, for messages such as critical MODE changes.
+func (q *Queue) PushUrgent(m Message) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	q.urgent = append(q.urgent, m)
+	q.size++
+	q.cond.Signal()
+}
+
+// Pop removes and returns the next message to send, blocking until one is
+// available. It returns false once the queue has been closed and drained.
+func (q *Queue) Pop() (Message, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.size == 0 {
+		if q.closed {
+			return Message{}, false
+		}
+		q.cond.Wait()
+	}
+	q.size--
+	if len(q.urgent) > 0 {
+		m := q.urgent[0]
+		q.urgent = q.urgent[1:]
+		return m, true
+	}
+	if len(q.order) == 0 {
+		m := q.quit[0]
+		q.quit = q.quit[1:]
+		return m, true
+	}
+	target := q.order[0]
+	q.order = q.order[1:]
+	pending := q.targets[target]
+	m := pending[0]
+	if len(pending) == 1 {
+		delete(q.targets, target)
+	} else {
+		q.targets[target] = pending[1:]
+		q.order = append(q.order, target)
+	}
+	return m, true
+}
+
+// Len returns the number of queued messages.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// Close stops the queue from accepting further messages and wakes any
+// blocked Pop calls once the remaining messages have been drained.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
@@ -0,0 +1,37 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+	q := NewQueue()
+	msg := func(target, text string) Message {
+		return Message{Command: "PRIVMSG", Params: []string{target, text}}
+	}
+	q.Push(msg("#a", "a1"))
+	q.Push(Message{Command: "QUIT", Params: []string{"bye"}})
+	q.Push(msg("#a", "a2"))
+	q.Push(msg("#b", "b1"))
+	q.Push(msg("#a", "a3"))
+	q.Push(Message{Command: "PONG", Params: []string{"server"}})
+	q.PushUrgent(Message{Command: "MODE", Params: []string{"#a", "+b", "spammer"}})
+	q.Close()
+	q.Push(msg("#a", "dropped"))
+	if q.Len() != 7 {
+		t.Errorf("expecting 7 queued messages, got %d", q.Len())
+	}
+	var got []string
+	for {
+		m, ok := q.Pop()
+		if !ok {
+			break
+		}
+		got = append(got, m.Params[len(m.Params)-1])
+	}
+	expected := []string{"server", "spammer", "a1", "b1", "a2", "a3", "bye"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expecting order %v, got %v", expected, got)
+	}
+}
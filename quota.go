@@ -0,0 +1,23 @@
+package ircmessage
+
+import "errors"
+
+// ErrQuotaExceeded is returned by a Scanner when the memory it holds for a
+// connection exceeds the quota set with SetQuota.
+var ErrQuotaExceeded = errors.New("memory quota exceeded")
+
+// SetQuota bounds the bytes the Scanner buffers for the message being
+// read, counting its tags, its body and the unterminated remainder of the
+// line, to n. Unlike Limits, which bound each section of a message, the
+// quota bounds their total, protecting servers from clients that trickle
+// in large partial lines. The Scanner's fixed 4096 byte read buffer is not
+// included. A quota of zero, the default, means no quota. SetQuota must be
+// called before the first call to Scan.
+func (s *Scanner) SetQuota(n int) {
+	s.quota = n
+}
+
+// overQuota reports whether the Scanner holds more than its quota.
+func (s *Scanner) overQuota() bool {
+	return s.quota > 0 && len(s.rawBuf)+len(s.arena) > s.quota
+}
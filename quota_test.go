@@ -0,0 +1,34 @@
+package ircmessage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var quotaTests = []struct {
+	in    string
+	reuse bool
+	err   error
+}{
+	{"@a=b :n PRIVMSG #c :hi\r\n", false, nil},
+	{"@a=" + strings.Repeat("b", 40) + " :n PRIVMSG #c :hi\r\n", false, ErrQuotaExceeded},
+	{":n PRIVMSG #c :" + strings.Repeat("a", 40), false, ErrQuotaExceeded},
+	{"@a=" + strings.Repeat("\\s", 12) + " PING\r\n", false, nil},
+	{"@a=" + strings.Repeat("\\s", 12) + " PING\r\n", true, ErrQuotaExceeded},
+}
+
+func TestScannerQuota(t *testing.T) {
+	for i, tt := range quotaTests {
+		s := NewScanner(strings.NewReader(tt.in))
+		s.SetQuota(40)
+		if tt.reuse {
+			s.ReuseStorage()
+		}
+		for s.Scan() {
+		}
+		if !errors.Is(s.Err(), tt.err) {
+			t.Errorf("%d. expecting error %v, got %v", i, tt.err, s.Err())
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ircmessage
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitedWriter wraps an Encoder with a token bucket so that a client
+// does not exceed the flood limits of a server. Up to burst messages may be
+// written at once, after which one further message is permitted for each
+// refill interval that elapses. A burst of 5 with a refill of 2 seconds
+// matches the penalty model used by most ircds.
+//
+// A RateLimitedWriter is safe for concurrent use.
+type RateLimitedWriter struct {
+	enc    *Encoder
+	burst  int
+	refill time.Duration
+
+	mu    sync.Mutex
+	tat   time.Time // Theoretical arrival time of the next message.
+	clock Clock
+}
+
+// NewRateLimitedWriter returns a RateLimitedWriter that writes to enc.
+func NewRateLimitedWriter(enc *Encoder, burst int, refill time.Duration) *RateLimitedWriter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimitedWriter{
+		enc:    enc,
+		burst:  burst,
+		refill: refill,
+		clock:  SystemClock,
+	}
+}
+
+// SetClock sets the Clock the rate limit is measured by.
+func (w *RateLimitedWriter) SetClock(c Clock) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.clock = c
+}
+
+// Encode blocks until the rate limit permits another message and then
+// encodes m. Messages are written in the order Encode is called. A message
+// that fails to encode does not count against the limit.
+func (w *RateLimitedWriter) Encode(m Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if d := w.reserve(); d > 0 {
+		w.clock.Sleep(d)
+	}
+	err := w.enc.Encode(m)
+	if err != nil {
+		// Return the token.
+		w.tat = w.tat.Add(-w.refill)
+	}
+	return err
+}
+
+// reserve takes a token from the bucket, returning how long the caller
+// must wait before it is available.
+func (w *RateLimitedWriter) reserve() time.Duration {
+	now := w.clock.Now()
+	if w.tat.Before(now) {
+		w.tat = now
+	}
+	wait := w.tat.Sub(now) - time.Duration(w.burst-1)*w.refill
+	w.tat = w.tat.Add(w.refill)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
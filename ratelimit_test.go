@@ -0,0 +1,51 @@
+package ircmessage
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedWriter(t *testing.T) {
+	var now time.Time
+	var waits []time.Duration
+	w := NewRateLimitedWriter(NewEncoder(io.Discard), 5, 2*time.Second)
+	w.SetClock(funcClock{
+		now: func() time.Time { return now },
+		sleep: func(d time.Duration) {
+			waits = append(waits, d)
+			now = now.Add(d)
+		},
+	})
+	for i := 0; i < 7; i++ {
+		if err := w.Encode(Message{Command: "PING"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []time.Duration{2 * time.Second, 2 * time.Second}
+	if len(waits) != len(expected) || waits[0] != expected[0] || waits[1] != expected[1] {
+		t.Errorf("expecting waits %v after the burst, got %v", expected, waits)
+	}
+	// After idling the bucket should have refilled completely.
+	now = now.Add(time.Minute)
+	waits = nil
+	for i := 0; i < 5; i++ {
+		w.Encode(Message{Command: "PING"})
+	}
+	if len(waits) != 0 {
+		t.Errorf("expecting a full burst after idling, got waits %v", waits)
+	}
+	// Messages that fail to encode do not use up the burst.
+	now = now.Add(time.Minute)
+	for i := 0; i < 10; i++ {
+		if err := w.Encode(Message{}); err != ErrMessageMalformed {
+			t.Errorf("expecting %v, got %v", ErrMessageMalformed, err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		w.Encode(Message{Command: "PING"})
+	}
+	if len(waits) != 0 {
+		t.Errorf("expecting a full burst after failed messages, got waits %v", waits)
+	}
+}
@@ -0,0 +1,81 @@
+package ircmessage
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReadMarker records the time up to which a conversation has been read, as
+// per: https://ircv3.net/specs/extensions/read-marker
+type ReadMarker struct {
+	Target string
+	// Time is zero when the server has no marker for Target.
+	Time time.Time
+}
+
+// MarkRead returns a MARKREAD message setting the read marker of target to
+// t, or querying it if t is zero.
+func MarkRead(target string, t time.Time) Message {
+	m := Message{Command: "MARKREAD", Params: []string{target}}
+	if !t.IsZero() {
+		m.Params = append(m.Params, HistorySelector{Time: t}.String())
+	}
+	return m
+}
+
+// ParseMarkRead parses a MARKREAD message.
+func ParseMarkRead(m Message) (ReadMarker, error) {
+	if !HasCommand(m, "MARKREAD") || len(m.Params) == 0 {
+		return ReadMarker{}, ErrMessageMalformed
+	}
+	r := ReadMarker{Target: m.Params[0]}
+	if len(m.Params) < 2 || m.Params[1] == "*" {
+		return r, nil
+	}
+	v, ok := strings.CutPrefix(m.Params[1], "timestamp=")
+	if !ok {
+		return ReadMarker{}, ErrMessageMalformed
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return ReadMarker{}, ErrMessageMalformed
+	}
+	r.Time = t
+	return r, nil
+}
+
+// ReadMarkers tracks the read marker of each conversation, as synchronised
+// by the server between a user's clients. The zero value is ready to use,
+// and a ReadMarkers is safe for concurrent use.
+type ReadMarkers struct {
+	mu      sync.Mutex
+	markers map[string]time.Time
+}
+
+// Update applies a MARKREAD message from the server. It returns the new
+// marker and true if the marker moved forward; markers never move back.
+func (r *ReadMarkers) Update(m Message) (ReadMarker, bool) {
+	rm, err := ParseMarkRead(m)
+	if err != nil || rm.Time.IsZero() {
+		return ReadMarker{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.markers == nil {
+		r.markers = make(map[string]time.Time)
+	}
+	if !rm.Time.After(r.markers[rm.Target]) {
+		return ReadMarker{}, false
+	}
+	r.markers[rm.Target] = rm.Time
+	return rm, true
+}
+
+// Get returns the read marker of target, and whether one is known.
+func (r *ReadMarkers) Get(target string) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.markers[target]
+	return t, ok
+}
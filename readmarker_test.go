@@ -0,0 +1,46 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarkRead(t *testing.T) {
+	at := time.Date(2019, 1, 4, 14, 33, 26, 123e6, time.UTC)
+	m := MarkRead("#chan", at)
+	if !reflect.DeepEqual(m.Params, []string{"#chan", "timestamp=2019-01-04T14:33:26.123Z"}) {
+		t.Errorf("unexpected message %v", m)
+	}
+	if m := MarkRead("#chan", time.Time{}); len(m.Params) != 1 {
+		t.Errorf("expecting a query, got %v", m)
+	}
+	r, err := ParseMarkRead(m)
+	if err != nil || r.Target != "#chan" || !r.Time.Equal(at) {
+		t.Errorf("unexpected marker %+v (%v)", r, err)
+	}
+	if r, err := ParseMarkRead(Message{Command: "MARKREAD", Params: []string{"#chan", "*"}}); err != nil || !r.Time.IsZero() {
+		t.Errorf("expecting an unknown marker, got %+v (%v)", r, err)
+	}
+	if _, err := ParseMarkRead(Message{Command: "MARKREAD", Params: []string{"#chan", "bogus"}}); err != ErrMessageMalformed {
+		t.Errorf("expecting %v, got %v", ErrMessageMalformed, err)
+	}
+}
+
+func TestReadMarkers(t *testing.T) {
+	var r ReadMarkers
+	at := time.Date(2019, 1, 4, 14, 33, 26, 0, time.UTC)
+	if _, ok := r.Update(MarkRead("#chan", at)); !ok {
+		t.Error("expecting the first marker to be an update")
+	}
+	if _, ok := r.Update(MarkRead("#chan", at.Add(-time.Second))); ok {
+		t.Error("expecting an older marker to be ignored")
+	}
+	ev, ok := r.Update(MarkRead("#chan", at.Add(time.Second)))
+	if !ok || ev.Target != "#chan" {
+		t.Errorf("unexpected update %+v", ev)
+	}
+	if got, _ := r.Get("#chan"); !got.Equal(at.Add(time.Second)) {
+		t.Errorf("unexpected marker %v", got)
+	}
+}
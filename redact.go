@@ -0,0 +1,118 @@
+package ircmessage
+
+import "sync"
+
+// Redaction is a REDACT message, withdrawing an earlier message, as per:
+// https://ircv3.net/specs/extensions/message-redaction
+type Redaction struct {
+	Prefix string // The user redacting the message, when received.
+	Target string // The channel or user the message was sent to.
+	MsgID  string // The msgid tag of the redacted message.
+	Reason string
+}
+
+// Message returns the REDACT message for r.
+func (r Redaction) Message() Message {
+	m := Message{Command: "REDACT", Params: []string{r.Target, r.MsgID}}
+	if r.Reason != "" {
+		m.Params = append(m.Params, r.Reason)
+	}
+	return m
+}
+
+// ParseRedaction parses a REDACT message.
+func ParseRedaction(m Message) (Redaction, error) {
+	if !HasCommand(m, "REDACT") || len(m.Params) < 2 || m.Params[1] == "" {
+		return Redaction{}, ErrMessageMalformed
+	}
+	r := Redaction{Prefix: m.Prefix, Target: m.Params[0], MsgID: m.Params[1]}
+	if len(m.Params) > 2 {
+		r.Reason = m.Params[2]
+	}
+	return r, nil
+}
+
+// RedactionHandler is told of redactions, so that clients and loggers can
+// delete the redacted content or replace it with a tombstone.
+type RedactionHandler interface {
+	// Redacted is called for each redaction with the message it
+	// withdraws, if that is still remembered. Otherwise found is false
+	// and the handler may look the message up by r.MsgID itself.
+	Redacted(r Redaction, original Message, found bool)
+}
+
+// RedactionHandlerFunc adapts a function to a RedactionHandler.
+type RedactionHandlerFunc func(r Redaction, original Message, found bool)
+
+// Redacted calls f.
+func (f RedactionHandlerFunc) Redacted(r Redaction, original Message, found bool) {
+	f(r, original, found)
+}
+
+// RedactionTracker remembers recent messages by their msgid tag so that
+// REDACT messages can be matched to the messages they withdraw.
+//
+// A RedactionTracker is safe for concurrent use.
+type RedactionTracker struct {
+	mu       sync.Mutex
+	size     int
+	handler  RedactionHandler
+	messages map[string]Message
+	order    []string // Remembered ids, oldest first.
+}
+
+// NewRedactionTracker returns a RedactionTracker that remembers the last
+// size messages and passes redactions to h.
+func NewRedactionTracker(size int, h RedactionHandler) *RedactionTracker {
+	if size < 1 {
+		size = 1
+	}
+	return &RedactionTracker{size: size, handler: h, messages: make(map[string]Message)}
+}
+
+// Handle remembers PRIVMSG, NOTICE and TAGMSG messages with a msgid tag, and
+// passes REDACT messages to the handler, forgetting the messages they
+// withdraw. It may be used as a HandlerFunc.
+func (t *RedactionTracker) Handle(m Message) {
+	if r, err := ParseRedaction(m); err == nil {
+		t.mu.Lock()
+		original, found := t.messages[r.MsgID]
+		if found {
+			delete(t.messages, r.MsgID)
+			for i, id := range t.order {
+				if id == r.MsgID {
+					t.order = append(t.order[:i:i], t.order[i+1:]...)
+					break
+				}
+			}
+		}
+		t.mu.Unlock()
+		t.handler.Redacted(r, original, found)
+		return
+	}
+	id := m.Tags["msgid"]
+	if id == "" || !HasCommand(m, "PRIVMSG") && !HasCommand(m, "NOTICE") && !HasCommand(m, "TAGMSG") {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.messages[id]; !ok {
+		t.order = append(t.order, id)
+	}
+	t.messages[id] = m.Detach()
+	for len(t.order) > t.size {
+		delete(t.messages, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+// Tombstone returns a copy of m, a redacted PRIVMSG or NOTICE, with its text
+// replaced by placeholder, for display in place of the original.
+func Tombstone(m Message, placeholder string) Message {
+	m = m.Detach()
+	m.Raw = ""
+	if len(m.Params) > 1 && (HasCommand(m, "PRIVMSG") || HasCommand(m, "NOTICE")) {
+		m.Params[len(m.Params)-1] = placeholder
+	}
+	return m
+}
@@ -0,0 +1,86 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+var redactionTests = []struct {
+	in       Message
+	expected Redaction
+	err      error
+}{
+	{
+		Message{Prefix: "nick!user@host", Command: "REDACT", Params: []string{"#chan", "abc", "oops"}},
+		Redaction{Prefix: "nick!user@host", Target: "#chan", MsgID: "abc", Reason: "oops"},
+		nil,
+	},
+	{Message{Command: "redact", Params: []string{"nick", "abc"}}, Redaction{Target: "nick", MsgID: "abc"}, nil},
+	{Message{Command: "REDACT", Params: []string{"#chan"}}, Redaction{}, ErrMessageMalformed},
+	{Message{Command: "PRIVMSG", Params: []string{"#chan", "abc"}}, Redaction{}, ErrMessageMalformed},
+}
+
+func TestParseRedaction(t *testing.T) {
+	for i, tt := range redactionTests {
+		r, err := ParseRedaction(tt.in)
+		if err != tt.err || r != tt.expected {
+			t.Errorf("%d. expecting %+v %v, got %+v %v", i, tt.expected, tt.err, r, err)
+		}
+	}
+	m := Redaction{Target: "#chan", MsgID: "abc", Reason: "spam"}.Message()
+	if expected := []string{"#chan", "abc", "spam"}; m.Command != "REDACT" || !reflect.DeepEqual(m.Params, expected) {
+		t.Errorf("unexpected message %v", m)
+	}
+}
+
+func TestRedactionTracker(t *testing.T) {
+	type call struct {
+		msgID string
+		text  string
+		found bool
+	}
+	var calls []call
+	tr := NewRedactionTracker(2, RedactionHandlerFunc(func(r Redaction, m Message, found bool) {
+		c := call{msgID: r.MsgID, found: found}
+		if found {
+			c.text = Tombstone(m, "[deleted]").Params[1]
+		}
+		calls = append(calls, c)
+	}))
+	privmsg := func(id, text string) Message {
+		return Message{Tags: map[string]string{"msgid": id}, Prefix: "nick", Command: "PRIVMSG", Params: []string{"#chan", text}}
+	}
+	tr.Handle(privmsg("a", "first"))
+	tr.Handle(privmsg("b", "second"))
+	tr.Handle(Message{Command: "PRIVMSG", Params: []string{"#chan", "no id"}})
+	tr.Handle(Message{Command: "REDACT", Params: []string{"#chan", "b"}})
+	tr.Handle(privmsg("c", "third"))
+	tr.Handle(privmsg("d", "fourth"))
+	tr.Handle(Message{Command: "REDACT", Params: []string{"#chan", "a"}})
+	tr.Handle(Message{Command: "REDACT", Params: []string{"#chan", "d"}})
+	expected := []call{{"b", "[deleted]", true}, {"a", "", false}, {"d", "[deleted]", true}}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expecting %v, got %v", expected, calls)
+	}
+}
+
+func TestRedactionTrackerReadd(t *testing.T) {
+	var found []bool
+	tr := NewRedactionTracker(2, RedactionHandlerFunc(func(r Redaction, m Message, ok bool) {
+		found = append(found, ok)
+	}))
+	privmsg := func(id string) Message {
+		return Message{Tags: map[string]string{"msgid": id}, Command: "PRIVMSG", Params: []string{"#chan", id}}
+	}
+	tr.Handle(privmsg("a"))
+	tr.Handle(Message{Command: "REDACT", Params: []string{"#chan", "a"}})
+	// The same id arriving again, followed by one newer message, must
+	// leave both within the window of 2.
+	tr.Handle(privmsg("a"))
+	tr.Handle(privmsg("b"))
+	tr.Handle(Message{Command: "REDACT", Params: []string{"#chan", "a"}})
+	tr.Handle(Message{Command: "REDACT", Params: []string{"#chan", "b"}})
+	if expected := []bool{true, true, true}; !reflect.DeepEqual(found, expected) {
+		t.Errorf("expecting %v, got %v", expected, found)
+	}
+}
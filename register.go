@@ -0,0 +1,131 @@
+package ircmessage
+
+import "errors"
+
+var (
+	// ErrNicknameUnavailable is returned during registration when the
+	// server rejects every nickname on offer.
+	ErrNicknameUnavailable = errors.New("no nickname available")
+	// ErrNoNickname is returned by Registration.Start when no nickname is
+	// given, or one of those given is empty.
+	ErrNoNickname = errors.New("no nickname to register")
+)
+
+// Registration performs the connection registration sequence as a client.
+// It emits CAP, PASS, NICK and USER in the correct order, falls back to
+// alternative nicknames when the server rejects one, and reports when the
+// server has welcomed the client.
+//
+// Like CapNegotiator it does no I/O: every message from the server is passed
+// to Handle, and the messages it returns must be sent in order.
+type Registration struct {
+	Password string
+	// Nicks lists the nicknames to try, in order of preference.
+	Nicks    []string
+	User     string
+	RealName string
+	// Caps, if set, negotiates capabilities during registration.
+	Caps *CapNegotiator
+	// SASL, if set, authenticates during registration. Its
+	// CapNegotiator is used and Caps is ignored.
+	SASL *SASLClient
+
+	nick       int
+	current    string // Nickname confirmed by RPL_WELCOME.
+	welcomed   bool
+	registered bool
+	isupport   []string
+}
+
+// Start returns the messages that begin registration. It returns
+// ErrNoNickname, and no messages, if Nicks is empty or holds an empty
+// nickname.
+func (r *Registration) Start() ([]Message, error) {
+	if len(r.Nicks) == 0 {
+		return nil, ErrNoNickname
+	}
+	for _, n := range r.Nicks {
+		if n == "" {
+			return nil, ErrNoNickname
+		}
+	}
+	var out []Message
+	if caps := r.caps(); caps != nil {
+		out = append(out, caps.Start())
+	}
+	if r.Password != "" {
+		out = append(out, Message{Command: "PASS", Params: []string{r.Password}})
+	}
+	realName := r.RealName
+	if realName == "" {
+		realName = r.User
+	}
+	return append(out,
+		Message{Command: "NICK", Params: []string{r.Nick()}},
+		Message{Command: "USER", Params: []string{r.User, "0", "*", realName}},
+	), nil
+}
+
+func (r *Registration) caps() *CapNegotiator {
+	if r.SASL != nil {
+		return r.SASL.caps
+	}
+	return r.Caps
+}
+
+// Handle processes a message from the server, returning the messages to send
+// in response. It returns ErrNicknameUnavailable if every nickname has been
+// rejected before registration completed. A SASL failure does not stop
+// registration and is not reported here; see SASLClient.Done.
+func (r *Registration) Handle(m Message) ([]Message, error) {
+	var out []Message
+	switch {
+	case r.SASL != nil:
+		out, _ = r.SASL.Handle(m)
+	case r.Caps != nil:
+		out = r.Caps.Handle(m)
+	}
+	switch upperCommand(m.Command) {
+	case "433", "436", "432": // ERR_NICKNAMEINUSE, ERR_NICKCOLLISION, ERR_ERRONEUSNICKNAME
+		if r.welcomed {
+			break
+		}
+		r.nick++
+		if r.nick >= len(r.Nicks) {
+			return out, ErrNicknameUnavailable
+		}
+		out = append(out, Message{Command: "NICK", Params: []string{r.Nick()}})
+	case "001": // RPL_WELCOME
+		r.welcomed = true
+		if len(m.Params) > 0 {
+			r.current = m.Params[0]
+		}
+	case "005": // RPL_ISUPPORT
+		if len(m.Params) > 2 {
+			r.isupport = append(r.isupport, m.Params[1:len(m.Params)-1]...)
+		}
+		r.registered = r.welcomed
+	}
+	return out, nil
+}
+
+// Nick returns the nickname currently in use or being attempted.
+func (r *Registration) Nick() string {
+	if r.current != "" {
+		return r.current
+	}
+	if r.nick < len(r.Nicks) {
+		return r.Nicks[r.nick]
+	}
+	return ""
+}
+
+// Welcomed reports whether the server has sent RPL_WELCOME (001).
+func (r *Registration) Welcomed() bool { return r.welcomed }
+
+// Done reports whether registration is complete, that is the server has
+// sent RPL_WELCOME followed by at least one RPL_ISUPPORT (005).
+func (r *Registration) Done() bool { return r.registered }
+
+// ISupport returns the raw RPL_ISUPPORT tokens received so far.
+func (r *Registration) ISupport() []string { return r.isupport }
@@ -0,0 +1,59 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistration(t *testing.T) {
+	r := &Registration{
+		Password: "secret",
+		Nicks:    []string{"nick", "nick_"},
+		User:     "user",
+		RealName: "Real Name",
+		Caps:     NewCapNegotiator("multi-prefix"),
+	}
+	expected := []Message{
+		{Command: "CAP", Params: []string{"LS", "302"}},
+		{Command: "PASS", Params: []string{"secret"}},
+		{Command: "NICK", Params: []string{"nick"}},
+		{Command: "USER", Params: []string{"user", "0", "*", "Real Name"}},
+	}
+	if out, err := r.Start(); err != nil || !reflect.DeepEqual(out, expected) {
+		t.Errorf("expecting %v, got %v (%v)", expected, out, err)
+	}
+	out, _ := r.Handle(capMsg("*", "LS", "server-time"))
+	if len(out) != 1 || out[0].Params[0] != "END" {
+		t.Errorf("expecting CAP END, got %v", out)
+	}
+	out, err := r.Handle(Message{Command: "433", Params: []string{"*", "nick", "Nickname is already in use"}})
+	expected = []Message{{Command: "NICK", Params: []string{"nick_"}}}
+	if err != nil || !reflect.DeepEqual(out, expected) {
+		t.Errorf("expecting %v, got %v (%v)", expected, out, err)
+	}
+	r.Handle(Message{Command: "001", Params: []string{"nick_", "Welcome"}})
+	if !r.Welcomed() || r.Done() || r.Nick() != "nick_" {
+		t.Errorf("unexpected state after 001: welcomed %v, done %v, nick %q", r.Welcomed(), r.Done(), r.Nick())
+	}
+	r.Handle(Message{Command: "005", Params: []string{"nick_", "CHANTYPES=#", "NICKLEN=30", "are supported by this server"}})
+	if !r.Done() || !reflect.DeepEqual(r.ISupport(), []string{"CHANTYPES=#", "NICKLEN=30"}) {
+		t.Errorf("unexpected state after 005: done %v, isupport %v", r.Done(), r.ISupport())
+	}
+}
+
+func TestRegistrationNicksExhausted(t *testing.T) {
+	r := &Registration{Nicks: []string{"nick"}, User: "user"}
+	r.Start()
+	if _, err := r.Handle(Message{Command: "433", Params: []string{"*", "nick", "in use"}}); err != ErrNicknameUnavailable {
+		t.Errorf("expecting %v, got %v", ErrNicknameUnavailable, err)
+	}
+}
+
+func TestRegistrationNoNickname(t *testing.T) {
+	for i, nicks := range [][]string{nil, {"nick", ""}} {
+		r := &Registration{Nicks: nicks, User: "user"}
+		if out, err := r.Start(); err != ErrNoNickname || out != nil {
+			t.Errorf("%d. expecting %v, got %v %v", i, ErrNoNickname, out, err)
+		}
+	}
+}
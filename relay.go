@@ -0,0 +1,80 @@
+package ircmessage
+
+// Relay prepares messages received from an upstream server for relaying to
+// a downstream client, as a bouncer does: it replaces the prefix of the
+// server, adds a server-time tag to messages without one, and strips tags
+// that depend on a capability the client has not negotiated.
+type Relay struct {
+	// Prefix, if set, replaces the prefix of relayed messages sent by a
+	// server, as when a bouncer presents itself as the server. Messages
+	// from users keep their prefix.
+	Prefix string
+	// Caps lists the capabilities the downstream client negotiated.
+	Caps []string
+	// Clock supplies the time for messages received without a time tag.
+	// If nil, SystemClock is used.
+	Clock Clock
+}
+
+// Rewrite returns m prepared for the downstream client, without its Raw
+// field. The tags of m are not modified.
+//
+// The time, account and batch tags are kept only if the client negotiated
+// server-time, account-tag and batch respectively, and all other tags only
+// if it negotiated message-tags. The label tag is always removed, as it
+// answers a request of the bouncer rather than of the client.
+func (r Relay) Rewrite(m Message) Message {
+	m.Raw = ""
+	if p := ParsePrefix(m.Prefix); r.Prefix != "" && p != nil && p.IsServer {
+		m.Prefix = r.Prefix
+	}
+	var tags map[string]string
+	for k, v := range m.Tags {
+		if k != "label" && r.enabled(tagCap(k)) {
+			if tags == nil {
+				tags = make(map[string]string, len(m.Tags)+1)
+			}
+			tags[k] = v
+		}
+	}
+	if _, ok := tags["time"]; !ok && r.enabled("server-time") {
+		if tags == nil {
+			tags = make(map[string]string, 1)
+		}
+		clock := r.Clock
+		if clock == nil {
+			clock = SystemClock
+		}
+		tags["time"] = clock.Now().UTC().Format(serverTimeLayout)
+	}
+	m.Tags = tags
+	return m
+}
+
+// Append rewrites m as Rewrite does and appends its encoding to dst, as
+// AppendMessage does.
+func (r Relay) Append(dst []byte, m Message) ([]byte, error) {
+	return AppendMessage(dst, r.Rewrite(m))
+}
+
+func (r Relay) enabled(capability string) bool {
+	for _, c := range r.Caps {
+		if CapEqual(c, capability) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagCap returns the capability that allows a server to send the tag key.
+func tagCap(key string) string {
+	switch key {
+	case "time":
+		return "server-time"
+	case "account":
+		return "account-tag"
+	case "batch":
+		return "batch"
+	}
+	return "message-tags"
+}
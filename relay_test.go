@@ -0,0 +1,59 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var relayTests = []struct {
+	caps     []string
+	in       Message
+	expected Message
+}{
+	{
+		nil,
+		Message{Raw: "x", Tags: map[string]string{"time": "t", "msgid": "1"}, Prefix: "nick!u@h", Command: "PRIVMSG", Params: []string{"#c", "hi"}},
+		Message{Prefix: "nick!u@h", Command: "PRIVMSG", Params: []string{"#c", "hi"}},
+	},
+	{
+		[]string{"server-time", "account-tag"},
+		Message{Tags: map[string]string{"account": "acct", "msgid": "1", "+typing": "active"}, Prefix: "nick!u@h", Command: "PRIVMSG", Params: []string{"#c", "hi"}},
+		Message{Tags: map[string]string{"account": "acct", "time": "2026-10-16T12:00:00.500Z"}, Prefix: "nick!u@h", Command: "PRIVMSG", Params: []string{"#c", "hi"}},
+	},
+	{
+		[]string{"message-tags", "server-time", "batch"},
+		Message{Tags: map[string]string{"time": "2020-01-01T00:00:00.000Z", "batch": "b", "label": "l", "+typing": "active"}, Prefix: "irc.example.com", Command: "NOTICE", Params: []string{"*", "hi"}},
+		Message{Tags: map[string]string{"time": "2020-01-01T00:00:00.000Z", "batch": "b", "+typing": "active"}, Prefix: "bouncer", Command: "NOTICE", Params: []string{"*", "hi"}},
+	},
+	{
+		[]string{"SERVER-TIME"},
+		Message{Command: "PING", Params: []string{"x"}},
+		Message{Tags: map[string]string{"time": "2026-10-16T12:00:00.500Z"}, Command: "PING", Params: []string{"x"}},
+	},
+	{
+		[]string{"message-tags", "labeled-response"},
+		Message{Tags: map[string]string{"label": "l", "msgid": "1"}, Prefix: "irc.example.com", Command: "PONG", Params: []string{"irc.example.com", "x"}},
+		Message{Tags: map[string]string{"msgid": "1"}, Prefix: "bouncer", Command: "PONG", Params: []string{"irc.example.com", "x"}},
+	},
+}
+
+func TestRelay(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 0, 0, 500000000, time.FixedZone("", 2*60*60))
+	clock := funcClock{now: func() time.Time { return now }}
+	for i, tt := range relayTests {
+		r := Relay{Prefix: "bouncer", Caps: tt.caps, Clock: clock}
+		tags := tt.in.Clone().Tags
+		got := r.Rewrite(tt.in)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%d. expecting %#v, got %#v", i, tt.expected, got)
+		}
+		if !reflect.DeepEqual(tt.in.Tags, tags) {
+			t.Errorf("%d. expecting the input tags to be unchanged, got %v", i, tt.in.Tags)
+		}
+	}
+	b, err := Relay{Caps: []string{"account-tag"}}.Append(nil, relayTests[1].in)
+	if expected := "@account=acct :nick!u@h PRIVMSG #c hi\r\n"; err != nil || string(b) != expected {
+		t.Errorf("expecting %q, got %q %v", expected, b, err)
+	}
+}
@@ -0,0 +1,56 @@
+package ircmessage
+
+// ChannelRename describes a channel being renamed, as per:
+// https://ircv3.net/specs/extensions/channel-rename
+type ChannelRename struct {
+	Prefix string // The user who renamed the channel, when received.
+	Old    string
+	New    string
+	Reason string
+}
+
+// Message returns the RENAME message requesting the rename.
+func (r ChannelRename) Message() Message {
+	m := Message{Command: "RENAME", Params: []string{r.Old, r.New}}
+	if r.Reason != "" {
+		m.Params = append(m.Params, r.Reason)
+	}
+	return m
+}
+
+// ParseRename parses a RENAME message.
+func ParseRename(m Message) (ChannelRename, error) {
+	if !HasCommand(m, "RENAME") || len(m.Params) < 2 {
+		return ChannelRename{}, ErrMessageMalformed
+	}
+	r := ChannelRename{Prefix: m.Prefix, Old: m.Params[0], New: m.Params[1]}
+	if len(m.Params) > 2 {
+		r.Reason = m.Params[2]
+	}
+	return r, nil
+}
+
+// RenameFailure is a FAIL reply to a RENAME command. Code is typically
+// CHANNEL_NAME_IN_USE or CANNOT_RENAME.
+type RenameFailure struct {
+	Code        string
+	Old         string
+	New         string
+	Description string
+}
+
+// ParseRenameFailure parses a FAIL RENAME message.
+func ParseRenameFailure(m Message) (RenameFailure, error) {
+	r, err := ParseStandardReply(m)
+	if err != nil || r.Type != "FAIL" || !CommandEqual(r.Command, "RENAME") {
+		return RenameFailure{}, ErrMessageMalformed
+	}
+	f := RenameFailure{Code: r.Code, Description: r.Description}
+	if len(r.Context) > 0 {
+		f.Old = r.Context[0]
+	}
+	if len(r.Context) > 1 {
+		f.New = r.Context[1]
+	}
+	return f, nil
+}
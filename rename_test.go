@@ -0,0 +1,36 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChannelRename(t *testing.T) {
+	r := ChannelRename{Old: "#old", New: "#new", Reason: "tidying up"}
+	m := r.Message()
+	if !reflect.DeepEqual(m.Params, []string{"#old", "#new", "tidying up"}) {
+		t.Errorf("unexpected message %v", m)
+	}
+	m.Prefix = "op!user@host"
+	parsed, err := ParseRename(m)
+	r.Prefix = m.Prefix
+	if err != nil || parsed != r {
+		t.Errorf("expecting %+v, got %+v (%v)", r, parsed, err)
+	}
+	if _, err := ParseRename(Message{Command: "RENAME", Params: []string{"#old"}}); err != ErrMessageMalformed {
+		t.Errorf("expecting %v, got %v", ErrMessageMalformed, err)
+	}
+}
+
+func TestParseRenameFailure(t *testing.T) {
+	m := Message{Command: "FAIL", Params: []string{"RENAME", "CHANNEL_NAME_IN_USE", "#old", "#new", "Channel already exists"}}
+	f, err := ParseRenameFailure(m)
+	expected := RenameFailure{Code: "CHANNEL_NAME_IN_USE", Old: "#old", New: "#new", Description: "Channel already exists"}
+	if err != nil || f != expected {
+		t.Errorf("expecting %+v, got %+v (%v)", expected, f, err)
+	}
+	m = Message{Command: "FAIL", Params: []string{"JOIN", "CHANNEL_NAME_IN_USE", "oops"}}
+	if _, err := ParseRenameFailure(m); err != ErrMessageMalformed {
+		t.Errorf("expecting %v for another command, got %v", ErrMessageMalformed, err)
+	}
+}
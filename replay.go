@@ -0,0 +1,79 @@
+package ircmessage
+
+import "time"
+
+// A MessageSource yields a sequence of messages, as a Scanner, LogReader or
+// JournalReader does.
+type MessageSource interface {
+	Scan() bool
+	Message() Message
+	Err() error
+}
+
+// Replayer paces the messages of a recording, such as a journal or a
+// stream captured with Tee, to reproduce the gaps between them given by
+// their server-time tags, for load testing and developing clients against
+// realistic traffic. Messages without a time tag are taken from a source
+// with a Time method, as a JournalReader has, and are otherwise passed on
+// at once, as are messages timed earlier than the one before.
+//
+// Pacing is against the time the replay started, so the time spent handling
+// messages does not accumulate as drift.
+type Replayer struct {
+	src   MessageSource
+	speed float64
+
+	started bool
+	last    time.Time // Recorded time of the last timed message.
+	due     time.Time // When the last timed message was due.
+	clock   Clock
+}
+
+// NewReplayer returns a Replayer that reads from src at the given speed, a
+// multiplier on the recorded rate: 2 replays twice as fast and 0.5 at half
+// speed. A speed of zero or less replays without delay.
+func NewReplayer(src MessageSource, speed float64) *Replayer {
+	return &Replayer{src: src, speed: speed, clock: SystemClock}
+}
+
+// SetClock sets the Clock the replay is paced by. It must be called before
+// the first call to Scan.
+func (r *Replayer) SetClock(c Clock) { r.clock = c }
+
+// Scan waits until the next message is due and then advances to it, with
+// the same semantics as Scanner.Scan.
+func (r *Replayer) Scan() bool {
+	if !r.src.Scan() {
+		return false
+	}
+	if r.speed <= 0 {
+		return true
+	}
+	t, ok := messageTime(r.src.Message())
+	if !ok {
+		if ts, isTimed := r.src.(interface{ Time() time.Time }); isTimed {
+			t, ok = ts.Time(), !ts.Time().IsZero()
+		}
+	}
+	if !ok {
+		return true
+	}
+	if !r.started {
+		r.started, r.last, r.due = true, t, r.clock.Now()
+		return true
+	}
+	if gap := t.Sub(r.last); gap > 0 {
+		r.due = r.due.Add(time.Duration(float64(gap) / r.speed))
+		r.last = t
+	}
+	if wait := r.due.Sub(r.clock.Now()); wait > 0 {
+		r.clock.Sleep(wait)
+	}
+	return true
+}
+
+// Message returns the most recent message returned by Scan.
+func (r *Replayer) Message() Message { return r.src.Message() }
+
+// Err returns the error of the underlying source.
+func (r *Replayer) Err() error { return r.src.Err() }
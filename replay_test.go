@@ -0,0 +1,59 @@
+package ircmessage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplayer(t *testing.T) {
+	in := "@time=2026-10-16T12:00:00.000Z PING a\r\n" +
+		"@time=2026-10-16T12:00:02.000Z PING b\r\n" +
+		"PING untimed\r\n" +
+		"@time=2026-10-16T12:00:01.000Z PING earlier\r\n" +
+		"@time=2026-10-16T12:00:06.000Z PING c\r\n"
+	now := time.Unix(0, 0)
+	var waits []time.Duration
+	r := NewReplayer(NewScanner(strings.NewReader(in)), 2)
+	r.SetClock(funcClock{
+		now: func() time.Time { return now },
+		sleep: func(d time.Duration) {
+			waits = append(waits, d)
+			now = now.Add(d)
+		},
+	})
+	n := 0
+	for r.Scan() {
+		n++
+		// Handling the message takes time, which the next wait absorbs.
+		now = now.Add(100 * time.Millisecond)
+	}
+	if r.Err() != nil || n != 5 {
+		t.Fatalf("expecting 5 messages, got %d and %v", n, r.Err())
+	}
+	expected := []time.Duration{900 * time.Millisecond, 1700 * time.Millisecond}
+	if !reflect.DeepEqual(waits, expected) {
+		t.Errorf("expecting waits %v, got %v", expected, waits)
+	}
+}
+
+func TestReplayerJournal(t *testing.T) {
+	in := "2026-10-16T12:00:00Z 8 PING a\r\n2026-10-16T12:00:03Z 8 PING b\r\n"
+	now := time.Unix(0, 0)
+	var waits []time.Duration
+	r := NewReplayer(NewJournalReader(strings.NewReader(in)), 1)
+	r.SetClock(funcClock{
+		now:   func() time.Time { return now },
+		sleep: func(d time.Duration) { waits = append(waits, d) },
+	})
+	for r.Scan() {
+	}
+	if len(waits) != 1 || waits[0] != 3*time.Second {
+		t.Errorf("expecting a wait of 3s, got %v", waits)
+	}
+	r = NewReplayer(NewJournalReader(strings.NewReader(in)), 0)
+	r.SetClock(funcClock{now: time.Now, sleep: func(d time.Duration) { t.Errorf("unexpected wait of %v at speed 0", d) }})
+	for r.Scan() {
+	}
+}
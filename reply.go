@@ -0,0 +1,27 @@
+package ircmessage
+
+import "strings"
+
+// ReplyTarget returns where a reply to m should be sent: the channel for
+// channel messages, including any STATUSMSG prefix so that the reply reaches
+// the same audience, and the sender's nick for private messages. Private
+// messages sent by ownNick, as seen with echo-message, reply to their
+// recipient. It returns an empty string if m has no target or sender.
+// A nil isupport uses the defaults described by ISupport.
+func (m Message) ReplyTarget(ownNick string, isupport *ISupport) string {
+	if len(m.Params) == 0 {
+		return ""
+	}
+	target := m.Params[0]
+	if isupport.IsChannel(strings.TrimLeft(target, isupport.StatusMsg())) {
+		return target
+	}
+	p := ParsePrefix(m.Prefix)
+	if p == nil || p.IsServer {
+		return ""
+	}
+	if isupport.Fold(p.Nickname) == isupport.Fold(ownNick) {
+		return target
+	}
+	return p.Nickname
+}
@@ -0,0 +1,30 @@
+package ircmessage
+
+import "testing"
+
+var replyTargetTests = []struct {
+	m        Message
+	expected string
+}{
+	{Message{Prefix: "a!u@h", Command: "PRIVMSG", Params: []string{"#chan", "hi"}}, "#chan"},
+	{Message{Prefix: "a!u@h", Command: "PRIVMSG", Params: []string{"@#chan", "hi ops"}}, "@#chan"},
+	{Message{Prefix: "a!u@h", Command: "PRIVMSG", Params: []string{"Me", "hi"}}, "a"},
+	{Message{Prefix: "me!u@h", Command: "PRIVMSG", Params: []string{"friend", "hi"}}, "friend"},
+	{Message{Prefix: "server.example.com", Command: "NOTICE", Params: []string{"me", "hi"}}, ""},
+	{Message{Command: "PING"}, ""},
+}
+
+func TestReplyTarget(t *testing.T) {
+	is := NewISupport()
+	is.Update(Message{Command: "005", Params: []string{"me", "STATUSMSG=@+", "are supported"}})
+	for i, tt := range replyTargetTests {
+		if got := tt.m.ReplyTarget("me", is); got != tt.expected {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, got)
+		}
+	}
+	// Without STATUSMSG, @#chan is not recognised as a channel.
+	m := replyTargetTests[1].m
+	if got := m.ReplyTarget("me", nil); got != "a" {
+		t.Errorf("expecting sender without STATUSMSG, got %q", got)
+	}
+}
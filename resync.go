@@ -0,0 +1,54 @@
+package ircmessage
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+)
+
+// SkipLine discards the rest of the line that stopped the scan, up to and
+// including the next CRLF, and clears the error so that scanning can
+// resume with the following line. If the offending line was read in full it
+// is not read past. Called while no error is pending, SkipLine discards the
+// next line of input.
+//
+// SkipLine only recovers from a malformed message or an exceeded quota.
+// Any other error, such as one from the underlying reader, is returned and
+// remains in place, as is io.EOF if the input ends before a CRLF.
+func (s *Scanner) SkipLine() error {
+	var pe *ParseError
+	if s.err != nil && !errors.As(s.err, &pe) && s.err != ErrQuotaExceeded {
+		return s.Err()
+	}
+	if s.err == nil || !bytes.HasSuffix(s.rawBuf, []byte("\r\n")) {
+		if err := s.discardLine(); err != nil {
+			s.err = err
+			return err
+		}
+	}
+	s.err = nil
+	return nil
+}
+
+// discardLine reads up to and including the next CRLF without buffering
+// the line. The bytes read are passed on to any tee and counted in the
+// stats.
+func (s *Scanner) discardLine() error {
+	cr := false
+	for {
+		b, err := s.src.ReadSlice('\n')
+		s.stats.bytes.Add(int64(len(b)))
+		if s.tee != nil && len(b) > 0 {
+			if _, teeErr := s.tee.Write(b); teeErr != nil {
+				return teeErr
+			}
+		}
+		switch {
+		case err == nil && (len(b) > 1 && b[len(b)-2] == '\r' || len(b) == 1 && cr):
+			return nil
+		case err != nil && err != bufio.ErrBufferFull:
+			return err
+		}
+		cr = len(b) > 0 && b[len(b)-1] == '\r'
+	}
+}
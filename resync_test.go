@@ -0,0 +1,69 @@
+package ircmessage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var skipLineTests = []struct {
+	in       string
+	expected []string
+}{
+	{"PING a\r\n@a=b\r\nPING b\r\n", []string{"a", "b"}},
+	{"PING a\r\n@a=b=c PING x\r\nPING b\r\n", []string{"a", "b"}},
+	{"PING a\r\n:n \r\nPING b\r\n", []string{"a", "b"}},
+	{"PING a\r\nPING :" + strings.Repeat("x", 5000) + "\r\nPING b\r\n", []string{"a", "b"}},
+}
+
+func TestScannerSkipLine(t *testing.T) {
+	for i, tt := range skipLineTests {
+		var tee bytes.Buffer
+		s := NewScanner(strings.NewReader(tt.in))
+		s.Tee(&tee)
+		var got []string
+		for {
+			for s.Scan() {
+				got = append(got, s.Message().Params[0])
+			}
+			if s.Err() == nil {
+				break
+			}
+			if err := s.SkipLine(); err != nil {
+				t.Fatalf("%d. %v", i, err)
+			}
+		}
+		if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, got)
+		}
+		if tee.String() != tt.in {
+			t.Errorf("%d. expecting tee %q, got %q", i, tt.in, tee.String())
+		}
+		if st := s.Stats(); st.Bytes != int64(len(tt.in)) || st.Malformed != 1 {
+			t.Errorf("%d. expecting %d bytes and 1 malformed, got %+v", i, len(tt.in), st)
+		}
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestScannerSkipLineErrors(t *testing.T) {
+	s := NewScanner(strings.NewReader("@a=b"))
+	s.Scan()
+	if err := s.SkipLine(); err != io.EOF {
+		t.Errorf("expecting %v, got %v", io.EOF, err)
+	}
+	if s.Scan() || s.Err() != nil {
+		t.Errorf("expecting scan to stop without error, got %v", s.Err())
+	}
+	failure := errors.New("failure")
+	s = NewScanner(errReader{failure})
+	s.Scan()
+	if err := s.SkipLine(); err != failure || s.Err() != failure {
+		t.Errorf("expecting %v to remain, got %v %v", failure, err, s.Err())
+	}
+}
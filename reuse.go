@@ -0,0 +1,80 @@
+package ircmessage
+
+import "unsafe"
+
+// ReuseStorage switches the Scanner to a mode in which the strings, Tags and
+// Params of every message share storage owned by the Scanner, so that
+// scanning does not allocate once the buffers have grown to fit the traffic.
+//
+// A message returned in this mode is only valid until the next call to
+// Scan, which overwrites it in place. This includes copies of the message
+// and any strings taken from it. Call Detach to obtain a message that can be
+// retained. ReuseStorage must be called before the first call to Scan.
+func (s *Scanner) ReuseStorage() {
+	s.reuse = true
+	s.tags = make(map[string]string)
+}
+
+func (s *Scanner) reuseMessage(tags, prefix, command, params span, hasTags bool) (Message, error) {
+	raw := unsafe.String(unsafe.SliceData(s.rawBuf), len(s.rawBuf))
+	msg := Message{
+		Raw:     raw,
+		Prefix:  raw[prefix.start:prefix.end],
+		Command: intern(raw[command.start:command.end]),
+	}
+	if s.profile.Spaces == SpacesPreserve {
+		s.params = appendParamsExact(s.params[:0], raw, command, params)
+	} else {
+		s.params = appendParams(s.params[:0], raw[params.start:params.end])
+	}
+	if len(s.params) > 0 {
+		msg.Params = s.params
+	}
+	if hasTags {
+		clear(s.tags)
+		if err := parseTags(s.tags, raw[tags.start:tags.end], &s.arena, s.profile.BadTags, s.profile.StrictEscapes); err != nil {
+			return Message{}, err
+		}
+		if s.overQuota() {
+			return Message{}, ErrQuotaExceeded
+		}
+		msg.Tags = s.tags
+	}
+	return s.finish(msg)
+}
+
+// Detach returns a copy of m that owns all of its storage, which is needed
+// to retain a message scanned by a Scanner using ReuseStorage. The strings
+// of the copy share a single allocation.
+func (m Message) Detach() Message {
+	n := len(m.Raw) + len(m.Prefix) + len(m.Command)
+	for k, v := range m.Tags {
+		n += len(k) + len(v)
+	}
+	for _, p := range m.Params {
+		n += len(p)
+	}
+	buf := make([]byte, 0, n)
+	own := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		start := len(buf)
+		buf = append(buf, s...)
+		return unsafe.String(&buf[start], len(s))
+	}
+	d := Message{Raw: own(m.Raw), Prefix: own(m.Prefix), Command: own(m.Command), Truncated: m.Truncated}
+	if m.Tags != nil {
+		d.Tags = make(map[string]string, len(m.Tags))
+		for k, v := range m.Tags {
+			d.Tags[own(k)] = own(v)
+		}
+	}
+	if m.Params != nil {
+		d.Params = make([]string, len(m.Params))
+		for i, p := range m.Params {
+			d.Params[i] = own(p)
+		}
+	}
+	return d
+}
@@ -0,0 +1,65 @@
+package ircmessage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScannerReuseStorage(t *testing.T) {
+	in := "@a=x\\sy;b=c :n PRIVMSG #c :hello\r\n@a=z\\sz :m NOTICE #d :there\r\n"
+	s := NewScanner(strings.NewReader(in))
+	s.ReuseStorage()
+	if !s.Scan() {
+		t.Fatal(s.Err())
+	}
+	first := s.Message()
+	detached := first.Detach()
+	expected := Message{
+		Raw:     "@a=x\\sy;b=c :n PRIVMSG #c :hello\r\n",
+		Tags:    map[string]string{"a": "x y", "b": "c"},
+		Prefix:  "n",
+		Command: "PRIVMSG",
+		Params:  []string{"#c", "hello"},
+	}
+	if !detached.Equal(expected) || detached.Raw != expected.Raw {
+		t.Errorf("expecting %v, got %v", expected, detached)
+	}
+	if !s.Scan() {
+		t.Fatal(s.Err())
+	}
+	second := s.Message()
+	if second.Tags["a"] != "z z" || second.Params[1] != "there" {
+		t.Errorf("unexpected second message %v", second)
+	}
+	// The first message now shares storage with the second.
+	if first.Params[0] != "#d" {
+		t.Errorf("expecting reused params, got %q", first.Params)
+	}
+	if !detached.Equal(expected) || detached.Raw != expected.Raw {
+		t.Errorf("detached message changed: %v", detached)
+	}
+}
+
+type repeatReader struct {
+	line string
+	off  int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		c := copy(p[n:], r.line[r.off:])
+		n += c
+		r.off = (r.off + c) % len(r.line)
+	}
+	return n, nil
+}
+
+func TestScannerReuseStorageAllocs(t *testing.T) {
+	s := NewScanner(&repeatReader{line: "@time=2017-09-26T00:00:00.000Z;msgid=a\\sb :nick!user@host PRIVMSG #chan :hello there\r\n"})
+	s.ReuseStorage()
+	s.Scan()
+	if n := testing.AllocsPerRun(100, func() { s.Scan() }); n != 0 {
+		t.Errorf("expecting no allocations, got %v", n)
+	}
+}
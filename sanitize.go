@@ -0,0 +1,87 @@
+package ircmessage
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SanitizeForDisplay makes s safe to show in a terminal or web page. ASCII
+// control characters other than formatting codes, including the CTCP
+// delimiter, are replaced by their Unicode control pictures, such as ␛ for
+// ESC, so that they are visible but inert. C1 control characters and
+// invalid UTF-8 are replaced by U+FFFD, and the bidirectional overrides and
+// isolates used to disguise text are removed. Formatting codes are kept for
+// the caller to render or strip.
+func SanitizeForDisplay(s string) string {
+	i := 0
+	for i < len(s) {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if !displaySafe(r, n) {
+			break
+		}
+		i += n
+	}
+	if i == len(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	b.WriteString(s[:i])
+	for i < len(s) {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case displaySafe(r, n):
+			b.WriteString(s[i : i+n])
+		case r < 0x20:
+			b.WriteRune(0x2400 + r)
+		case r == 0x7f:
+			b.WriteRune(0x2421)
+		case isBidiControl(r):
+		default:
+			b.WriteRune(utf8.RuneError)
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// SanitizeMessageForDisplay returns m with its prefix, params and tag
+// values passed through SanitizeForDisplay. Raw is left as received and
+// should not be displayed. The tags and params of m are not modified.
+func SanitizeMessageForDisplay(m Message) Message {
+	m.Prefix = SanitizeForDisplay(m.Prefix)
+	m.Command = SanitizeForDisplay(m.Command)
+	if m.Params != nil {
+		params := make([]string, len(m.Params))
+		for i, p := range m.Params {
+			params[i] = SanitizeForDisplay(p)
+		}
+		m.Params = params
+	}
+	if m.Tags != nil {
+		tags := make(map[string]string, len(m.Tags))
+		for k, v := range m.Tags {
+			tags[SanitizeForDisplay(k)] = SanitizeForDisplay(v)
+		}
+		m.Tags = tags
+	}
+	return m
+}
+
+// displaySafe reports whether the rune r, decoded from n bytes, may be
+// displayed as it is.
+func displaySafe(r rune, n int) bool {
+	switch {
+	case r < 0x20:
+		return isFormatCode(byte(r))
+	case r == 0x7f, r >= 0x80 && r <= 0x9f, r == utf8.RuneError && n == 1:
+		return false
+	}
+	return !isBidiControl(r)
+}
+
+// isBidiControl reports whether r is one of the explicit bidirectional
+// embeddings, overrides or isolates, which can reorder the text around them.
+func isBidiControl(r rune) bool {
+	return r >= 0x202a && r <= 0x202e || r >= 0x2066 && r <= 0x2069
+}
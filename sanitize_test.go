@@ -0,0 +1,53 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+var sanitizeTests = []struct {
+	in, expected string
+}{
+	{"hello", "hello"},
+	{"\x02bold\x0f \x0304red\x03 \x1ditalic", "\x02bold\x0f \x0304red\x03 \x1ditalic"},
+	{"\x1b[2Jcleared", "␛[2Jcleared"},
+	{"\x01ACTION waves\x01", "␁ACTION waves␁"},
+	{"a\x00b\x7fc\td", "a␀b␡c␉d"},
+	{"café ☺", "café ☺"},
+	{"bad \xff\xfe", "bad ��"},
+	{"csi \u009b2J", "csi �2J"},
+	{"evil\u202egpj.exe", "evilgpj.exe"},
+	{"\u2067isolated\u2069", "isolated"},
+	{"�", "�"},
+}
+
+func TestSanitizeForDisplay(t *testing.T) {
+	for i, tt := range sanitizeTests {
+		if s := SanitizeForDisplay(tt.in); s != tt.expected {
+			t.Errorf("%d. expecting %q, got %q", i, tt.expected, s)
+		}
+	}
+}
+
+func TestSanitizeMessageForDisplay(t *testing.T) {
+	m := Message{
+		Raw:     "x",
+		Tags:    map[string]string{"a": "\x1b"},
+		Prefix:  "n\x07!u@h",
+		Command: "PRIVMSG",
+		Params:  []string{"#c", "hi\x1b[0m"},
+	}
+	expected := Message{
+		Raw:     "x",
+		Tags:    map[string]string{"a": "␛"},
+		Prefix:  "n␇!u@h",
+		Command: "PRIVMSG",
+		Params:  []string{"#c", "hi␛[0m"},
+	}
+	if s := SanitizeMessageForDisplay(m); !reflect.DeepEqual(s, expected) {
+		t.Errorf("expecting %#v, got %#v", expected, s)
+	}
+	if m.Params[1] != "hi\x1b[0m" || m.Tags["a"] != "\x1b" {
+		t.Errorf("original message modified: %#v", m)
+	}
+}
@@ -0,0 +1,208 @@
+package ircmessage
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ErrSASLFailed is returned when SASL authentication fails with every
+// mechanism that was attempted, or is aborted.
+var ErrSASLFailed = errors.New("sasl authentication failed")
+
+// authenticateChunk is the maximum length of an AUTHENTICATE payload chunk.
+const authenticateChunk = 400
+
+// SASLMechanism is a client-side SASL mechanism. A mechanism that verifies
+// the server may also have a Done() bool method reporting whether it has
+// finished doing so; until it has, SASLClient treats success as failure.
+type SASLMechanism interface {
+	// Name returns the mechanism name, such as PLAIN.
+	Name() string
+	// Next returns the response to a server challenge. The challenge is
+	// empty for the server's initial "+" prompt.
+	Next(challenge []byte) ([]byte, error)
+}
+
+type saslPlain struct {
+	authzid, authcid, password string
+}
+
+// SASLPlain returns the PLAIN mechanism for the given credentials. Authzid
+// is usually empty.
+func SASLPlain(authzid, authcid, password string) SASLMechanism {
+	return saslPlain{authzid, authcid, password}
+}
+
+func (p saslPlain) Name() string { return "PLAIN" }
+
+func (p saslPlain) Next([]byte) ([]byte, error) {
+	return []byte(p.authzid + "\x00" + p.authcid + "\x00" + p.password), nil
+}
+
+type saslExternal struct{ authzid string }
+
+// SASLExternal returns the EXTERNAL mechanism, used with TLS client
+// certificates. Authzid is usually empty.
+func SASLExternal(authzid string) SASLMechanism { return saslExternal{authzid} }
+
+func (e saslExternal) Name() string { return "EXTERNAL" }
+
+func (e saslExternal) Next([]byte) ([]byte, error) { return []byte(e.authzid), nil }
+
+// AuthenticateMessages returns the AUTHENTICATE messages carrying payload,
+// base64 encoded and split into chunks as required by:
+// https://ircv3.net/specs/extensions/sasl-3.1
+func AuthenticateMessages(payload []byte) []Message {
+	enc := base64.StdEncoding.EncodeToString(payload)
+	var msgs []Message
+	for len(enc) >= authenticateChunk {
+		msgs = append(msgs, Message{Command: "AUTHENTICATE", Params: []string{enc[:authenticateChunk]}})
+		enc = enc[authenticateChunk:]
+	}
+	if enc == "" {
+		enc = "+"
+	}
+	return append(msgs, Message{Command: "AUTHENTICATE", Params: []string{enc}})
+}
+
+// SASLClient performs SASL authentication during connection registration.
+// It wraps a CapNegotiator, requesting the sasl capability and withholding
+// CAP END until authentication has succeeded or failed.
+//
+// Like CapNegotiator it does no I/O: every message from the server is passed
+// to Handle, and the messages it returns must be sent in order, typically
+// with Conn.WriteMessage.
+type SASLClient struct {
+	caps    *CapNegotiator
+	mechs   []SASLMechanism
+	offered []string // Mechanisms the server supports, if known.
+	current int      // Index into mechs of the mechanism in progress.
+	payload strings.Builder
+	started bool
+	done    bool
+	account string
+	err     error
+}
+
+// NewSASLClient returns a SASLClient that negotiates capabilities with caps
+// and attempts the given mechanisms in order of preference.
+func NewSASLClient(caps *CapNegotiator, mechs ...SASLMechanism) *SASLClient {
+	c := &SASLClient{caps: caps, mechs: mechs, current: -1}
+	if !caps.wants("sasl") {
+		caps.Wanted = append(caps.Wanted, "sasl")
+	}
+	caps.BeforeEnd = func(n *CapNegotiator) bool {
+		if !n.Enabled("sasl") || c.started {
+			return false
+		}
+		c.started = true
+		if v, _ := n.Available("sasl"); v != "" {
+			c.offered = strings.Split(v, ",")
+		}
+		return true
+	}
+	return c
+}
+
+// Handle processes a message from the server, returning the messages to
+// send in response. Once authentication has finished, Handle returns
+// ErrSASLFailed if it was unsuccessful; registration continues regardless.
+func (c *SASLClient) Handle(m Message) ([]Message, error) {
+	if c.done {
+		return c.caps.Handle(m), c.err
+	}
+	wasStarted := c.started
+	out := c.caps.Handle(m)
+	if c.started && !wasStarted {
+		return append(out, c.next()...), c.err
+	}
+	switch upperCommand(m.Command) {
+	case "AUTHENTICATE":
+		if c.current < 0 || len(m.Params) == 0 {
+			break
+		}
+		chunk := m.Params[0]
+		if chunk != "+" {
+			c.payload.WriteString(chunk)
+		}
+		if len(chunk) == authenticateChunk {
+			break
+		}
+		challenge, err := base64.StdEncoding.DecodeString(c.payload.String())
+		c.payload.Reset()
+		var resp []byte
+		if err == nil {
+			resp, err = c.mechs[c.current].Next(challenge)
+		}
+		if err != nil {
+			return append(out, Message{Command: "AUTHENTICATE", Params: []string{"*"}}), nil
+		}
+		out = append(out, AuthenticateMessages(resp)...)
+	case "900": // RPL_LOGGEDIN
+		if len(m.Params) > 2 {
+			c.account = m.Params[2]
+		}
+	case "903": // RPL_SASLSUCCESS
+		if c.current >= 0 && c.current < len(c.mechs) {
+			// A mechanism that verifies the server must have done so.
+			if m, ok := c.mechs[c.current].(interface{ Done() bool }); ok && !m.Done() {
+				out = append(out, c.finish(ErrSASLFailed)...)
+				break
+			}
+		}
+		out = append(out, c.finish(nil)...)
+	case "907": // ERR_SASLALREADY
+		out = append(out, c.finish(nil)...)
+	case "908": // RPL_SASLMECHS
+		if len(m.Params) > 1 {
+			c.offered = strings.Split(m.Params[1], ",")
+		}
+	case "904", "905": // ERR_SASLFAIL, ERR_SASLTOOLONG
+		if c.current >= 0 {
+			out = append(out, c.next()...)
+		}
+	case "906": // ERR_SASLABORTED
+		out = append(out, c.finish(ErrSASLFailed)...)
+	}
+	return out, c.err
+}
+
+// next starts the next mechanism supported by the server, or finishes with
+// an error if none remain.
+func (c *SASLClient) next() []Message {
+	c.payload.Reset()
+	for c.current++; c.current < len(c.mechs); c.current++ {
+		if name := c.mechs[c.current].Name(); c.supported(name) {
+			return []Message{{Command: "AUTHENTICATE", Params: []string{name}}}
+		}
+	}
+	return c.finish(ErrSASLFailed)
+}
+
+func (c *SASLClient) supported(name string) bool {
+	if len(c.offered) == 0 {
+		return true
+	}
+	for _, o := range c.offered {
+		if strings.EqualFold(o, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *SASLClient) finish(err error) []Message {
+	c.done = true
+	c.err = err
+	if c.caps.Done() {
+		return nil
+	}
+	return []Message{c.caps.Finish()}
+}
+
+// Done reports whether authentication has finished, successfully or not.
+func (c *SASLClient) Done() bool { return c.done }
+
+// Account returns the account name reported by the server on login.
+func (c *SASLClient) Account() string { return c.account }
@@ -0,0 +1,112 @@
+package ircmessage
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAuthenticateMessages(t *testing.T) {
+	if msgs := AuthenticateMessages(nil); len(msgs) != 1 || msgs[0].Params[0] != "+" {
+		t.Errorf("expecting a single + for an empty payload, got %v", msgs)
+	}
+	// 300 bytes encode to exactly 400 characters, which must be followed
+	// by a + to mark the end of the payload.
+	msgs := AuthenticateMessages([]byte(strings.Repeat("a", 300)))
+	if len(msgs) != 2 || len(msgs[0].Params[0]) != 400 || msgs[1].Params[0] != "+" {
+		t.Errorf("unexpected chunking %v", msgs)
+	}
+	msgs = AuthenticateMessages([]byte(strings.Repeat("a", 450)))
+	if len(msgs) != 2 || len(msgs[1].Params[0]) != 200 {
+		t.Errorf("unexpected chunking %v", msgs)
+	}
+}
+
+func TestSASLClient(t *testing.T) {
+	n := NewCapNegotiator()
+	c := NewSASLClient(n, SASLExternal(""), SASLPlain("", "user", "pass"))
+	n.Start()
+	steps := []struct {
+		in       Message
+		expected []Message
+		err      error
+	}{
+		{
+			capMsg("*", "LS", "sasl=PLAIN,EXTERNAL"),
+			[]Message{{Command: "CAP", Params: []string{"REQ", "sasl"}}},
+			nil,
+		},
+		{
+			capMsg("*", "ACK", "sasl"),
+			[]Message{{Command: "AUTHENTICATE", Params: []string{"EXTERNAL"}}},
+			nil,
+		},
+		{
+			Message{Command: "904", Params: []string{"*", "SASL authentication failed"}},
+			[]Message{{Command: "AUTHENTICATE", Params: []string{"PLAIN"}}},
+			nil,
+		},
+		{
+			Message{Command: "AUTHENTICATE", Params: []string{"+"}},
+			[]Message{{Command: "AUTHENTICATE", Params: []string{"AHVzZXIAcGFzcw=="}}},
+			nil,
+		},
+		{
+			Message{Command: "900", Params: []string{"nick", "nick!user@host", "account", "You are now logged in"}},
+			nil,
+			nil,
+		},
+		{
+			Message{Command: "903", Params: []string{"nick", "SASL authentication successful"}},
+			[]Message{{Command: "CAP", Params: []string{"END"}}},
+			nil,
+		},
+	}
+	for i, s := range steps {
+		out, err := c.Handle(s.in)
+		if err != s.err {
+			t.Errorf("%d. expecting error %v, got %v", i, s.err, err)
+		}
+		if !reflect.DeepEqual(out, s.expected) {
+			t.Errorf("%d. expecting %v, got %v", i, s.expected, out)
+		}
+	}
+	if !c.Done() || c.Account() != "account" {
+		t.Errorf("expecting to be logged in as account, got %q", c.Account())
+	}
+}
+
+func TestSASLClientNoMechanism(t *testing.T) {
+	n := NewCapNegotiator()
+	c := NewSASLClient(n, SASLPlain("", "user", "pass"))
+	n.Start()
+	c.Handle(capMsg("*", "LS", "sasl=EXTERNAL"))
+	out, err := c.Handle(capMsg("*", "ACK", "sasl"))
+	if err != ErrSASLFailed {
+		t.Errorf("expecting %v, got %v", ErrSASLFailed, err)
+	}
+	if len(out) != 1 || out[0].Params[0] != "END" {
+		t.Errorf("expecting CAP END, got %v", out)
+	}
+}
+
+func TestSASLClientUnverifiedSuccess(t *testing.T) {
+	n := NewCapNegotiator()
+	s := newTestScram("", nil)
+	c := NewSASLClient(n, s)
+	n.Start()
+	c.Handle(capMsg("*", "LS", "sasl=SCRAM-SHA-256"))
+	c.Handle(capMsg("*", "ACK", "sasl"))
+	c.Handle(Message{Command: "AUTHENTICATE", Params: []string{"+"}})
+	serverFirst := base64.StdEncoding.EncodeToString([]byte(scramServerFirst))
+	c.Handle(Message{Command: "AUTHENTICATE", Params: []string{serverFirst}})
+	// The server claims success without proving it knows the password.
+	out, err := c.Handle(Message{Command: "903", Params: []string{"nick", "SASL authentication successful"}})
+	if err != ErrSASLFailed {
+		t.Errorf("expecting %v, got %v", ErrSASLFailed, err)
+	}
+	if len(out) != 1 || out[0].Params[0] != "END" {
+		t.Errorf("expecting CAP END, got %v", out)
+	}
+}
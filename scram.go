@@ -0,0 +1,209 @@
+package ircmessage
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrSCRAMServer is returned by a SCRAM mechanism when the server's
+	// messages are malformed, or it fails to prove that it knows the
+	// password, which may mean it is an impostor.
+	ErrSCRAMServer = errors.New("scram: server failed verification")
+	// ErrSCRAMRejected is returned by a SCRAM mechanism when the server
+	// reports an error in its final message.
+	ErrSCRAMRejected = errors.New("scram: rejected by server")
+)
+
+// ChannelBinding returns the channel binding type and data for the TLS
+// connection carrying a session, such as "tls-exporter" and the result of
+// ExportKeyingMaterial("EXPORTER-Channel-Binding", nil, 32) on a
+// crypto/tls connection state.
+type ChannelBinding func() (kind string, data []byte, err error)
+
+// scramMaxIterations caps the iteration count a server may demand, so that
+// a hostile one cannot tie up the client computing the salted password.
+// Servers typically use a few thousand.
+const scramMaxIterations = 1 << 20
+
+type saslScram struct {
+	authzid, authcid, password string
+	binding                    ChannelBinding
+
+	step            int
+	nonce           string
+	gs2Header       string
+	clientFirstBare string
+	serverSignature []byte
+	verified        bool
+
+	testNonce string // Used in place of a random nonce by tests.
+}
+
+// SASLScramSHA256 returns the SCRAM-SHA-256 mechanism of RFC 7677 for the
+// given credentials. Unlike PLAIN it does not reveal the password to the
+// server, and it verifies that the server knows it. The password is used as
+// given, without SASLprep normalization. Authzid is usually empty.
+func SASLScramSHA256(authzid, authcid, password string) SASLMechanism {
+	return &saslScram{authzid: authzid, authcid: authcid, password: password}
+}
+
+// SASLScramSHA256Plus returns the SCRAM-SHA-256-PLUS mechanism, which binds
+// authentication to the TLS connection described by binding, so that it
+// cannot be relayed by a man in the middle.
+func SASLScramSHA256Plus(authzid, authcid, password string, binding ChannelBinding) SASLMechanism {
+	return &saslScram{authzid: authzid, authcid: authcid, password: password, binding: binding}
+}
+
+func (s *saslScram) Name() string {
+	if s.binding != nil {
+		return "SCRAM-SHA-256-PLUS"
+	}
+	return "SCRAM-SHA-256"
+}
+
+func (s *saslScram) Next(challenge []byte) ([]byte, error) {
+	if len(challenge) == 0 {
+		// The initial prompt, which begins a new exchange if the
+		// mechanism is reused.
+		s.step = 0
+	}
+	s.step++
+	switch s.step {
+	case 1:
+		return s.clientFirst()
+	case 2:
+		return s.clientFinal(string(challenge))
+	case 3:
+		return s.verify(string(challenge))
+	}
+	return nil, ErrSCRAMServer
+}
+
+// Done reports whether the server has proved that it knows the password,
+// completing the exchange.
+func (s *saslScram) Done() bool { return s.verified }
+
+func (s *saslScram) clientFirst() ([]byte, error) {
+	s.serverSignature, s.verified = nil, false
+	s.nonce = s.testNonce
+	if s.nonce == "" {
+		b := make([]byte, 18)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		s.nonce = base64.RawStdEncoding.EncodeToString(b)
+	}
+	// The GS2 header declares channel binding and the authorization
+	// identity.
+	s.gs2Header = "n,"
+	if s.binding != nil {
+		kind, _, err := s.binding()
+		if err != nil {
+			return nil, err
+		}
+		s.gs2Header = "p=" + kind + ","
+	}
+	if s.authzid != "" {
+		s.gs2Header += "a=" + scramName(s.authzid)
+	}
+	s.gs2Header += ","
+	s.clientFirstBare = "n=" + scramName(s.authcid) + ",r=" + s.nonce
+	return []byte(s.gs2Header + s.clientFirstBare), nil
+}
+
+func (s *saslScram) clientFinal(serverFirst string) ([]byte, error) {
+	attrs := scramAttributes(serverFirst)
+	nonce, salt64, iter := attrs["r"], attrs["s"], attrs["i"]
+	if !strings.HasPrefix(nonce, s.nonce) || len(nonce) == len(s.nonce) {
+		return nil, ErrSCRAMServer
+	}
+	salt, err := base64.StdEncoding.DecodeString(salt64)
+	if err != nil {
+		return nil, ErrSCRAMServer
+	}
+	iterations, err := strconv.Atoi(iter)
+	if err != nil || iterations < 1 || iterations > scramMaxIterations {
+		return nil, ErrSCRAMServer
+	}
+	cbind := []byte(s.gs2Header)
+	if s.binding != nil {
+		_, data, err := s.binding()
+		if err != nil {
+			return nil, err
+		}
+		cbind = append(cbind, data...)
+	}
+	withoutProof := "c=" + base64.StdEncoding.EncodeToString(cbind) + ",r=" + nonce
+	authMessage := []byte(s.clientFirstBare + "," + serverFirst + "," + withoutProof)
+
+	salted := pbkdf2SHA256([]byte(s.password), salt, iterations)
+	clientKey := hmacSHA256(salted, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	proof := hmacSHA256(storedKey[:], authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	s.serverSignature = hmacSHA256(hmacSHA256(salted, []byte("Server Key")), authMessage)
+	return []byte(withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof)), nil
+}
+
+func (s *saslScram) verify(serverFinal string) ([]byte, error) {
+	attrs := scramAttributes(serverFinal)
+	if _, ok := attrs["e"]; ok {
+		return nil, ErrSCRAMRejected
+	}
+	sig, err := base64.StdEncoding.DecodeString(attrs["v"])
+	if err != nil || !hmac.Equal(sig, s.serverSignature) {
+		return nil, ErrSCRAMServer
+	}
+	s.verified = true
+	return []byte{}, nil
+}
+
+// scramName escapes a username for a SCRAM message.
+func scramName(name string) string {
+	return strings.NewReplacer("=", "=3D", ",", "=2C").Replace(name)
+}
+
+// scramAttributes parses the comma separated a=value attributes of a SCRAM
+// message.
+func scramAttributes(msg string) map[string]string {
+	attrs := make(map[string]string)
+	for _, a := range strings.Split(msg, ",") {
+		if k, v, ok := strings.Cut(a, "="); ok && len(k) == 1 {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// pbkdf2SHA256 derives a key as long as one SHA-256 hash from password as
+// per RFC 8018, which SCRAM calls Hi.
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	h := hmac.New(sha256.New, password)
+	h.Write(salt)
+	h.Write([]byte{0, 0, 0, 1})
+	u := h.Sum(nil)
+	key := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		h.Reset()
+		h.Write(u)
+		u = h.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
@@ -0,0 +1,83 @@
+package ircmessage
+
+import "testing"
+
+// The example exchange from RFC 7677.
+const (
+	scramServerFirst = "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"
+	scramServerFinal = "v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="
+)
+
+func newTestScram(authzid string, binding ChannelBinding) *saslScram {
+	s := &saslScram{authzid: authzid, authcid: "user", password: "pencil", binding: binding}
+	s.testNonce = "rOprNGfwEbeRWgbNEkqO"
+	return s
+}
+
+func TestSASLScramSHA256(t *testing.T) {
+	s := newTestScram("", nil)
+	steps := []struct{ in, expected string }{
+		{"", "n,,n=user,r=rOprNGfwEbeRWgbNEkqO"},
+		{scramServerFirst, "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="},
+		{scramServerFinal, ""},
+	}
+	// The mechanism may be reused for a second exchange.
+	for j := 0; j < 2; j++ {
+		for i, step := range steps {
+			out, err := s.Next([]byte(step.in))
+			if err != nil || string(out) != step.expected {
+				t.Errorf("%d. expecting %q, got %q %v", i, step.expected, out, err)
+			}
+			if done := i == len(steps)-1; s.Done() != done {
+				t.Errorf("%d. expecting done to be %v", i, done)
+			}
+		}
+	}
+	if SASLScramSHA256("", "u", "p").Name() != "SCRAM-SHA-256" {
+		t.Error("unexpected mechanism name")
+	}
+}
+
+var scramFailureTests = []struct {
+	serverFirst, serverFinal string
+	err                      error
+}{
+	{"r=someoneelse,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096", "", ErrSCRAMServer},
+	{"r=rOprNGfwEbeRWgbNEkqO,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096", "", ErrSCRAMServer},
+	{"r=rOprNGfwEbeRWgbNEkqOabc,s=!,i=4096", "", ErrSCRAMServer},
+	{"r=rOprNGfwEbeRWgbNEkqOabc,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=0", "", ErrSCRAMServer},
+	{"r=rOprNGfwEbeRWgbNEkqOabc,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=2147483647", "", ErrSCRAMServer},
+	{scramServerFirst, "v=AAAA", ErrSCRAMServer},
+	{scramServerFirst, "e=invalid-proof", ErrSCRAMRejected},
+}
+
+func TestSASLScramFailure(t *testing.T) {
+	for i, tt := range scramFailureTests {
+		s := newTestScram("", nil)
+		s.Next(nil)
+		_, err := s.Next([]byte(tt.serverFirst))
+		if err == nil {
+			_, err = s.Next([]byte(tt.serverFinal))
+		}
+		if err != tt.err {
+			t.Errorf("%d. expecting %v, got %v", i, tt.err, err)
+		}
+	}
+}
+
+func TestSASLScramSHA256Plus(t *testing.T) {
+	binding := func() (string, []byte, error) { return "tls-exporter", []byte("data"), nil }
+	s := newTestScram("admin,1", binding)
+	if s.Name() != "SCRAM-SHA-256-PLUS" {
+		t.Errorf("unexpected mechanism name %q", s.Name())
+	}
+	out, _ := s.Next(nil)
+	if expected := "p=tls-exporter,a=admin=2C1,n=user,r=rOprNGfwEbeRWgbNEkqO"; string(out) != expected {
+		t.Errorf("expecting %q, got %q", expected, out)
+	}
+	out, _ = s.Next([]byte(scramServerFirst))
+	// base64("p=tls-exporter,a=admin=2C1,data")
+	if expected := "c=cD10bHMtZXhwb3J0ZXIsYT1hZG1pbj0yQzEsZGF0YQ==,"; string(out[:len(expected)]) != expected {
+		t.Errorf("expecting channel binding %q, got %q", expected, out)
+	}
+}
@@ -0,0 +1,118 @@
+package ircmessage
+
+// serverQuery returns a query of the server named by target, or of the
+// server the client is connected to if target is empty.
+func serverQuery(command, target string) Message {
+	m := Message{Command: command}
+	if target != "" {
+		m.Params = []string{target}
+	}
+	return m
+}
+
+// AdminRequest returns the ADMIN message asking for the administrative
+// contacts of the server target, or of the current server if it is empty.
+func AdminRequest(target string) Message { return serverQuery("ADMIN", target) }
+
+// InfoRequest returns the INFO message asking for a description of the
+// server target, or of the current server if it is empty.
+func InfoRequest(target string) Message { return serverQuery("INFO", target) }
+
+// VersionRequest returns the VERSION message asking for the version of the
+// server target, or of the current server if it is empty.
+func VersionRequest(target string) Message { return serverQuery("VERSION", target) }
+
+// AdminInfo is the reply to an ADMIN request.
+type AdminInfo struct {
+	Server    string // The server described, if the server names it.
+	Location1 string // Usually the location of the server.
+	Location2 string // Usually the institution running the server.
+	Email     string
+}
+
+// AdminCollector gathers the RPL_ADMINME (256), RPL_ADMINLOC1 (257) and
+// RPL_ADMINLOC2 (258) replies to ADMIN, returning them when RPL_ADMINEMAIL
+// (259), the last reply, arrives. The zero value is ready to use, and is not
+// safe for concurrent use.
+type AdminCollector struct {
+	info AdminInfo
+}
+
+// Add feeds a message to the collector. It reports whether the message was
+// an ADMIN reply, and returns the information once the replies have ended.
+func (c *AdminCollector) Add(m Message) (*AdminInfo, bool) {
+	if len(m.Params) < 2 {
+		return nil, false
+	}
+	text := m.Params[len(m.Params)-1]
+	switch m.Command {
+	case "256": // RPL_ADMINME
+		c.info = AdminInfo{}
+		if len(m.Params) > 2 {
+			c.info.Server = m.Params[1]
+		}
+	case "257": // RPL_ADMINLOC1
+		c.info.Location1 = text
+	case "258": // RPL_ADMINLOC2
+		c.info.Location2 = text
+	case "259": // RPL_ADMINEMAIL
+		info := c.info
+		info.Email = text
+		c.info = AdminInfo{}
+		return &info, true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// InfoCollector gathers the RPL_INFO (371) replies to INFO, returning their
+// lines when RPL_ENDOFINFO (374) arrives. The zero value is ready to use,
+// and is not safe for concurrent use.
+type InfoCollector struct {
+	lines []string
+}
+
+// Add feeds a message to the collector. It reports whether the message was
+// an INFO reply, and returns the lines once the replies have ended.
+func (c *InfoCollector) Add(m Message) ([]string, bool) {
+	switch m.Command {
+	case "371": // RPL_INFO
+		if len(m.Params) < 2 {
+			return nil, false
+		}
+		c.lines = append(c.lines, m.Params[len(m.Params)-1])
+	case "373": // RPL_INFOSTART, sent by some older servers.
+	case "374": // RPL_ENDOFINFO
+		lines := c.lines
+		c.lines = nil
+		if lines == nil {
+			lines = []string{}
+		}
+		return lines, true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// VersionInfo is the reply to a VERSION request.
+type VersionInfo struct {
+	Version  string // The server software and version, such as "solanum-1.0".
+	Server   string
+	Comments string
+}
+
+// ParseVersion parses RPL_VERSION (351), the reply to VERSION, which is
+// followed by RPL_ISUPPORT messages. It returns false for any other
+// message.
+func ParseVersion(m Message) (VersionInfo, bool) {
+	if m.Command != "351" || len(m.Params) < 3 {
+		return VersionInfo{}, false
+	}
+	v := VersionInfo{Version: m.Params[1], Server: m.Params[2]}
+	if len(m.Params) > 3 {
+		v.Comments = m.Params[3]
+	}
+	return v, true
+}
@@ -0,0 +1,63 @@
+package ircmessage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAdminCollector(t *testing.T) {
+	in := ":irc.example.org 256 me irc.example.org :Administrative info\r\n" +
+		":irc.example.org 257 me :Somewhere, Earth\r\n" +
+		":irc.example.org 258 me :Example Network\r\n" +
+		":irc.example.org 259 me :admin@example.org\r\n"
+	var (
+		c    AdminCollector
+		info *AdminInfo
+	)
+	s := NewScanner(strings.NewReader(in))
+	for s.Scan() {
+		i, ok := c.Add(s.Message())
+		if !ok {
+			t.Errorf("expecting %v to be consumed", s.Message())
+		}
+		if i != nil {
+			info = i
+		}
+	}
+	expected := &AdminInfo{Server: "irc.example.org", Location1: "Somewhere, Earth", Location2: "Example Network", Email: "admin@example.org"}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("expecting %+v, got %+v", expected, info)
+	}
+	if _, ok := c.Add(Message{Command: "001", Params: []string{"me", "Welcome"}}); ok {
+		t.Error("expecting 001 not to be consumed")
+	}
+}
+
+func TestInfoCollector(t *testing.T) {
+	var c InfoCollector
+	c.Add(Message{Command: "371", Params: []string{"me", "Example IRC server"}})
+	c.Add(Message{Command: "371", Params: []string{"me", "Written by example"}})
+	lines, ok := c.Add(Message{Command: "374", Params: []string{"me", "End of /INFO list."}})
+	if expected := []string{"Example IRC server", "Written by example"}; !ok || !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expecting %q, got %q %v", expected, lines, ok)
+	}
+	lines, _ = c.Add(Message{Command: "374", Params: []string{"me", "End of /INFO list."}})
+	if lines == nil || len(lines) != 0 {
+		t.Errorf("expecting no lines, got %q", lines)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	v, ok := ParseVersion(Message{Command: "351", Params: []string{"me", "solanum-1.0(20230101)", "irc.example.org", "eHIKMpSZ6 TS6ow"}})
+	expected := VersionInfo{Version: "solanum-1.0(20230101)", Server: "irc.example.org", Comments: "eHIKMpSZ6 TS6ow"}
+	if !ok || v != expected {
+		t.Errorf("expecting %+v, got %+v %v", expected, v, ok)
+	}
+	if m := VersionRequest(""); m.Command != "VERSION" || len(m.Params) != 0 {
+		t.Errorf("unexpected message %v", m)
+	}
+	if m := AdminRequest("irc.example.org"); !reflect.DeepEqual(m.Params, []string{"irc.example.org"}) {
+		t.Errorf("unexpected message %v", m)
+	}
+}
@@ -0,0 +1,137 @@
+package ircmessage
+
+import (
+	"strings"
+	"sync"
+)
+
+// SessionState tracks the state of a client connection from the messages
+// the server sends: the client's own nickname, the capabilities enabled,
+// the channels joined and the ISUPPORT parameters. Every incoming message
+// should be passed to Handle. The capabilities and ISUPPORT parameters are
+// kept in a NetworkConfig, which may be shared with other components by
+// SetNetworkConfig.
+//
+// A SessionState is safe for concurrent use.
+type SessionState struct {
+	mu       sync.RWMutex
+	nick     string
+	welcomed bool
+	channels map[string]string // Folded name to name as joined.
+	config   *NetworkConfig
+	shared   bool // Whether config is fed elsewhere.
+}
+
+// SessionSnapshot is a copy of the state held by a SessionState.
+type SessionSnapshot struct {
+	Nick     string
+	Welcomed bool     // Whether RPL_WELCOME (001) has been received.
+	Caps     []string // Enabled capabilities, sorted, with any values.
+	Channels []string // Joined channels, sorted.
+	ISupport *ISupport
+}
+
+// NewSessionState returns a SessionState for a client registering as nick.
+func NewSessionState(nick string) *SessionState {
+	return &SessionState{
+		nick:     nick,
+		channels: make(map[string]string),
+		config:   NewNetworkConfig(),
+	}
+}
+
+// SetNetworkConfig sets the store the capabilities and ISUPPORT parameters
+// are taken from, in place of the one the SessionState keeps itself. The
+// store must be kept current by passing messages to its own Handle.
+func (s *SessionState) SetNetworkConfig(c *NetworkConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config, s.shared = c, true
+}
+
+// Handle updates the state from a message sent by the server.
+func (s *SessionState) Handle(m Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.shared {
+		s.config.Handle(m)
+	}
+	is := s.config.ISupport()
+	if _, ok := numeric(m.Command); ok {
+		// Numerics are addressed to the client's current nickname,
+		// which the server may have changed or truncated.
+		if len(m.Params) > 0 && m.Params[0] != "*" && (m.Command == "001" || s.welcomed) {
+			s.nick = m.Params[0]
+		}
+		if m.Command == "001" {
+			s.welcomed = true
+		}
+		return
+	}
+	self := s.isSelf(m.Prefix, is)
+	switch upperCommand(m.Command) {
+	case "NICK":
+		if self && len(m.Params) > 0 {
+			s.nick = m.Params[0]
+		}
+	case "JOIN":
+		if self && len(m.Params) > 0 {
+			s.channels[is.Fold(m.Params[0])] = m.Params[0]
+		}
+	case "PART":
+		if self && len(m.Params) > 0 {
+			for _, c := range strings.Split(m.Params[0], ",") {
+				delete(s.channels, is.Fold(c))
+			}
+		}
+	case "KICK":
+		if len(m.Params) > 1 && is.Fold(m.Params[1]) == is.Fold(s.nick) {
+			delete(s.channels, is.Fold(m.Params[0]))
+		}
+	}
+}
+
+func (s *SessionState) isSelf(prefix string, is *ISupport) bool {
+	p := ParsePrefix(prefix)
+	return p != nil && !p.IsServer && is.Fold(p.Nickname) == is.Fold(s.nick)
+}
+
+// Nick returns the client's current nickname.
+func (s *SessionState) Nick() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.nick
+}
+
+// HasCap reports whether the capability c is enabled.
+func (s *SessionState) HasCap(c string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config.HasCap(c)
+}
+
+// InChannel reports whether the client is in channel.
+func (s *SessionState) InChannel(channel string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.channels[s.config.Fold(channel)]
+	return ok
+}
+
+// Snapshot returns a copy of the current state.
+func (s *SessionState) Snapshot() SessionSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap := SessionSnapshot{
+		Nick:     s.nick,
+		Welcomed: s.welcomed,
+		Caps:     s.config.Caps(),
+		// NetworkConfig never modifies the ISupport it returns.
+		ISupport: s.config.ISupport(),
+	}
+	for _, c := range s.channels {
+		snap.Channels = append(snap.Channels, c)
+	}
+	sortStrings(snap.Channels)
+	return snap
+}
@@ -0,0 +1,50 @@
+package ircmessage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSessionState(t *testing.T) {
+	in := ":irc.example.com CAP * ACK :sasl message-tags\r\n" +
+		":irc.example.com 433 * nick :Nickname is already in use\r\n" +
+		":irc.example.com 001 nick_ :Welcome\r\n" +
+		":irc.example.com 005 nick_ CASEMAPPING=rfc1459 CHANTYPES=#& :are supported\r\n" +
+		":nick_!u@h JOIN #Chan\r\n" +
+		":nick_!u@h JOIN &local\r\n" +
+		":other!u@h JOIN #chan\r\n" +
+		":other!u@h JOIN #other\r\n" +
+		":nick_!u@h NICK nick[a]\r\n" +
+		":irc.example.com 353 nick{a} = #chan :nick{a} @other\r\n" +
+		":op!u@h KICK &LOCAL nick[a] :bye\r\n" +
+		":irc.example.com CAP nick{a} ACK :-message-tags\r\n" +
+		":irc.example.com CAP nick{a} NEW :away-notify\r\n" +
+		":irc.example.com CAP nick{a} ACK :away-notify\r\n" +
+		":irc.example.com CAP nick{a} DEL :sasl\r\n"
+	st := NewSessionState("nick")
+	s := NewScanner(strings.NewReader(in))
+	for s.Scan() {
+		st.Handle(s.Message())
+	}
+	snap := st.Snapshot()
+	if snap.Nick != "nick{a}" || !snap.Welcomed {
+		t.Errorf("expecting to be welcomed as nick{a}, got %q %v", snap.Nick, snap.Welcomed)
+	}
+	if expected := []string{"away-notify"}; !reflect.DeepEqual(snap.Caps, expected) {
+		t.Errorf("expecting caps %v, got %v", expected, snap.Caps)
+	}
+	if expected := []string{"#Chan"}; !reflect.DeepEqual(snap.Channels, expected) {
+		t.Errorf("expecting channels %v, got %v", expected, snap.Channels)
+	}
+	if snap.ISupport.ChanTypes() != "#&" {
+		t.Errorf("expecting CHANTYPES #&, got %q", snap.ISupport.ChanTypes())
+	}
+	if !st.InChannel("#CHAN") || st.InChannel("#other") || !st.HasCap("AWAY-NOTIFY") || st.HasCap("sasl") {
+		t.Error("unexpected channel or capability state")
+	}
+	st.Handle(Message{Prefix: "nick[a]!u@h", Command: "PART", Params: []string{"#chan,#x"}})
+	if st.InChannel("#chan") || st.Nick() != "nick{a}" {
+		t.Errorf("expecting to have parted #chan as nick{a}, got %v", st.Snapshot())
+	}
+}
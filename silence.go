@@ -0,0 +1,80 @@
+package ircmessage
+
+import "strings"
+
+// SilenceList requests the silence list, answered by RPL_SILELIST (271)
+// messages ended by RPL_ENDOFSILELIST (272).
+//
+// SILENCE manages a server-side ignore list, as supported by ircu,
+// UnrealIRCd, InspIRCd and others. Servers advertise the SILENCE
+// RPL_ISUPPORT token with the maximum number of entries.
+func SilenceList() Message { return Message{Command: "SILENCE"} }
+
+// SilenceAdd adds mask, such as "nick!*@*", to the silence list.
+func SilenceAdd(mask string) Message {
+	return Message{Command: "SILENCE", Params: []string{"+" + mask}}
+}
+
+// SilenceRemove removes mask from the silence list.
+func SilenceRemove(mask string) Message {
+	return Message{Command: "SILENCE", Params: []string{"-" + mask}}
+}
+
+// SilenceChange is a mask added to or removed from the silence list.
+type SilenceChange struct {
+	Add  bool
+	Mask string
+}
+
+// ParseSilence parses a SILENCE message, such as the one a server sends to
+// confirm a change. A parameter may hold several comma separated changes,
+// and a mask without a sign is added.
+func ParseSilence(m Message) ([]SilenceChange, error) {
+	if !HasCommand(m, "SILENCE") || len(m.Params) == 0 {
+		return nil, ErrMessageMalformed
+	}
+	var changes []SilenceChange
+	for _, mask := range strings.Split(m.Params[0], ",") {
+		c := SilenceChange{Add: true, Mask: mask}
+		switch {
+		case strings.HasPrefix(mask, "+"):
+			c.Mask = mask[1:]
+		case strings.HasPrefix(mask, "-"):
+			c.Add, c.Mask = false, mask[1:]
+		}
+		if c.Mask == "" {
+			return nil, ErrMessageMalformed
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// SilenceEntry is an entry of a silence list.
+type SilenceEntry struct {
+	Nick  string // The user whose list it is.
+	Mask  string
+	Flags string // The flags of the entry on InspIRCd, otherwise empty.
+}
+
+// ParseSilenceEntry parses an RPL_SILELIST (271) message. It returns false
+// for any other message.
+func ParseSilenceEntry(m Message) (SilenceEntry, bool) {
+	if m.Command != "271" || len(m.Params) < 3 {
+		return SilenceEntry{}, false
+	}
+	e := SilenceEntry{Nick: m.Params[1], Mask: m.Params[2]}
+	if len(m.Params) > 3 {
+		e.Flags = m.Params[3]
+	}
+	return e, true
+}
+
+// IsSilenceFull reports whether m is ERR_SILELISTFULL (511), sent when a
+// mask cannot be added because the list is full, and if so the mask.
+func IsSilenceFull(m Message) (mask string, ok bool) {
+	if m.Command != "511" || len(m.Params) < 2 {
+		return "", false
+	}
+	return m.Params[1], true
+}
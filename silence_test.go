@@ -0,0 +1,50 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+var silenceTests = []struct {
+	in       Message
+	expected []SilenceChange
+	err      error
+}{
+	{SilenceAdd("spam!*@*"), []SilenceChange{{true, "spam!*@*"}}, nil},
+	{SilenceRemove("*!*@bad.host"), []SilenceChange{{false, "*!*@bad.host"}}, nil},
+	{
+		Message{Prefix: "me!u@h", Command: "silence", Params: []string{"+a!*@*,-b!*@*,c!*@*"}},
+		[]SilenceChange{{true, "a!*@*"}, {false, "b!*@*"}, {true, "c!*@*"}},
+		nil,
+	},
+	{Message{Command: "SILENCE", Params: []string{"+"}}, nil, ErrMessageMalformed},
+	{SilenceList(), nil, ErrMessageMalformed},
+}
+
+func TestParseSilence(t *testing.T) {
+	for i, tt := range silenceTests {
+		changes, err := ParseSilence(tt.in)
+		if err != tt.err || !reflect.DeepEqual(changes, tt.expected) {
+			t.Errorf("%d. expecting %v %v, got %v %v", i, tt.expected, tt.err, changes, err)
+		}
+	}
+}
+
+func TestParseSilenceReplies(t *testing.T) {
+	e, ok := ParseSilenceEntry(Message{Command: "271", Params: []string{"me", "me", "*!*@bad.host", "pn"}})
+	if expected := (SilenceEntry{"me", "*!*@bad.host", "pn"}); !ok || e != expected {
+		t.Errorf("expecting %+v, got %+v %v", expected, e, ok)
+	}
+	if _, ok := ParseSilenceEntry(Message{Command: "272", Params: []string{"me", "End of Silence List"}}); ok {
+		t.Error("expecting RPL_ENDOFSILELIST not to be an entry")
+	}
+	mask, ok := IsSilenceFull(Message{Command: "511", Params: []string{"me", "x!*@*", "Your silence list is full"}})
+	if !ok || mask != "x!*@*" {
+		t.Errorf("expecting a full list for x!*@*, got %q %v", mask, ok)
+	}
+	is := NewISupport()
+	is.Update(Message{Command: "005", Params: []string{"me", "SILENCE=32", "are supported"}})
+	if is.Silence() != 32 {
+		t.Errorf("expecting 32 entries, got %d", is.Silence())
+	}
+}
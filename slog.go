@@ -0,0 +1,48 @@
+package ircmessage
+
+import "log/slog"
+
+// LogValue implements slog.LogValuer, logging m as a group holding its
+// command, its prefix split into nick, user and host, or server, its tags in
+// key order and its params. Empty fields and the Raw field are left out.
+func (m Message) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, 4)
+	attrs = append(attrs, slog.String("command", m.Command))
+	if m.Prefix != "" {
+		attrs = append(attrs, prefixAttr(m.Prefix))
+	}
+	if len(m.Tags) > 0 {
+		keys := make([]string, 0, len(m.Tags))
+		for k := range m.Tags {
+			keys = append(keys, k)
+		}
+		sortStrings(keys)
+		tags := make([]slog.Attr, len(keys))
+		for i, k := range keys {
+			tags[i] = slog.String(k, m.Tags[k])
+		}
+		attrs = append(attrs, slog.Attr{Key: "tags", Value: slog.GroupValue(tags...)})
+	}
+	if len(m.Params) > 0 {
+		attrs = append(attrs, slog.Any("params", m.Params))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func prefixAttr(prefix string) slog.Attr {
+	p := ParsePrefix(prefix)
+	switch {
+	case p == nil:
+		return slog.String("prefix", prefix)
+	case p.IsServer:
+		return slog.String("server", p.Host)
+	}
+	attrs := []slog.Attr{slog.String("nick", p.Nickname)}
+	if p.User != "" {
+		attrs = append(attrs, slog.String("user", p.User))
+	}
+	if p.Host != "" {
+		attrs = append(attrs, slog.String("host", p.Host))
+	}
+	return slog.Attr{Key: "prefix", Value: slog.GroupValue(attrs...)}
+}
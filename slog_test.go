@@ -0,0 +1,43 @@
+package ircmessage
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+var logValueTests = []struct {
+	in       string
+	expected string
+}{
+	{"PING", "m.command=PING"},
+	{
+		"@time=2017-09-26T00:00:00.000Z;account=bob :bob!~b@host PRIVMSG #c :hi there",
+		`m.command=PRIVMSG m.prefix.nick=bob m.prefix.user=~b m.prefix.host=host m.tags.account=bob m.tags.time=2017-09-26T00:00:00.000Z m.params="[#c hi there]"`,
+	},
+	{":irc.example.com 001 bob :Welcome", `m.command=001 m.server=irc.example.com m.params="[bob Welcome]"`},
+	{":bob JOIN #c", "m.command=JOIN m.prefix.nick=bob m.params=[#c]"},
+}
+
+func TestMessageLogValue(t *testing.T) {
+	for i, tt := range logValueTests {
+		s := NewScanner(strings.NewReader(tt.in + "\r\n"))
+		if !s.Scan() {
+			t.Fatalf("%d. %v", i, s.Err())
+		}
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey) {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+		logger.Info("", "m", s.Message())
+		if got := strings.TrimSpace(buf.String()); got != tt.expected {
+			t.Errorf("%d. expecting %s\ngot %s", i, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package ircmessage
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ServerNoticeKind identifies the event a ServerNotice reports.
+type ServerNoticeKind int
+
+const (
+	ServerNoticeOther   ServerNoticeKind = iota // A notice not otherwise recognised.
+	ServerNoticeConnect                         // A client connected.
+	ServerNoticeExit                            // A client disconnected.
+	ServerNoticeKill                            // A client was killed by an operator.
+)
+
+// ServerNotice is a notice sent by a server to operators, as selected by
+// their snomask. The fields other than Server and Text are filled in when
+// the notice is recognised, and those a server does not report are empty.
+type ServerNotice struct {
+	Kind   ServerNoticeKind
+	Server string
+	Text   string // The text without a leading "*** " or "*** Notice -- ".
+	Nick   string // The client connecting, exiting or killed.
+	User   string
+	Host   string
+	IP     string
+	Reason string // The reason for an exit or kill.
+	Oper   string // The operator responsible for a kill.
+}
+
+// serverNoticePatterns recognise the notices of the ratbox family, such as
+// Solanum and Charybdis, InspIRCd and UnrealIRCd. The names of their
+// subexpressions are those of the ServerNotice fields they fill in.
+var serverNoticePatterns = []struct {
+	kind ServerNoticeKind
+	re   *regexp.Regexp
+}{
+	// Solanum and UnrealIRCd.
+	{ServerNoticeConnect, regexp.MustCompile(`^Client connecting: (?P<nick>\S+) \((?P<user>[^@\s]+)@(?P<host>\S+)\) \[(?P<ip>[^\]]*)\]`)},
+	// Solanum.
+	{ServerNoticeExit, regexp.MustCompile(`^Client exiting: (?P<nick>\S+) \((?P<user>[^@\s]+)@(?P<host>\S+)\) \[(?P<reason>.*)\] \[(?P<ip>[^\]]*)\]$`)},
+	// UnrealIRCd.
+	{ServerNoticeExit, regexp.MustCompile(`^Client exiting: (?P<nick>\S+) \((?P<user>[^@\s]+)@(?P<host>\S+)\) \[(?P<ip>[^\]]*)\] \((?P<reason>.*)\)$`)},
+	// InspIRCd.
+	{ServerNoticeConnect, regexp.MustCompile(`^CONNECT: Client connecting on port \d+(?: \(class [^)]*\))?: (?P<nick>[^!\s]+)!(?P<user>[^@\s]+)@(?P<host>\S+) \((?P<ip>[^)]*)\)`)},
+	{ServerNoticeExit, regexp.MustCompile(`^QUIT: Client exiting: (?P<nick>[^!\s]+)!(?P<user>[^@\s]+)@(?P<host>\S+) \((?P<ip>[^)]*)\) \[(?P<reason>.*)\]$`)},
+	{ServerNoticeKill, regexp.MustCompile(`^KILL: (?:Local|Remote) kill by (?P<oper>\S+): (?P<nick>[^!\s]+)!(?P<user>[^@\s]+)@(?P<host>\S+) \((?P<reason>.*)\)$`)},
+	// Solanum.
+	{ServerNoticeKill, regexp.MustCompile(`^Received KILL message for (?P<nick>[^!\s]+)!(?P<user>[^@\s]+)@(?P<host>\S+?)\. From (?P<oper>\S+) Path: \S+ \((?P<reason>.*)\)$`)},
+}
+
+// ParseServerNotice parses a NOTICE from a server whose text begins with
+// "***", as server notices do. It returns false for any other message.
+// Notices are recognised heuristically from the formats of common servers,
+// and those that are not have the kind ServerNoticeOther.
+func ParseServerNotice(m Message) (ServerNotice, bool) {
+	if !HasCommand(m, "NOTICE") || len(m.Params) < 2 {
+		return ServerNotice{}, false
+	}
+	p := ParsePrefix(m.Prefix)
+	text, ok := strings.CutPrefix(m.Params[len(m.Params)-1], "***")
+	if p == nil || !p.IsServer || !ok {
+		return ServerNotice{}, false
+	}
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "Notice -- ")
+	n := ServerNotice{Server: p.Host, Text: text}
+	for _, pat := range serverNoticePatterns {
+		match := pat.re.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		n.Kind = pat.kind
+		for i, name := range pat.re.SubexpNames() {
+			switch name {
+			case "nick":
+				n.Nick = match[i]
+			case "user":
+				n.User = match[i]
+			case "host":
+				n.Host = match[i]
+			case "ip":
+				n.IP = match[i]
+			case "reason":
+				n.Reason = match[i]
+			case "oper":
+				n.Oper = match[i]
+			}
+		}
+		break
+	}
+	return n, true
+}
+
+// SetSnomask returns the MODE message setting the server notice mask of
+// nick, an operator, to mask, such as "+cF" to add notices of client
+// connections and far connections on ratbox family servers.
+func SetSnomask(nick, mask string) Message {
+	return Message{Command: "MODE", Params: []string{nick, "+s", mask}}
+}
+
+// ParseSnomask parses RPL_SNOMASK (008), returning the server notice mask
+// now in effect. It returns false for any other message.
+func ParseSnomask(m Message) (string, bool) {
+	if m.Command != "008" || len(m.Params) < 2 {
+		return "", false
+	}
+	return m.Params[1], true
+}
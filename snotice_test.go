@@ -0,0 +1,76 @@
+package ircmessage
+
+import (
+	"strings"
+	"testing"
+)
+
+var serverNoticeTests = []struct {
+	text     string
+	expected ServerNotice
+}{
+	{
+		"*** Notice -- Client connecting: alice (~a@host.example) [192.0.2.1] {users} [Alice]",
+		ServerNotice{Kind: ServerNoticeConnect, Nick: "alice", User: "~a", Host: "host.example", IP: "192.0.2.1"},
+	},
+	{
+		"*** Notice -- Client exiting: alice (~a@host.example) [Quit: bye [now]] [192.0.2.1]",
+		ServerNotice{Kind: ServerNoticeExit, Nick: "alice", User: "~a", Host: "host.example", IP: "192.0.2.1", Reason: "Quit: bye [now]"},
+	},
+	{
+		"*** Notice -- Received KILL message for alice!~a@host.example. From oper Path: irc.example.org!oper (spamming)",
+		ServerNotice{Kind: ServerNoticeKill, Nick: "alice", User: "~a", Host: "host.example", Oper: "oper", Reason: "spamming"},
+	},
+	{
+		"*** Client connecting: bob (b@host.example) [2001:db8::1] {clients} [secure TLSv1.3]",
+		ServerNotice{Kind: ServerNoticeConnect, Nick: "bob", User: "b", Host: "host.example", IP: "2001:db8::1"},
+	},
+	{
+		"*** Client exiting: bob (b@host.example) [2001:db8::1] (Ping timeout: 240 seconds)",
+		ServerNotice{Kind: ServerNoticeExit, Nick: "bob", User: "b", Host: "host.example", IP: "2001:db8::1", Reason: "Ping timeout: 240 seconds"},
+	},
+	{
+		"*** CONNECT: Client connecting on port 6697 (class main): carol!c@host.example (198.51.100.7) [Carol]",
+		ServerNotice{Kind: ServerNoticeConnect, Nick: "carol", User: "c", Host: "host.example", IP: "198.51.100.7"},
+	},
+	{
+		"*** QUIT: Client exiting: carol!c@host.example (198.51.100.7) [Quit: later]",
+		ServerNotice{Kind: ServerNoticeExit, Nick: "carol", User: "c", Host: "host.example", IP: "198.51.100.7", Reason: "Quit: later"},
+	},
+	{
+		"*** KILL: Local kill by oper: carol!c@host.example (flooding)",
+		ServerNotice{Kind: ServerNoticeKill, Nick: "carol", User: "c", Host: "host.example", Oper: "oper", Reason: "flooding"},
+	},
+	{"*** Notice -- oper is now an operator", ServerNotice{}},
+}
+
+func TestParseServerNotice(t *testing.T) {
+	for i, tt := range serverNoticeTests {
+		n, ok := ParseServerNotice(Message{Prefix: "irc.example.org", Command: "NOTICE", Params: []string{"oper", tt.text}})
+		tt.expected.Server = "irc.example.org"
+		text := n.Text
+		n.Text = ""
+		if !ok || n != tt.expected || strings.HasPrefix(text, "*") {
+			t.Errorf("%d. expecting %+v, got %+v %v", i, tt.expected, n, ok)
+		}
+	}
+	for i, m := range []Message{
+		{Prefix: "nick!u@h", Command: "NOTICE", Params: []string{"oper", "*** Client connecting: x (u@h) [ip]"}},
+		{Prefix: "irc.example.org", Command: "NOTICE", Params: []string{"oper", "hello"}},
+		{Prefix: "irc.example.org", Command: "PRIVMSG", Params: []string{"oper", "*** hello"}},
+	} {
+		if _, ok := ParseServerNotice(m); ok {
+			t.Errorf("%d. expecting %v not to be a server notice", i, m)
+		}
+	}
+}
+
+func TestSnomask(t *testing.T) {
+	if m := SetSnomask("oper", "+cF"); m.Command != "MODE" || m.Params[2] != "+cF" {
+		t.Errorf("unexpected message %v", m)
+	}
+	mask, ok := ParseSnomask(Message{Command: "008", Params: []string{"oper", "+cFs", "Server notice mask"}})
+	if !ok || mask != "+cFs" {
+		t.Errorf("expecting +cFs, got %q %v", mask, ok)
+	}
+}
@@ -0,0 +1,67 @@
+package ircmessage
+
+import (
+	"bytes"
+	"strings"
+)
+
+// SpacePolicy determines how a Scanner treats runs of more than one space
+// separating the components of a message, outside the trailing parameter.
+type SpacePolicy int
+
+const (
+	// SpacesCollapse treats a run of spaces as a single separator, and
+	// ignores spaces at the end of the line. This is the default.
+	SpacesCollapse SpacePolicy = iota
+	// SpacesStrict treats a message with a run of spaces, or spaces at
+	// the end of the line, as malformed.
+	SpacesStrict
+	// SpacesPreserve keeps the position of parameters by treating each
+	// extra space as separating an empty parameter, for relays that must
+	// reproduce messages exactly. Spaces at the end of the line are
+	// ignored.
+	SpacesPreserve
+)
+
+// strictSpaces reports whether the line in raw uses single spaces as
+// separators everywhere before its trailing parameter.
+func strictSpaces(raw []byte, params span) bool {
+	head := raw[:len(raw)-len("\r\n")]
+	if p := raw[params.start:params.end]; len(p) > 0 {
+		trailing := -1
+		if p[0] == runeColon {
+			trailing = 0
+		} else if i := bytes.Index(p, []byte(" :")); i >= 0 {
+			trailing = i + 1
+		}
+		if trailing >= 0 {
+			head = raw[:params.start+trailing]
+			return !bytes.Contains(head, []byte("  "))
+		}
+	}
+	return !bytes.Contains(head, []byte("  ")) && !bytes.HasSuffix(head, []byte(tokenSpace))
+}
+
+// appendParamsExact splits the parameters of the message in raw, adding
+// an empty parameter for each extra space separating them.
+func appendParamsExact(dst []string, raw string, command, params span) []string {
+	if params.end == params.start {
+		return dst
+	}
+	for i := command.end + 1; i < params.start; i++ {
+		dst = append(dst, "")
+	}
+	p := raw[params.start:params.end]
+	for p != "" {
+		if p[0] == runeColon {
+			return append(dst, p[1:])
+		}
+		var param string
+		param, p, _ = strings.Cut(p, tokenSpace)
+		if param == "" && strings.Trim(p, tokenSpace) == "" {
+			break // Spaces at the end of the line.
+		}
+		dst = append(dst, param)
+	}
+	return dst
+}
@@ -0,0 +1,45 @@
+package ircmessage
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var spacesTests = []struct {
+	in       string
+	collapse []string
+	strict   error
+	preserve []string
+}{
+	{"FOO a b :c  d", []string{"a", "b", "c  d"}, nil, []string{"a", "b", "c  d"}},
+	{"FOO a  b", []string{"a", "b"}, ErrMessageMalformed, []string{"a", "", "b"}},
+	{"FOO  a b", []string{"a", "b"}, ErrMessageMalformed, []string{"", "a", "b"}},
+	{"FOO a b  ", []string{"a", "b"}, ErrMessageMalformed, []string{"a", "b"}},
+	{"FOO a   :b", []string{"a", "b"}, ErrMessageMalformed, []string{"a", "", "", "b"}},
+	{":n  FOO a", []string{"a"}, ErrMessageMalformed, []string{"a"}},
+	{"@t=1  FOO a", []string{"a"}, ErrMessageMalformed, []string{"a"}},
+	{"FOO :", []string{""}, nil, []string{""}},
+	{"FOO", nil, nil, nil},
+}
+
+func TestScannerSpaces(t *testing.T) {
+	scan := func(in string, policy SpacePolicy) (Message, error) {
+		s := NewScanner(strings.NewReader(in + "\r\n"))
+		s.SetProfile(Profile{Spaces: policy})
+		s.Scan()
+		return s.Message(), s.Err()
+	}
+	for i, tt := range spacesTests {
+		if m, err := scan(tt.in, SpacesCollapse); err != nil || !reflect.DeepEqual(m.Params, tt.collapse) {
+			t.Errorf("%d. collapse: expecting %q, got %q %v", i, tt.collapse, m.Params, err)
+		}
+		if _, err := scan(tt.in, SpacesStrict); !errors.Is(err, tt.strict) {
+			t.Errorf("%d. strict: expecting error %v, got %v", i, tt.strict, err)
+		}
+		if m, err := scan(tt.in, SpacesPreserve); err != nil || !reflect.DeepEqual(m.Params, tt.preserve) {
+			t.Errorf("%d. preserve: expecting %q, got %q %v", i, tt.preserve, m.Params, err)
+		}
+	}
+}
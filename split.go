@@ -0,0 +1,115 @@
+package ircmessage
+
+import "unicode/utf8"
+
+// splitAtom is an indivisible piece of text: either a single rune or a
+// complete formatting code.
+type splitAtom struct {
+	text string
+	code bool
+}
+
+func splitAtoms(text string) []splitAtom {
+	atoms := make([]splitAtom, 0, len(text))
+	for len(text) > 0 {
+		n := formatCodeLen(text)
+		code := n > 0
+		if !code {
+			_, n = utf8.DecodeRuneInString(text)
+		}
+		atoms = append(atoms, splitAtom{text: text[:n], code: code})
+		text = text[n:]
+	}
+	return atoms
+}
+
+// SplitText breaks text into chunks small enough to be sent to target in
+// PRIVMSG or NOTICE messages of at most budget bytes, including the command,
+// target, trailing colon and CRLF. Any prefix the server adds when relaying
+// the message must be accounted for by the caller in budget.
+//
+// Text is split on word boundaries where possible and never inside a UTF-8
+// sequence or formatting code. Formatting in effect at the end of a chunk is
+// reapplied at the start of the next.
+func SplitText(target, text string, budget int) []string {
+	return splitText(text, textBudget(target, budget), true)
+}
+
+// textBudget returns how much of budget remains for the text of a PRIVMSG
+// to target.
+func textBudget(target string, budget int) int {
+	return budget - len("PRIVMSG  :\r\n") - len(target)
+}
+
+// splitText splits text into chunks of at most avail bytes. When restore is
+// set, the space a chunk was split on is dropped and formatting is carried
+// over to the next chunk; otherwise the space is kept at the end of the chunk
+// so that the chunks concatenate back into text.
+func splitText(text string, avail int, restore bool) []string {
+	atoms := splitAtoms(text)
+	var (
+		chunks []string
+		state  formatState
+	)
+	for i := 0; i < len(atoms); {
+		var prefix string
+		if restore {
+			prefix = state.codes()
+		}
+		size := len(prefix)
+		next := state
+		lastSpace := -1
+		var spaceState formatState
+		j := i
+		for ; j < len(atoms); j++ {
+			a := atoms[j]
+			if size+len(a.text) > avail {
+				break
+			}
+			size += len(a.text)
+			if a.code {
+				next.apply(a.text)
+			} else if a.text == tokenSpace && j > i {
+				lastSpace, spaceState = j, next
+			}
+		}
+		if restore && j < len(atoms) && j > i && atoms[j].text == tokenSpace {
+			lastSpace, spaceState = j, next
+		}
+		switch {
+		case j == len(atoms):
+		case j == i:
+			// Not even a single atom fits, send it anyway
+			// rather than never making progress.
+			if atoms[j].code {
+				next.apply(atoms[j].text)
+			}
+			j++
+		case lastSpace > i:
+			end := lastSpace
+			if !restore {
+				end++
+			}
+			chunks = append(chunks, prefix+joinAtoms(atoms[i:end]))
+			state = spaceState
+			i = lastSpace + 1
+			continue
+		}
+		chunks = append(chunks, prefix+joinAtoms(atoms[i:j]))
+		state = next
+		i = j
+	}
+	return chunks
+}
+
+func joinAtoms(atoms []splitAtom) string {
+	n := 0
+	for _, a := range atoms {
+		n += len(a.text)
+	}
+	b := make([]byte, 0, n)
+	for _, a := range atoms {
+		b = append(b, a.text...)
+	}
+	return string(b)
+}
@@ -0,0 +1,37 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+// A budget of 24 leaves 10 bytes of text per chunk for target "#c".
+var splitTextTests = []struct {
+	text     string
+	budget   int
+	expected []string
+}{
+	{"short", 24, []string{"short"}},
+	{"hello world foo", 24, []string{"hello", "world foo"}},
+	{"abcdefghijklmnop", 24, []string{"abcdefghij", "klmnop"}},
+	{"ééééééé", 24, []string{"ééééé", "éé"}},
+	{"\x02bold text here", 24, []string{"\x02bold text", "\x02here"}},
+	{"\x034,1red words go", 26, []string{"\x034,1red", "\x0304,01words", "\x0304,01go"}},
+	{"\x02on\x02 off words", 24, []string{"\x02on\x02 off", "words"}},
+	{"\x0312abcdefghijkl", 24, []string{"\x0312abcdefg", "\x0312hijkl"}},
+	{"", 24, nil},
+}
+
+func TestSplitText(t *testing.T) {
+	for i, tt := range splitTextTests {
+		chunks := SplitText("#c", tt.text, tt.budget)
+		if !reflect.DeepEqual(chunks, tt.expected) {
+			t.Errorf("%d. expecting chunks %q, got %q", i, tt.expected, chunks)
+		}
+		for _, c := range chunks {
+			if n := len("PRIVMSG #c :" + c + "\r\n"); n > tt.budget {
+				t.Errorf("%d. chunk %q is %d bytes, over budget of %d", i, c, n, tt.budget)
+			}
+		}
+	}
+}
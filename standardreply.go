@@ -0,0 +1,27 @@
+package ircmessage
+
+// StandardReply is a FAIL, WARN or NOTE message as per:
+// https://ircv3.net/specs/extensions/standard-replies
+type StandardReply struct {
+	Type        string // FAIL, WARN or NOTE.
+	Command     string // The command the reply relates to, or "*".
+	Code        string
+	Context     []string
+	Description string
+}
+
+// ParseStandardReply parses a FAIL, WARN or NOTE message.
+func ParseStandardReply(m Message) (StandardReply, error) {
+	typ := upperCommand(m.Command)
+	if typ != "FAIL" && typ != "WARN" && typ != "NOTE" || len(m.Params) < 3 {
+		return StandardReply{}, ErrMessageMalformed
+	}
+	last := len(m.Params) - 1
+	return StandardReply{
+		Type:        typ,
+		Command:     m.Params[0],
+		Code:        m.Params[1],
+		Context:     m.Params[2:last],
+		Description: m.Params[last],
+	}, nil
+}
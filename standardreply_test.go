@@ -0,0 +1,24 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStandardReply(t *testing.T) {
+	m := Message{Command: "WARN", Params: []string{"REHASH", "CERTS_EXPIRED", "cert.pem", "Certificate has expired"}}
+	expected := StandardReply{
+		Type:        "WARN",
+		Command:     "REHASH",
+		Code:        "CERTS_EXPIRED",
+		Context:     []string{"cert.pem"},
+		Description: "Certificate has expired",
+	}
+	r, err := ParseStandardReply(m)
+	if err != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expecting %+v, got %+v (%v)", expected, r, err)
+	}
+	if _, err := ParseStandardReply(Message{Command: "NOTICE", Params: []string{"a", "b", "c"}}); err != ErrMessageMalformed {
+		t.Errorf("expecting %v, got %v", ErrMessageMalformed, err)
+	}
+}
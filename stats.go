@@ -0,0 +1,75 @@
+package ircmessage
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ScannerStats holds counters describing the traffic read by a Scanner.
+type ScannerStats struct {
+	Messages int64 // Messages scanned successfully.
+	Bytes    int64 // Bytes consumed, including those of malformed lines.
+	// Malformed counts the malformed lines encountered. Unless the
+	// caller recovers from them the first one stops the scan.
+	Malformed int64
+	// Commands counts messages by command, and is nil unless enabled
+	// with CountCommands.
+	Commands map[string]int64
+}
+
+// scannerStats is the live, concurrently readable form of ScannerStats.
+type scannerStats struct {
+	messages  atomic.Int64
+	bytes     atomic.Int64
+	malformed atomic.Int64
+
+	mu       sync.Mutex
+	commands map[string]int64
+}
+
+func (st *scannerStats) record(n int, m Message, err error) {
+	st.bytes.Add(int64(n))
+	switch {
+	case err == nil:
+		st.messages.Add(1)
+		if st.commands != nil {
+			st.mu.Lock()
+			if _, ok := st.commands[m.Command]; ok {
+				st.commands[m.Command]++
+			} else {
+				// The command may share storage with a reused buffer.
+				st.commands[strings.Clone(m.Command)] = 1
+			}
+			st.mu.Unlock()
+		}
+	case errors.Is(err, ErrMessageMalformed):
+		st.malformed.Add(1)
+	}
+}
+
+// CountCommands enables per-command counts in Stats. It must be called
+// before the first call to Scan.
+func (s *Scanner) CountCommands() {
+	s.stats.commands = make(map[string]int64)
+}
+
+// Stats returns the Scanner's counters. It is safe to call concurrently
+// with Scan, for example from a monitoring endpoint.
+func (s *Scanner) Stats() ScannerStats {
+	st := ScannerStats{
+		Messages:  s.stats.messages.Load(),
+		Bytes:     s.stats.bytes.Load(),
+		Malformed: s.stats.malformed.Load(),
+	}
+	s.stats.mu.Lock()
+	defer s.stats.mu.Unlock()
+	if s.stats.commands != nil {
+		st.Commands = make(map[string]int64, len(s.stats.commands))
+		for k, v := range s.stats.commands {
+			st.Commands[k] = v
+		}
+	}
+	return st
+}
@@ -0,0 +1,29 @@
+package ircmessage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScannerStats(t *testing.T) {
+	const in = "PING :a\r\nPING :b\r\n:n PRIVMSG #c :hi\r\n@bad=a=b FOO\r\n"
+	s := NewScanner(strings.NewReader(in))
+	s.CountCommands()
+	for s.Scan() {
+	}
+	expected := ScannerStats{
+		Messages:  3,
+		Bytes:     int64(len(in)),
+		Malformed: 1,
+		Commands:  map[string]int64{"PING": 2, "PRIVMSG": 1},
+	}
+	if st := s.Stats(); !reflect.DeepEqual(st, expected) {
+		t.Errorf("expecting %+v, got %+v", expected, st)
+	}
+	s = NewScanner(strings.NewReader(in))
+	s.Scan()
+	if st := s.Stats(); st.Commands != nil || st.Messages != 1 {
+		t.Errorf("unexpected stats without command counts %+v", st)
+	}
+}
@@ -0,0 +1,128 @@
+package ircmessage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatsRequest returns the STATS message making query, such as "u" for
+// uptime, of the server target, or of the current server if it is empty.
+func StatsRequest(query, target string) Message {
+	m := Message{Command: "STATS", Params: []string{query}}
+	if target != "" {
+		m.Params = append(m.Params, target)
+	}
+	return m
+}
+
+// StatsLink is an RPL_STATSLINKINFO (211) reply to STATS l, describing a
+// connection to the server.
+type StatsLink struct {
+	Name         string // The connection, such as "nick[user@host]".
+	SendQ        int64  // The bytes queued to be sent.
+	SentMessages int64
+	SentKBytes   int64
+	RecvMessages int64
+	RecvKBytes   int64
+	Open         time.Duration // How long the connection has been open.
+}
+
+// ParseStatsLink parses RPL_STATSLINKINFO (211). It returns false for any
+// other message.
+func ParseStatsLink(m Message) (StatsLink, bool) {
+	if m.Command != "211" || len(m.Params) < 8 {
+		return StatsLink{}, false
+	}
+	var n [6]int64
+	for i := range n {
+		v, err := strconv.ParseInt(m.Params[i+2], 10, 64)
+		if err != nil {
+			return StatsLink{}, false
+		}
+		n[i] = v
+	}
+	return StatsLink{
+		Name:         m.Params[1],
+		SendQ:        n[0],
+		SentMessages: n[1],
+		SentKBytes:   n[2],
+		RecvMessages: n[3],
+		RecvKBytes:   n[4],
+		Open:         time.Duration(n[5]) * time.Second,
+	}, true
+}
+
+// ParseStatsUptime parses RPL_STATSUPTIME (242), the reply to STATS u, such
+// as "Server Up 12 days, 3:04:05". It returns false for any other message.
+func ParseStatsUptime(m Message) (time.Duration, bool) {
+	if m.Command != "242" || len(m.Params) < 2 {
+		return 0, false
+	}
+	text := strings.ToLower(strings.ReplaceAll(m.Params[len(m.Params)-1], ",", ""))
+	var days, hours, mins, secs int
+	if _, err := fmt.Sscanf(text, "server up %d days %d:%d:%d", &days, &hours, &mins, &secs); err != nil {
+		return 0, false
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(hours)*time.Hour +
+		time.Duration(mins)*time.Minute + time.Duration(secs)*time.Second, true
+}
+
+// StatsOLine is an RPL_STATSOLINE (243) reply to STATS o, describing an
+// operator block.
+type StatsOLine struct {
+	Mask       string // The hosts the operator may connect from.
+	Name       string
+	Privileges string // The privilege set, where the server shows it.
+	Class      string // The connection class, where the server shows it.
+}
+
+// ParseStatsOLine parses RPL_STATSOLINE (243). It returns false for any
+// other message.
+func ParseStatsOLine(m Message) (StatsOLine, bool) {
+	if m.Command != "243" || len(m.Params) < 5 {
+		return StatsOLine{}, false
+	}
+	o := StatsOLine{Mask: m.Params[2], Name: m.Params[4]}
+	if len(m.Params) > 5 {
+		o.Privileges = m.Params[5]
+	}
+	if len(m.Params) > 6 {
+		o.Class = m.Params[6]
+	}
+	return o, true
+}
+
+// StatsKLine is an RPL_STATSKLINE (216) reply to STATS k, describing a ban
+// on connections.
+type StatsKLine struct {
+	Host   string
+	User   string
+	Reason string
+}
+
+// Mask returns the user@host mask the K-line matches.
+func (k StatsKLine) Mask() string { return k.User + "@" + k.Host }
+
+// ParseStatsKLine parses RPL_STATSKLINE (216). It returns false for any
+// other message.
+func ParseStatsKLine(m Message) (StatsKLine, bool) {
+	if m.Command != "216" || len(m.Params) < 5 {
+		return StatsKLine{}, false
+	}
+	k := StatsKLine{Host: m.Params[2], User: m.Params[4]}
+	if len(m.Params) > 5 {
+		k.Reason = m.Params[5]
+	}
+	return k, true
+}
+
+// ParseEndOfStats parses RPL_ENDOFSTATS (219), which ends the reply to a
+// STATS query, returning the query. It returns false for any other message.
+func ParseEndOfStats(m Message) (query string, ok bool) {
+	if m.Command != "219" || len(m.Params) < 2 {
+		return "", false
+	}
+	return m.Params[1], true
+}
@@ -0,0 +1,54 @@
+package ircmessage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseStatsLink(t *testing.T) {
+	l, ok := ParseStatsLink(Message{Command: "211", Params: []string{"me", "alice[a@192.0.2.1]", "0", "120", "15", "98", "7", "3600", "10", "-"}})
+	expected := StatsLink{Name: "alice[a@192.0.2.1]", SentMessages: 120, SentKBytes: 15, RecvMessages: 98, RecvKBytes: 7, Open: time.Hour}
+	if !ok || l != expected {
+		t.Errorf("expecting %+v, got %+v %v", expected, l, ok)
+	}
+	if _, ok := ParseStatsLink(Message{Command: "211", Params: []string{"me", "x", "0", "a", "0", "0", "0", "0"}}); ok {
+		t.Error("expecting a malformed count to be rejected")
+	}
+}
+
+var statsUptimeTests = []struct {
+	text     string
+	expected time.Duration
+	ok       bool
+}{
+	{"Server Up 12 days, 3:04:05", 12*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second, true},
+	{"Server Up 0 days 0:00:30", 30 * time.Second, true},
+	{"Server up 1 days, 00:00:00", 24 * time.Hour, true},
+	{"Up a while", 0, false},
+}
+
+func TestParseStatsUptime(t *testing.T) {
+	for i, tt := range statsUptimeTests {
+		d, ok := ParseStatsUptime(Message{Command: "242", Params: []string{"me", tt.text}})
+		if ok != tt.ok || d != tt.expected {
+			t.Errorf("%d. expecting %v %v, got %v %v", i, tt.expected, tt.ok, d, ok)
+		}
+	}
+}
+
+func TestParseStatsLines(t *testing.T) {
+	o, ok := ParseStatsOLine(Message{Command: "243", Params: []string{"me", "O", "*@staff.example", "*", "alice", "admin", "opers"}})
+	if expected := (StatsOLine{"*@staff.example", "alice", "admin", "opers"}); !ok || o != expected {
+		t.Errorf("expecting %+v, got %+v %v", expected, o, ok)
+	}
+	k, ok := ParseStatsKLine(Message{Command: "216", Params: []string{"me", "K", "bad.example", "*", "*", "Spamming"}})
+	if expected := (StatsKLine{"bad.example", "*", "Spamming"}); !ok || k != expected || k.Mask() != "*@bad.example" {
+		t.Errorf("expecting %+v, got %+v %v", expected, k, ok)
+	}
+	if q, ok := ParseEndOfStats(Message{Command: "219", Params: []string{"me", "k", "End of /STATS report"}}); !ok || q != "k" {
+		t.Errorf("expecting the end of STATS k, got %q %v", q, ok)
+	}
+	if m := StatsRequest("u", ""); m.Command != "STATS" || len(m.Params) != 1 {
+		t.Errorf("unexpected message %v", m)
+	}
+}
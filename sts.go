@@ -0,0 +1,268 @@
+package ircmessage
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBadSTSPolicy is returned when the value of the sts capability cannot
+// be parsed.
+var ErrBadSTSPolicy = errors.New("malformed sts policy")
+
+// STSPolicy is a Strict Transport Security policy as per:
+// https://ircv3.net/specs/extensions/sts
+type STSPolicy struct {
+	// Port is the TLS port to upgrade to. It is only advertised on
+	// insecure connections.
+	Port int
+	// Duration is how long the policy should be remembered for. It is
+	// only advertised on secure connections, where a Duration of 0 asks
+	// for the policy to be forgotten.
+	Duration time.Duration
+	// HasDuration reports whether a duration was advertised.
+	HasDuration bool
+	// Preload reports whether the network consents to being included in
+	// a list of policies shipped with clients.
+	Preload bool
+}
+
+// ParseSTSPolicy parses the value of the sts capability, such as
+// "port=6697,duration=2592000". Unrecognised keys are ignored.
+func ParseSTSPolicy(value string) (STSPolicy, error) {
+	var p STSPolicy
+	for _, kv := range strings.Split(value, ",") {
+		k, v, _ := strings.Cut(kv, "=")
+		switch k {
+		case "port":
+			port, err := strconv.Atoi(v)
+			if err != nil || port < 1 || port > 65535 {
+				return STSPolicy{}, ErrBadSTSPolicy
+			}
+			p.Port = port
+		case "duration":
+			secs, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || secs < 0 || secs > int64(1<<63-1)/int64(time.Second) {
+				return STSPolicy{}, ErrBadSTSPolicy
+			}
+			p.Duration, p.HasDuration = time.Duration(secs)*time.Second, true
+		case "preload":
+			p.Preload = true
+		}
+	}
+	return p, nil
+}
+
+// STSEntry is a policy remembered for a host.
+type STSEntry struct {
+	// Port is the port on which the host was last connected to securely.
+	Port    int       `json:"port"`
+	Expires time.Time `json:"expires"`
+	Preload bool      `json:"preload,omitempty"`
+}
+
+// STSStore persists STS policies by host name. Implementations must be safe
+// for concurrent use.
+type STSStore interface {
+	// Get returns the entry for host, if any.
+	Get(host string) (STSEntry, bool, error)
+	// Put records the entry for host, replacing any existing one.
+	Put(host string, e STSEntry) error
+	// Delete removes the entry for host, if any.
+	Delete(host string) error
+}
+
+// MemorySTSStore is an STSStore that holds policies in memory.
+type MemorySTSStore struct {
+	mu      sync.Mutex
+	entries map[string]STSEntry
+}
+
+// NewMemorySTSStore returns an empty MemorySTSStore.
+func NewMemorySTSStore() *MemorySTSStore {
+	return &MemorySTSStore{entries: make(map[string]STSEntry)}
+}
+
+// Get returns the entry for host, if any.
+func (s *MemorySTSStore) Get(host string) (STSEntry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[stsHost(host)]
+	return e, ok, nil
+}
+
+// Put records the entry for host.
+func (s *MemorySTSStore) Put(host string, e STSEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[stsHost(host)] = e
+	return nil
+}
+
+// Delete removes the entry for host.
+func (s *MemorySTSStore) Delete(host string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, stsHost(host))
+	return nil
+}
+
+// FileSTSStore is an STSStore that keeps policies in a JSON file, which is
+// rewritten whenever they change. A process should not share the file with
+// another.
+type FileSTSStore struct {
+	name string
+	mem  *MemorySTSStore
+}
+
+// OpenSTSStore returns a FileSTSStore for the named file, loading any
+// policies it holds. A missing file is treated as empty.
+func OpenSTSStore(name string) (*FileSTSStore, error) {
+	s := &FileSTSStore{name: name, mem: NewMemorySTSStore()}
+	b, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &s.mem.entries); err != nil {
+		return nil, err
+	}
+	if s.mem.entries == nil {
+		s.mem.entries = make(map[string]STSEntry)
+	}
+	return s, nil
+}
+
+// Get returns the entry for host, if any.
+func (s *FileSTSStore) Get(host string) (STSEntry, bool, error) {
+	return s.mem.Get(host)
+}
+
+// Put records the entry for host and saves the file.
+func (s *FileSTSStore) Put(host string, e STSEntry) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	s.mem.entries[stsHost(host)] = e
+	return s.save()
+}
+
+// Delete removes the entry for host and saves the file.
+func (s *FileSTSStore) Delete(host string) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	if _, ok := s.mem.entries[stsHost(host)]; !ok {
+		return nil
+	}
+	delete(s.mem.entries, stsHost(host))
+	return s.save()
+}
+
+// save writes the entries to a temporary file which then replaces the
+// store's, so that a crash cannot leave it truncated. The caller must hold
+// the lock.
+func (s *FileSTSStore) save() error {
+	b, err := json.MarshalIndent(s.mem.entries, "", "\t")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(s.name), filepath.Base(s.name)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.name)
+}
+
+// stsHost returns the key under which policies for host are stored.
+// Policies apply to host names regardless of port.
+func stsHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// STSEnforcer applies the STS policies in a store to connections, telling a
+// client when it must use TLS and recording the policies it is given.
+type STSEnforcer struct {
+	store STSStore
+	clock Clock
+}
+
+// NewSTSEnforcer returns an STSEnforcer backed by store.
+func NewSTSEnforcer(store STSStore) *STSEnforcer {
+	return &STSEnforcer{store: store, clock: SystemClock}
+}
+
+// SetClock sets the clock by which policies expire.
+func (e *STSEnforcer) SetClock(c Clock) { e.clock = c }
+
+// Connect reports whether a connection to host must use TLS, and if so the
+// port to use in place of the one configured. An expired policy is removed
+// from the store. Policies never apply to IP addresses.
+func (e *STSEnforcer) Connect(host string) (tls bool, port int, err error) {
+	if isIPHost(host) {
+		return false, 0, nil
+	}
+	entry, ok, err := e.store.Get(host)
+	if err != nil || !ok {
+		return false, 0, err
+	}
+	if !e.clock.Now().Before(entry.Expires) {
+		return false, 0, e.store.Delete(host)
+	}
+	return true, entry.Port, nil
+}
+
+// Advertised handles the value of the sts capability advertised by host on
+// a connection to port, which is secure if it uses TLS. On an insecure
+// connection it returns the port the client must disconnect and reconnect
+// to with TLS, or 0 if the policy has none. On a secure connection the
+// policy is recorded, or forgotten if its duration is 0, and 0 is returned.
+//
+// Clients should also call Advertised with the last value seen when
+// disconnecting from a secure connection, to extend the policy's expiry.
+//
+// As the specification requires, policies advertised on connections to IP
+// addresses are ignored.
+func (e *STSEnforcer) Advertised(host string, port int, secure bool, value string) (upgrade int, err error) {
+	if isIPHost(host) {
+		return 0, nil
+	}
+	p, err := ParseSTSPolicy(value)
+	if err != nil {
+		return 0, err
+	}
+	if !secure {
+		return p.Port, nil
+	}
+	switch {
+	case !p.HasDuration:
+		return 0, nil
+	case p.Duration == 0:
+		return 0, e.store.Delete(host)
+	}
+	return 0, e.store.Put(host, STSEntry{
+		Port:    port,
+		Expires: e.clock.Now().Add(p.Duration),
+		Preload: p.Preload,
+	})
+}
+
+// isIPHost reports whether host is an IP address rather than a hostname.
+func isIPHost(host string) bool {
+	return net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")) != nil
+}
@@ -0,0 +1,96 @@
+package ircmessage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var stsPolicyTests = []struct {
+	in       string
+	expected STSPolicy
+	err      error
+}{
+	{"port=6697", STSPolicy{Port: 6697}, nil},
+	{"duration=300,preload", STSPolicy{Duration: 5 * time.Minute, HasDuration: true, Preload: true}, nil},
+	{"duration=0,future=1", STSPolicy{HasDuration: true}, nil},
+	{"port=0", STSPolicy{}, ErrBadSTSPolicy},
+	{"port=70000", STSPolicy{}, ErrBadSTSPolicy},
+	{"duration=-1", STSPolicy{}, ErrBadSTSPolicy},
+	{"duration=99999999999999999999", STSPolicy{}, ErrBadSTSPolicy},
+}
+
+func TestParseSTSPolicy(t *testing.T) {
+	for i, tt := range stsPolicyTests {
+		p, err := ParseSTSPolicy(tt.in)
+		if err != tt.err || p != tt.expected {
+			t.Errorf("%d. expecting %+v %v, got %+v %v", i, tt.expected, tt.err, p, err)
+		}
+	}
+}
+
+func TestSTSEnforcer(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := NewSTSEnforcer(NewMemorySTSStore())
+	e.SetClock(funcClock{now: func() time.Time { return now }})
+
+	if tls, _, _ := e.Connect("irc.example.org"); tls {
+		t.Error("expecting no policy for an unknown host")
+	}
+	if port, _ := e.Advertised("irc.example.org", 6667, false, "port=6697,duration=60"); port != 6697 {
+		t.Errorf("expecting upgrade to 6697, got %d", port)
+	}
+	if tls, _, _ := e.Connect("irc.example.org"); tls {
+		t.Error("expecting a policy from an insecure connection to be ignored")
+	}
+	if port, _ := e.Advertised("irc.example.org", 6697, true, "duration=60"); port != 0 {
+		t.Errorf("expecting no upgrade on a secure connection, got %d", port)
+	}
+	if tls, port, _ := e.Connect("IRC.example.org."); !tls || port != 6697 {
+		t.Errorf("expecting TLS on 6697, got %v %d", tls, port)
+	}
+	now = now.Add(time.Minute)
+	if tls, _, _ := e.Connect("irc.example.org"); tls {
+		t.Error("expecting the policy to have expired")
+	}
+	e.Advertised("irc.example.org", 6697, true, "duration=60")
+	e.Advertised("irc.example.org", 6697, true, "duration=0")
+	if tls, _, _ := e.Connect("irc.example.org"); tls {
+		t.Error("expecting a duration of 0 to remove the policy")
+	}
+	for _, host := range []string{"192.0.2.1", "2001:db8::1", "[2001:db8::1]"} {
+		if port, _ := e.Advertised(host, 6667, false, "port=6697,duration=60"); port != 0 {
+			t.Errorf("expecting no upgrade for %s, got %d", host, port)
+		}
+		e.Advertised(host, 6697, true, "duration=60")
+		if tls, _, _ := e.Connect(host); tls {
+			t.Errorf("expecting no policy for %s", host)
+		}
+	}
+}
+
+func TestFileSTSStore(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "sts.json")
+	s, err := OpenSTSStore(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := s.Put("irc.example.org", STSEntry{Port: 6697, Expires: expires}); err != nil {
+		t.Fatal(err)
+	}
+	s.Put("other.example.org", STSEntry{Port: 7000, Expires: expires})
+	s.Delete("other.example.org")
+
+	s, err = OpenSTSStore(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, ok, _ := s.Get("irc.example.org")
+	if !ok || e.Port != 6697 || !e.Expires.Equal(expires) {
+		t.Errorf("expecting the policy to be reloaded, got %+v %v", e, ok)
+	}
+	if _, ok, _ := s.Get("other.example.org"); ok {
+		t.Error("expecting the deleted policy to stay deleted")
+	}
+}
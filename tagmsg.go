@@ -0,0 +1,55 @@
+package ircmessage
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNoClientTags is returned by TagMsg when none of the tags given are
+// client-only tags.
+var ErrNoClientTags = errors.New("no client-only tags")
+
+// isClientTag reports whether key names a client-only tag, which is
+// prefixed with '+' as per:
+// https://ircv3.net/specs/extensions/message-tags#client-only-tags
+func isClientTag(key string) bool {
+	return strings.HasPrefix(key, "+")
+}
+
+// TagMsg returns a TAGMSG message sending tags to target. At least one of
+// the tags must be a client-only tag, otherwise the message would carry
+// nothing for the recipient.
+func TagMsg(target string, tags map[string]string) (Message, error) {
+	for k := range tags {
+		if isClientTag(k) {
+			return Message{Tags: tags, Command: "TAGMSG", Params: []string{target}}, nil
+		}
+	}
+	return Message{}, ErrNoClientTags
+}
+
+// ClientTags returns the client-only tags of m, or nil if there are none.
+func ClientTags(m Message) map[string]string {
+	var tags map[string]string
+	for k, v := range m.Tags {
+		if isClientTag(k) {
+			if tags == nil {
+				tags = make(map[string]string)
+			}
+			tags[k] = v
+		}
+	}
+	return tags
+}
+
+// IsTagMsg reports whether m is a TAGMSG, which carries only tags and must
+// not be displayed as a text message.
+func IsTagMsg(m Message) bool {
+	return HasCommand(m, "TAGMSG")
+}
+
+// IsTextMessage reports whether m is a PRIVMSG or NOTICE carrying text.
+func IsTextMessage(m Message) bool {
+	return (HasCommand(m, "PRIVMSG") || HasCommand(m, "NOTICE")) &&
+		len(m.Params) > 1
+}
@@ -0,0 +1,29 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTagMsg(t *testing.T) {
+	tags := map[string]string{"+typing": "active", "label": "1"}
+	m, err := TagMsg("#chan", tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsTagMsg(m) || IsTextMessage(m) || !reflect.DeepEqual(m.Params, []string{"#chan"}) {
+		t.Errorf("unexpected message %v", m)
+	}
+	if ct := ClientTags(m); !reflect.DeepEqual(ct, map[string]string{"+typing": "active"}) {
+		t.Errorf("unexpected client tags %v", ct)
+	}
+	if _, err := TagMsg("#chan", map[string]string{"label": "1"}); err != ErrNoClientTags {
+		t.Errorf("expecting %v, got %v", ErrNoClientTags, err)
+	}
+	if ClientTags(Message{Command: "PING"}) != nil {
+		t.Error("expecting nil client tags for an untagged message")
+	}
+	if !IsTextMessage(Message{Command: "notice", Params: []string{"#chan", "hi"}}) {
+		t.Error("expecting NOTICE to be a text message")
+	}
+}
@@ -0,0 +1,21 @@
+package ircmessage
+
+// TagPolicy determines how a Scanner treats a malformed tag, such as one
+// whose value contains an unescaped equals sign.
+type TagPolicy int
+
+const (
+	// TagsFail treats the whole message as malformed. This is the
+	// default.
+	TagsFail TagPolicy = iota
+	// TagsDrop discards the malformed tag and keeps the rest.
+	TagsDrop
+	// TagsKeepRaw stores the raw text of malformed tags under
+	// MalformedTagKey, separated by semicolons, for relays that must
+	// pass them on. The Encoder writes them back unchanged.
+	TagsKeepRaw
+)
+
+// MalformedTagKey is the key under which TagsKeepRaw stores malformed tags.
+// It cannot clash with a valid tag key.
+const MalformedTagKey = "!malformed"
@@ -0,0 +1,85 @@
+package ircmessage
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var tagPolicyTests = []struct {
+	policy   TagPolicy
+	expected map[string]string
+	err      error
+}{
+	{TagsFail, nil, ErrMessageMalformed},
+	{TagsDrop, map[string]string{"a": "b", "c": ""}, nil},
+	{TagsKeepRaw, map[string]string{"a": "b", "c": "", MalformedTagKey: "x=y=z;w=1=2"}, nil},
+}
+
+func TestScannerTagPolicy(t *testing.T) {
+	for i, tt := range tagPolicyTests {
+		for _, reuse := range []bool{false, true} {
+			s := NewScanner(strings.NewReader("@a=b;x=y=z;c;w=1=2 PING\r\n"))
+			s.SetProfile(Profile{BadTags: tt.policy})
+			if reuse {
+				s.ReuseStorage()
+			}
+			s.Scan()
+			if !errors.Is(s.Err(), tt.err) {
+				t.Errorf("%d. expecting error %v, got %v", i, tt.err, s.Err())
+			}
+			if m := s.Message(); !reflect.DeepEqual(m.Tags, tt.expected) {
+				t.Errorf("%d. expecting tags %v, got %v", i, tt.expected, m.Tags)
+			}
+		}
+	}
+}
+
+func TestEncoderMalformedTags(t *testing.T) {
+	var buf bytes.Buffer
+	m := Message{Tags: map[string]string{"a": "b", MalformedTagKey: "x=y=z;w=1=2"}, Command: "PING"}
+	if err := NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "@x=y=z;w=1=2;a=b PING\r\n"; buf.String() != expected {
+		t.Errorf("expecting %q, got %q", expected, buf.String())
+	}
+}
+
+var tagEscapeTests = []struct {
+	in      string
+	value   string
+	invalid bool
+}{
+	{`a\sb\:c\\d\re\nf`, "a b;c\\d\re\nf", false},
+	{`a\bc`, "abc", true},
+	{`a\`, "a", true},
+	{`\\\`, `\`, true},
+	{`\\\\`, `\\`, false},
+	{`caf\é`, "café", true},
+}
+
+func TestTagEscapes(t *testing.T) {
+	for i, tt := range tagEscapeTests {
+		for _, reuse := range []bool{false, true} {
+			s := NewScanner(strings.NewReader("@a=" + tt.in + " PING\r\n"))
+			if reuse {
+				s.ReuseStorage()
+			}
+			if !s.Scan() {
+				t.Fatalf("%d. %v", i, s.Err())
+			}
+			if v := s.Message().Tags["a"]; v != tt.value {
+				t.Errorf("%d. expecting %q, got %q", i, tt.value, v)
+			}
+		}
+		s := NewScanner(strings.NewReader("@a=" + tt.in + " PING\r\n"))
+		s.SetProfile(Profile{StrictEscapes: true})
+		s.Scan()
+		if err := s.Err(); errors.Is(err, ErrBadTag) != tt.invalid {
+			t.Errorf("%d. strict: expecting invalid %t, got %v", i, tt.invalid, err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package ircmessage
+
+// Terminator selects how an Encoder ends each message it writes.
+type Terminator int
+
+const (
+	// TerminatorCRLF ends messages with CRLF as IRC requires. This is
+	// the default.
+	TerminatorCRLF Terminator = iota
+	// TerminatorLF ends messages with a bare LF, as suits log files.
+	TerminatorLF
+	// TerminatorNone writes messages with no terminator, for transports
+	// such as WebSocket that frame messages themselves.
+	TerminatorNone
+)
+
+// SetTerminator sets how the Encoder ends each message. Size limits are
+// applied as if every message ended with CRLF, whatever the terminator.
+func (e *Encoder) SetTerminator(t Terminator) {
+	e.terminator = t
+}
+
+// terminate replaces the CRLF ending b with t.
+func (t Terminator) terminate(b []byte) []byte {
+	switch t {
+	case TerminatorLF:
+		b = append(b[:len(b)-2], '\n')
+	case TerminatorNone:
+		b = b[:len(b)-2]
+	}
+	return b
+}
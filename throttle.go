@@ -0,0 +1,62 @@
+package ircmessage
+
+import "time"
+
+// Throttle is a named outgoing rate limit for use with a
+// RateLimitedWriter: Burst messages at once, then one more for each Refill
+// interval. Define a custom Throttle with a literal or PerWindow.
+type Throttle struct {
+	Name   string
+	Burst  int
+	Refill time.Duration
+}
+
+// Presets for well-known networks. The IRC networks publish no exact
+// figures; their presets keep within the penalty models of the ircds they
+// run, which allow a short burst and then about one line every two
+// seconds. Twitch documents its limits as messages per 30 seconds.
+var (
+	ThrottleLibera = Throttle{Name: "libera", Burst: 5, Refill: 2 * time.Second}
+	ThrottleOFTC   = Throttle{Name: "oftc", Burst: 5, Refill: 2 * time.Second}
+	ThrottleEFnet  = Throttle{Name: "efnet", Burst: 4, Refill: 2 * time.Second}
+	// ThrottleTwitch is for ordinary accounts, allowed 20 messages every
+	// 30 seconds.
+	ThrottleTwitch = PerWindow("twitch", 20, 30*time.Second)
+	// ThrottleTwitchModerator is for accounts that moderate the channel
+	// or are VIPs in it, allowed 100 messages every 30 seconds.
+	ThrottleTwitchModerator = PerWindow("twitch-moderator", 100, 30*time.Second)
+	// ThrottleTwitchVerified is for verified bots, allowed 7500
+	// messages every 30 seconds.
+	ThrottleTwitchVerified = PerWindow("twitch-verified", 7500, 30*time.Second)
+)
+
+var throttlePresets = []Throttle{
+	ThrottleLibera, ThrottleOFTC, ThrottleEFnet,
+	ThrottleTwitch, ThrottleTwitchModerator, ThrottleTwitchVerified,
+}
+
+// PerWindow returns a Throttle for a network that allows n messages in any
+// window of the given length. Messages are spread evenly across the
+// window, without a burst, so that no window sees more than n.
+func PerWindow(name string, n int, window time.Duration) Throttle {
+	if n < 1 {
+		n = 1
+	}
+	return Throttle{Name: name, Burst: 1, Refill: window / time.Duration(n)}
+}
+
+// LookupThrottle returns the preset with the given name, compared
+// case-insensitively.
+func LookupThrottle(name string) (Throttle, bool) {
+	for _, t := range throttlePresets {
+		if equalFoldASCII(t.Name, name) {
+			return t, true
+		}
+	}
+	return Throttle{}, false
+}
+
+// Writer returns a RateLimitedWriter that writes to enc within t.
+func (t Throttle) Writer(enc *Encoder) *RateLimitedWriter {
+	return NewRateLimitedWriter(enc, t.Burst, t.Refill)
+}
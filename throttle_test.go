@@ -0,0 +1,48 @@
+package ircmessage
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestThrottleWindow(t *testing.T) {
+	for _, th := range []Throttle{ThrottleTwitch, ThrottleTwitchModerator, ThrottleTwitchVerified, PerWindow("x", 3, time.Second)} {
+		var now time.Time
+		var sent []time.Time
+		w := th.Writer(NewEncoder(io.Discard))
+		w.SetClock(funcClock{now: func() time.Time { return now }, sleep: func(d time.Duration) { now = now.Add(d) }})
+		for i := 0; i < 3*th.Burst+20; i++ {
+			w.Encode(Message{Command: "PING"})
+			sent = append(sent, now)
+		}
+		// No window may see more messages than the limit allows.
+		window := th.Refill * time.Duration(th.Burst)
+		limit := th.Burst
+		if th.Burst == 1 {
+			window = th.Refill * 10
+			limit = 10
+		}
+		for i := range sent {
+			n := 0
+			for j := i; j < len(sent) && sent[j].Sub(sent[i]) < window; j++ {
+				n++
+			}
+			if n > limit {
+				t.Fatalf("%s: %d messages within %v", th.Name, n, window)
+			}
+		}
+	}
+}
+
+func TestLookupThrottle(t *testing.T) {
+	if th, ok := LookupThrottle("Libera"); !ok || th != ThrottleLibera {
+		t.Errorf("expecting the libera preset, got %+v %v", th, ok)
+	}
+	if th, ok := LookupThrottle("twitch-verified"); !ok || th.Refill != 4*time.Millisecond {
+		t.Errorf("expecting verified Twitch bots to send every 4ms, got %+v %v", th, ok)
+	}
+	if _, ok := LookupThrottle("unknown"); ok {
+		t.Error("expecting no preset for an unknown network")
+	}
+}
@@ -0,0 +1,60 @@
+package ircmessage
+
+import (
+	"strconv"
+	"time"
+)
+
+// TimeRequest returns the TIME message asking for the local time of the
+// server target, or of the current server if it is empty.
+func TimeRequest(target string) Message { return serverQuery("TIME", target) }
+
+// TimeReply is the reply to a TIME request.
+type TimeReply struct {
+	Server string
+	Time   time.Time
+	Text   string // The time as the server wrote it.
+}
+
+// Skew returns how far the server's clock is ahead of local, the time the
+// reply was received. Human readable times are only precise to the second.
+func (r TimeReply) Skew(local time.Time) time.Duration { return r.Time.Sub(local) }
+
+// timeReplyLayouts are the human readable formats servers use for the time,
+// tried in order.
+var timeReplyLayouts = []string{
+	"Monday January 2 2006 -- 15:04:05 -07:00", // ratbox family and UnrealIRCd.
+	"Monday January 2 2006 -- 15:04:05 -0700",
+	"Monday January 2 2006 -- 15:04 -07:00",
+	"Monday January 2 2006 -- 15:04 -0700",
+	"Mon Jan 2 2006 -- 15:04:05 -07:00",
+	time.RFC1123,
+	time.RFC1123Z,
+	time.UnixDate,
+	time.ANSIC,
+	time.RFC3339,
+}
+
+// ParseTimeReply parses RPL_TIME (391). Servers that send a Unix timestamp
+// before the human readable time, as some do, have it used; otherwise the
+// text is parsed in one of the formats servers commonly use. It returns
+// false for any other message, or if the time cannot be understood.
+func ParseTimeReply(m Message) (TimeReply, bool) {
+	if m.Command != "391" || len(m.Params) < 3 {
+		return TimeReply{}, false
+	}
+	r := TimeReply{Server: m.Params[1], Text: m.Params[len(m.Params)-1]}
+	if len(m.Params) > 3 {
+		if ts, err := strconv.ParseInt(m.Params[2], 10, 64); err == nil {
+			r.Time = time.Unix(ts, 0)
+			return r, true
+		}
+	}
+	for _, layout := range timeReplyLayouts {
+		if t, err := time.Parse(layout, r.Text); err == nil {
+			r.Time = t
+			return r, true
+		}
+	}
+	return TimeReply{}, false
+}
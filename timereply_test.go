@@ -0,0 +1,33 @@
+package ircmessage
+
+import (
+	"testing"
+	"time"
+)
+
+var timeReplyTests = []struct {
+	params   []string
+	expected time.Time
+	ok       bool
+}{
+	{[]string{"me", "irc.example.org", "Friday January 1 2010 -- 12:34:56 +01:00"}, time.Date(2010, 1, 1, 11, 34, 56, 0, time.UTC), true},
+	{[]string{"me", "irc.example.org", "Friday January 01 2010 -- 12:34:56 +0100"}, time.Date(2010, 1, 1, 11, 34, 56, 0, time.UTC), true},
+	{[]string{"me", "irc.example.org", "Fri, 01 Jan 2010 12:34:56 UTC"}, time.Date(2010, 1, 1, 12, 34, 56, 0, time.UTC), true},
+	{[]string{"me", "irc.example.org", "Fri Jan  1 12:34:56 2010"}, time.Date(2010, 1, 1, 12, 34, 56, 0, time.UTC), true},
+	{[]string{"me", "irc.example.org", "1262349296", "0", "Friday January 1 2010 -- 12:34:56"}, time.Date(2010, 1, 1, 12, 34, 56, 0, time.UTC), true},
+	{[]string{"me", "irc.example.org", "teatime"}, time.Time{}, false},
+	{[]string{"me", "irc.example.org"}, time.Time{}, false},
+}
+
+func TestParseTimeReply(t *testing.T) {
+	for i, tt := range timeReplyTests {
+		r, ok := ParseTimeReply(Message{Command: "391", Params: tt.params})
+		if ok != tt.ok || !r.Time.Equal(tt.expected) || ok && r.Server != "irc.example.org" {
+			t.Errorf("%d. expecting %v %v, got %+v %v", i, tt.expected, tt.ok, r, ok)
+		}
+	}
+	r, _ := ParseTimeReply(Message{Command: "391", Params: timeReplyTests[0].params})
+	if skew := r.Skew(time.Date(2010, 1, 1, 11, 34, 50, 0, time.UTC)); skew != 6*time.Second {
+		t.Errorf("expecting a skew of 6s, got %v", skew)
+	}
+}
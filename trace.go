@@ -0,0 +1,58 @@
+package ircmessage
+
+// TraceKind identifies the step of parsing a TraceEvent describes.
+type TraceKind int
+
+const (
+	TraceTags    TraceKind = iota // The tag section was read.
+	TracePrefix                   // The prefix was read.
+	TraceCommand                  // The command was read.
+	TraceParams                   // The parameters were read.
+	TraceLine                     // A complete message was parsed.
+	TraceError                    // Parsing stopped with an error.
+)
+
+var traceKindNames = [...]string{"tags", "prefix", "command", "params", "line", "error"}
+
+func (k TraceKind) String() string {
+	if k < 0 || int(k) >= len(traceKindNames) {
+		return "unknown"
+	}
+	return traceKindNames[k]
+}
+
+// TraceEvent describes a step taken by the Scanner while parsing a line.
+type TraceEvent struct {
+	Kind TraceKind
+	// Start and End are the byte offsets of the component within the
+	// line, and Text holds its bytes exactly as received.
+	Start, End int
+	Text       string
+	// Consumed is the number of bytes of the line read so far.
+	Consumed int
+	Note     string // A description of any decision taken.
+	Err      error  // Set for TraceError.
+}
+
+// Trace sets fn to receive an event for each component of every line the
+// Scanner parses, which can help diagnose interoperability problems with
+// unusual servers. Tracing is slow and intended for debugging. Trace must be
+// called before the first call to Scan.
+func (s *Scanner) Trace(fn func(TraceEvent)) {
+	s.tracer = fn
+}
+
+func (s *Scanner) trace(kind TraceKind, sp span, note string, err error) {
+	if s.tracer == nil {
+		return
+	}
+	s.tracer(TraceEvent{
+		Kind:     kind,
+		Start:    sp.start,
+		End:      sp.end,
+		Text:     string(s.rawBuf[sp.start:sp.end]),
+		Consumed: len(s.rawBuf),
+		Note:     note,
+		Err:      err,
+	})
+}
@@ -0,0 +1,32 @@
+package ircmessage
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScannerTrace(t *testing.T) {
+	var events []string
+	s := NewScanner(strings.NewReader("@a=b :n PRIVMSG #c :hi\r\nPING\r\nBAD :" + strings.Repeat("x", 600)))
+	s.Trace(func(e TraceEvent) {
+		events = append(events, fmt.Sprintf("%v %d-%d %q %d %q %v", e.Kind, e.Start, e.End, e.Text, e.Consumed, e.Note, e.Err))
+	})
+	for s.Scan() {
+	}
+	expected := []string{
+		`tags 1-4 "a=b" 5 "tags present, size limit reset for the body" <nil>`,
+		`prefix 6-7 "n" 8 "prefix present" <nil>`,
+		`command 8-15 "PRIVMSG" 16 "" <nil>`,
+		`params 16-22 "#c :hi" 24 "trailing parameter present" <nil>`,
+		`line 0-24 "@a=b :n PRIVMSG #c :hi\r\n" 24 "" <nil>`,
+		`command 0-4 "PING" 4 "" <nil>`,
+		`line 0-6 "PING\r\n" 6 "" <nil>`,
+		`command 0-3 "BAD" 4 "" <nil>`,
+		`error 0-513 "BAD :` + strings.Repeat("x", 508) + `" 513 "" line too long at byte 513`,
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expecting\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(events, "\n"))
+	}
+}
@@ -0,0 +1,155 @@
+package ircmessage
+
+import (
+	"regexp"
+	"sync"
+)
+
+// A Trigger describes the messages a subscriber to Triggers is interested
+// in. Empty fields match anything, and a message must satisfy all the
+// others to match.
+type Trigger struct {
+	Command string // Compared case-insensitively.
+	// Target is a mask, as for MatchMask, that the first parameter must
+	// match, such as "#chan" or "#help-*".
+	Target string
+	// Sender is a mask that the prefix must match, such as
+	// "*!*@example.com".
+	Sender string
+	Text   *regexp.Regexp // Must match the last parameter.
+}
+
+// Match reports whether m satisfies t. Masks are compared using the
+// casemapping of isupport, which may be nil.
+func (t Trigger) Match(m Message, isupport *ISupport) bool {
+	if t.Command != "" && !CommandEqual(m.Command, t.Command) {
+		return false
+	}
+	if t.Target != "" && (len(m.Params) == 0 || !MatchMask(t.Target, m.Params[0], isupport)) {
+		return false
+	}
+	if t.Sender != "" && !MatchMask(t.Sender, m.Prefix, isupport) {
+		return false
+	}
+	if t.Text != nil && (len(m.Params) == 0 || !t.Text.MatchString(m.Params[len(m.Params)-1])) {
+		return false
+	}
+	return true
+}
+
+// Triggers is a registry of subscriptions to messages matching a Trigger,
+// for features such as highlights and automation. Unlike a Mux, every
+// matching subscriber receives a message, in the order they subscribed.
+//
+// The zero value is ready to use, and Triggers is safe for concurrent use.
+type Triggers struct {
+	mu       sync.RWMutex
+	isupport isupportRef
+	next     int
+	subs     []subscription
+}
+
+type subscription struct {
+	id int
+	t  Trigger
+	h  HandlerFunc
+}
+
+// SetISupport sets the server parameters whose casemapping masks are
+// compared with.
+func (tr *Triggers) SetISupport(isupport *ISupport) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.isupport.isupport = isupport
+}
+
+// SetNetworkConfig sets the store whose current casemapping masks are
+// compared with, in place of any set by SetISupport.
+func (tr *Triggers) SetNetworkConfig(c *NetworkConfig) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.isupport.config = c
+}
+
+// Subscribe arranges for h to be called with each message matching t, and
+// returns a function that cancels the subscription.
+func (tr *Triggers) Subscribe(t Trigger, h HandlerFunc) (cancel func()) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.next++
+	id := tr.next
+	tr.subs = append(tr.subs, subscription{id, t, h})
+	return func() {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		for i, s := range tr.subs {
+			if s.id == id {
+				tr.subs = append(tr.subs[:i:i], tr.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Dispatch calls the handler of every subscription matching m, reporting
+// whether there were any. Handlers are called without the registry locked,
+// so they may subscribe or cancel.
+func (tr *Triggers) Dispatch(m Message) bool {
+	tr.mu.RLock()
+	var matched []HandlerFunc
+	isupport := tr.isupport.get()
+	for _, s := range tr.subs {
+		if s.t.Match(m, isupport) {
+			matched = append(matched, s.h)
+		}
+	}
+	tr.mu.RUnlock()
+	for _, h := range matched {
+		h(m)
+	}
+	return len(matched) > 0
+}
+
+// Then returns a HandlerFunc that dispatches a message to the triggers and
+// then calls h, if it is not nil, for stacking the triggers on a Mux:
+//
+//	mux.Handle("PRIVMSG", triggers.Then(onPrivmsg))
+//	mux.Handle("*", triggers.Then(nil))
+func (tr *Triggers) Then(h HandlerFunc) HandlerFunc {
+	return func(m Message) {
+		tr.Dispatch(m)
+		if h != nil {
+			h(m)
+		}
+	}
+}
+
+// MatchMask reports whether name matches mask, in which * matches any run
+// of characters and ? matches any single character. The comparison uses the
+// casemapping of isupport, which may be nil.
+func MatchMask(mask, name string, isupport *ISupport) bool {
+	mask, name = isupport.Fold(mask), isupport.Fold(name)
+	// Match greedily, backtracking to the last star on a mismatch.
+	star, retry := -1, 0
+	i, j := 0, 0
+	for j < len(name) {
+		switch {
+		case i < len(mask) && mask[i] == '*':
+			star, retry = i, j
+			i++
+		case i < len(mask) && (mask[i] == '?' || mask[i] == name[j]):
+			i++
+			j++
+		case star >= 0:
+			i = star + 1
+			retry++
+			j = retry
+		default:
+			return false
+		}
+	}
+	for i < len(mask) && mask[i] == '*' {
+		i++
+	}
+	return i == len(mask)
+}
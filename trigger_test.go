@@ -0,0 +1,68 @@
+package ircmessage
+
+import (
+	"regexp"
+	"testing"
+)
+
+var maskTests = []struct {
+	mask, name string
+	expected   bool
+}{
+	{"*", "", true},
+	{"*", "anything", true},
+	{"#chan", "#CHAN", true},
+	{"#help-*", "#help-go", true},
+	{"#help-*", "#help", false},
+	{"n?ck", "nick", true},
+	{"n?ck", "nck", false},
+	{"*!*@*.example.com", "nick!user@host.example.com", true},
+	{"*!*@*.example.com", "nick!user@example.com", false},
+	{"*a*b", "xaxbxab", true},
+	{"*a*b", "xaxbxa", false},
+	{"nick[away]", "NICK{AWAY}", true},
+}
+
+func TestMatchMask(t *testing.T) {
+	for i, tt := range maskTests {
+		if got := MatchMask(tt.mask, tt.name, nil); got != tt.expected {
+			t.Errorf("%d. %q %q: expecting %v, got %v", i, tt.mask, tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestTriggers(t *testing.T) {
+	var tr Triggers
+	var highlights, joins, all int
+	tr.Subscribe(Trigger{Command: "PRIVMSG", Target: "#*", Text: regexp.MustCompile(`(?i)\bbob\b`)}, func(Message) { highlights++ })
+	tr.Subscribe(Trigger{Command: "join", Sender: "*!*@trusted.example"}, func(Message) { joins++ })
+	cancel := tr.Subscribe(Trigger{}, func(Message) { all++ })
+
+	msgs := []Message{
+		{Prefix: "a!u@h", Command: "PRIVMSG", Params: []string{"#chan", "hi Bob"}},
+		{Prefix: "a!u@h", Command: "PRIVMSG", Params: []string{"#chan", "bobcat"}},
+		{Prefix: "a!u@h", Command: "PRIVMSG", Params: []string{"bob", "hi bob"}},
+		{Prefix: "a!u@trusted.example", Command: "JOIN", Params: []string{"#chan"}},
+		{Prefix: "a!u@other.example", Command: "JOIN", Params: []string{"#chan"}},
+	}
+	for _, m := range msgs {
+		if !tr.Dispatch(m) {
+			t.Errorf("expecting %v to match the catch-all", m)
+		}
+	}
+	if highlights != 1 || joins != 1 || all != len(msgs) {
+		t.Errorf("expecting 1 highlight, 1 join and %d in all, got %d, %d and %d", len(msgs), highlights, joins, all)
+	}
+	cancel()
+	if tr.Dispatch(msgs[4]) {
+		t.Error("expecting no match after cancelling")
+	}
+
+	var mux Mux
+	var handled bool
+	mux.Handle("PRIVMSG", tr.Then(func(Message) { handled = true }))
+	mux.Dispatch(msgs[0])
+	if highlights != 2 || !handled {
+		t.Errorf("expecting the trigger and handler to run on the mux, got %d %v", highlights, handled)
+	}
+}
@@ -0,0 +1,105 @@
+package ircmessage
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UserNotice is a Twitch USERNOTICE, announcing an event such as a
+// subscription, gifted subscription or raid as per:
+// https://dev.twitch.tv/docs/irc/commands/#usernotice
+type UserNotice struct {
+	Channel     string
+	Login       string // The user who caused the event.
+	DisplayName string
+	// MsgID identifies the kind of event, such as "sub", "resub",
+	// "subgift" or "raid".
+	MsgID     string
+	SystemMsg string // Twitch's own description of the event.
+	Text      string // The user's message, if any.
+	// Params holds the msg-param- tags describing the event, keyed
+	// without that prefix, such as "cumulative-months" or "viewerCount".
+	Params map[string]string
+}
+
+// ParseUserNotice parses a Twitch USERNOTICE message.
+func ParseUserNotice(m Message) (UserNotice, error) {
+	if !HasCommand(m, "USERNOTICE") || len(m.Params) < 1 || m.Tags["msg-id"] == "" {
+		return UserNotice{}, ErrMessageMalformed
+	}
+	n := UserNotice{
+		Channel:     m.Params[0],
+		Login:       m.Tags["login"],
+		DisplayName: m.Tags["display-name"],
+		MsgID:       m.Tags["msg-id"],
+		SystemMsg:   m.Tags["system-msg"],
+		Params:      make(map[string]string),
+	}
+	if len(m.Params) > 1 {
+		n.Text = m.Params[1]
+	}
+	for k, v := range m.Tags {
+		if name, ok := strings.CutPrefix(k, "msg-param-"); ok {
+			n.Params[name] = v
+		}
+	}
+	return n, nil
+}
+
+// ClearChat is a Twitch CLEARCHAT, sent when a user is banned or timed out,
+// or the whole chat is cleared, as per:
+// https://dev.twitch.tv/docs/irc/commands/#clearchat
+type ClearChat struct {
+	Channel string
+	// User is the user whose messages are cleared, or empty when the
+	// whole chat is cleared.
+	User string
+	// Duration is the length of a timeout, or zero for a permanent ban
+	// or when the whole chat is cleared.
+	Duration time.Duration
+}
+
+// Ban reports whether c permanently bans a user.
+func (c ClearChat) Ban() bool { return c.User != "" && c.Duration == 0 }
+
+// ParseClearChat parses a Twitch CLEARCHAT message.
+func ParseClearChat(m Message) (ClearChat, error) {
+	if !HasCommand(m, "CLEARCHAT") || len(m.Params) < 1 {
+		return ClearChat{}, ErrMessageMalformed
+	}
+	c := ClearChat{Channel: m.Params[0]}
+	if len(m.Params) > 1 {
+		c.User = m.Params[1]
+	}
+	if v, ok := m.Tags["ban-duration"]; ok {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 {
+			return ClearChat{}, ErrMessageMalformed
+		}
+		c.Duration = time.Duration(secs) * time.Second
+	}
+	return c, nil
+}
+
+// ClearMsg is a Twitch CLEARMSG, sent when a single message is deleted, as
+// per: https://dev.twitch.tv/docs/irc/commands/#clearmsg
+type ClearMsg struct {
+	Channel string
+	Login   string // The author of the deleted message.
+	MsgID   string // The id tag of the deleted message.
+	Text    string // The deleted message.
+}
+
+// ParseClearMsg parses a Twitch CLEARMSG message.
+func ParseClearMsg(m Message) (ClearMsg, error) {
+	if !HasCommand(m, "CLEARMSG") || len(m.Params) < 2 || m.Tags["target-msg-id"] == "" {
+		return ClearMsg{}, ErrMessageMalformed
+	}
+	return ClearMsg{
+		Channel: m.Params[0],
+		Login:   m.Tags["login"],
+		MsgID:   m.Tags["target-msg-id"],
+		Text:    m.Params[1],
+	}, nil
+}
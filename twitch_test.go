@@ -0,0 +1,79 @@
+package ircmessage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scanOne(t *testing.T, line string) Message {
+	t.Helper()
+	s := NewScanner(strings.NewReader(line + "\r\n"))
+	s.SetProfile(Twitch)
+	if !s.Scan() {
+		t.Fatalf("%q: %v", line, s.Err())
+	}
+	return s.Message()
+}
+
+func TestParseUserNotice(t *testing.T) {
+	m := scanOne(t, `@badges=staff/1;display-name=Ronni;login=ronni;msg-id=resub;msg-param-cumulative-months=6;msg-param-sub-plan=Prime;system-msg=ronni\shas\ssubscribed\sfor\s6\smonths! :tmi.twitch.tv USERNOTICE #dallas :Great stream -- keep it up!`)
+	n, err := ParseUserNotice(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := UserNotice{
+		Channel:     "#dallas",
+		Login:       "ronni",
+		DisplayName: "Ronni",
+		MsgID:       "resub",
+		SystemMsg:   "ronni has subscribed for 6 months!",
+		Text:        "Great stream -- keep it up!",
+		Params:      map[string]string{"cumulative-months": "6", "sub-plan": "Prime"},
+	}
+	if !reflect.DeepEqual(n, expected) {
+		t.Errorf("expecting %#v, got %#v", expected, n)
+	}
+	m = scanOne(t, `@login=raider;msg-id=raid;msg-param-viewerCount=15 :tmi.twitch.tv USERNOTICE #c`)
+	if n, err := ParseUserNotice(m); err != nil || n.Params["viewerCount"] != "15" || n.Text != "" {
+		t.Errorf("unexpected raid %#v %v", n, err)
+	}
+	if _, err := ParseUserNotice(scanOne(t, ":tmi.twitch.tv USERNOTICE #c")); err != ErrMessageMalformed {
+		t.Errorf("expecting error %v, got %v", ErrMessageMalformed, err)
+	}
+}
+
+var clearChatTests = []struct {
+	in       string
+	expected ClearChat
+	ban      bool
+	err      error
+}{
+	{":tmi.twitch.tv CLEARCHAT #c", ClearChat{Channel: "#c"}, false, nil},
+	{"@room-id=1 :tmi.twitch.tv CLEARCHAT #c :ronni", ClearChat{Channel: "#c", User: "ronni"}, true, nil},
+	{"@ban-duration=350 :tmi.twitch.tv CLEARCHAT #c :ronni", ClearChat{Channel: "#c", User: "ronni", Duration: 350 * time.Second}, false, nil},
+	{"@ban-duration=x :tmi.twitch.tv CLEARCHAT #c :ronni", ClearChat{}, false, ErrMessageMalformed},
+	{":tmi.twitch.tv CLEARCHAT", ClearChat{}, false, ErrMessageMalformed},
+}
+
+func TestParseClearChat(t *testing.T) {
+	for i, tt := range clearChatTests {
+		c, err := ParseClearChat(scanOne(t, tt.in))
+		if err != tt.err || c != tt.expected || c.Ban() != tt.ban {
+			t.Errorf("%d. expecting %+v %v, got %+v %v", i, tt.expected, tt.err, c, err)
+		}
+	}
+}
+
+func TestParseClearMsg(t *testing.T) {
+	m := scanOne(t, "@login=ronni;room-id=;target-msg-id=abc-123-def;tmi-sent-ts=1642720582342 :tmi.twitch.tv CLEARMSG #dallas :HeyGuys")
+	c, err := ParseClearMsg(m)
+	expected := ClearMsg{Channel: "#dallas", Login: "ronni", MsgID: "abc-123-def", Text: "HeyGuys"}
+	if err != nil || c != expected {
+		t.Errorf("expecting %+v, got %+v %v", expected, c, err)
+	}
+	if _, err := ParseClearMsg(scanOne(t, ":tmi.twitch.tv CLEARMSG #dallas :HeyGuys")); err != ErrMessageMalformed {
+		t.Errorf("expecting error %v, got %v", ErrMessageMalformed, err)
+	}
+}
@@ -0,0 +1,140 @@
+package ircmessage
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidURL is returned by ParseURL when the input is not an irc://,
+// ircs:// or irc6:// URL.
+var ErrInvalidURL = errors.New("invalid irc url")
+
+// URL holds the connection parameters described by an IRC URL.
+type URL struct {
+	Host string
+	Port int
+	TLS  bool
+	// Nick is the nickname given in the user part of the URL, if any.
+	Nick string
+	// Channels lists the channels to join, with Keys holding the key
+	// for the channel at the same index, or an empty string.
+	Channels []string
+	Keys     []string
+	// Query is set instead of Channels when the URL names a user to
+	// open a private conversation with.
+	Query string
+}
+
+// ParseURL parses an IRC URL following the common conventions, such as
+// ircs://nick@irc.example.net:6697/chan1,chan2?key=secret or
+// irc://irc.example.net/user,isnick. Channel names without a channel
+// prefix are given a '#' prefix.
+func ParseURL(s string) (*URL, error) {
+	// Some clients mark TLS ports with a plus sign, which
+	// net/url rejects.
+	plusPort := false
+	if _, rest, ok := strings.Cut(s, "://"); ok {
+		authority := rest
+		if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+			authority = rest[:i]
+		}
+		if i := strings.LastIndex(authority, ":+"); i >= 0 {
+			at := len(s) - len(rest) + i
+			s = s[:at+1] + s[at+2:]
+			plusPort = true
+		}
+	}
+	raw, err := url.Parse(s)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	u := &URL{Host: raw.Hostname()}
+	switch strings.ToLower(raw.Scheme) {
+	case "irc", "irc6":
+		u.Port = 6667
+	case "ircs":
+		u.TLS = true
+		u.Port = 6697
+	default:
+		return nil, ErrInvalidURL
+	}
+	if u.Host == "" {
+		return nil, ErrInvalidURL
+	}
+	if p := raw.Port(); p != "" {
+		if u.Port, err = strconv.Atoi(p); err != nil {
+			return nil, ErrInvalidURL
+		}
+		u.TLS = u.TLS || plusPort
+	}
+	if raw.User != nil {
+		u.Nick = raw.User.Username()
+	}
+	// An unescaped '#' begins the fragment, so irc://host/#chan puts the
+	// channel in the fragment rather than the path.
+	target := strings.TrimPrefix(raw.Path, "/")
+	if raw.Fragment != "" {
+		target += "#" + raw.Fragment
+	}
+	var names []string
+	isNick := false
+	for _, part := range strings.Split(target, ",") {
+		switch part {
+		case "":
+		case "isnick":
+			isNick = true
+		case "isserver", "needpass", "needkey":
+		default:
+			names = append(names, part)
+		}
+	}
+	if isNick {
+		if len(names) > 0 {
+			u.Query = names[0]
+		}
+		return u, nil
+	}
+	var keys []string
+	if k := raw.Query().Get("key"); k != "" {
+		keys = strings.Split(k, ",")
+	}
+	for i, name := range names {
+		if !strings.ContainsRune("#&+!", rune(name[0])) {
+			name = "#" + name
+		}
+		key := ""
+		if i < len(keys) {
+			key = keys[i]
+		}
+		u.Channels = append(u.Channels, name)
+		u.Keys = append(u.Keys, key)
+	}
+	return u, nil
+}
+
+// Addr returns the host and port in a form suitable for net.Dial.
+func (u *URL) Addr() string {
+	host := u.Host
+	if strings.Contains(host, tokenColon) {
+		host = "[" + host + "]"
+	}
+	return host + tokenColon + strconv.Itoa(u.Port)
+}
+
+// JoinMessage returns the JOIN message for the channels in u, and false if
+// there are none.
+func (u *URL) JoinMessage() (Message, bool) {
+	if len(u.Channels) == 0 {
+		return Message{}, false
+	}
+	params := []string{strings.Join(u.Channels, ",")}
+	for _, k := range u.Keys {
+		if k != "" {
+			params = append(params, strings.Join(u.Keys, ","))
+			break
+		}
+	}
+	return Message{Command: "JOIN", Params: params}, true
+}
@@ -0,0 +1,49 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+var urlTests = []struct {
+	in       string
+	expected *URL
+	err      error
+}{
+	{"irc://irc.example.net", &URL{Host: "irc.example.net", Port: 6667}, nil},
+	{"ircs://irc.example.net/", &URL{Host: "irc.example.net", Port: 6697, TLS: true}, nil},
+	{"irc://irc.example.net:+7000/chan", &URL{Host: "irc.example.net", Port: 7000, TLS: true, Channels: []string{"#chan"}, Keys: []string{""}}, nil},
+	{
+		"ircs://nick@irc.example.net:6698/chan,&local?key=secret",
+		&URL{Host: "irc.example.net", Port: 6698, TLS: true, Nick: "nick", Channels: []string{"#chan", "&local"}, Keys: []string{"secret", ""}},
+		nil,
+	},
+	{"irc://irc.example.net/#chan", &URL{Host: "irc.example.net", Port: 6667, Channels: []string{"#chan"}, Keys: []string{""}}, nil},
+	{"irc://irc.example.net/%23chan,needkey", &URL{Host: "irc.example.net", Port: 6667, Channels: []string{"#chan"}, Keys: []string{""}}, nil},
+	{"irc://irc.example.net/someone,isnick", &URL{Host: "irc.example.net", Port: 6667, Query: "someone"}, nil},
+	{"http://irc.example.net/chan", nil, ErrInvalidURL},
+	{"irc:///chan", nil, ErrInvalidURL},
+}
+
+func TestParseURL(t *testing.T) {
+	for i, tt := range urlTests {
+		u, err := ParseURL(tt.in)
+		if err != tt.err {
+			t.Errorf("%d. expecting error %v, got %v", i, tt.err, err)
+		}
+		if !reflect.DeepEqual(u, tt.expected) {
+			t.Errorf("%d. expecting %+v, got %+v", i, tt.expected, u)
+		}
+	}
+}
+
+func TestURLJoinMessage(t *testing.T) {
+	u, _ := ParseURL("ircs://[::1]/a,b?key=k1")
+	if u.Addr() != "[::1]:6697" {
+		t.Errorf("unexpected address %q", u.Addr())
+	}
+	m, ok := u.JoinMessage()
+	if !ok || !reflect.DeepEqual(m.Params, []string{"#a,#b", "k1,"}) {
+		t.Errorf("unexpected join message %v", m)
+	}
+}
@@ -0,0 +1,135 @@
+package ircmessage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidChannel is returned for a malformed channel name.
+	ErrInvalidChannel = errors.New("invalid channel name")
+	// ErrInvalidNick is returned for a malformed nickname.
+	ErrInvalidNick = errors.New("invalid nickname")
+	// ErrNameTooLong is returned for a name longer than the server allows.
+	ErrNameTooLong = errors.New("name too long")
+)
+
+// ValidChannel checks that name is a channel name the server described by
+// isupport would accept: it must begin with one of the CHANTYPES, contain
+// no spaces, commas, BELs, NULs or line endings, and fit within CHANNELLEN.
+// A nil isupport applies the defaults.
+func ValidChannel(name string, isupport *ISupport) error {
+	if !isupport.IsChannel(name) || len(name) < 2 || strings.ContainsAny(name, " ,\x07\x00\r\n") {
+		return ErrInvalidChannel
+	}
+	if len(name) > isupport.ChannelLen() {
+		return ErrNameTooLong
+	}
+	return nil
+}
+
+// ValidNick checks that nick is a nickname the server described by isupport
+// would accept: it must not begin with a digit or hyphen, must fit within
+// NICKLEN, and may otherwise contain letters, digits, hyphens and the
+// characters []\`_^{|}. Under the rfc7613 and precis casemappings letters
+// and digits outside ASCII are also allowed. A nil isupport applies the
+// defaults.
+func ValidNick(nick string, isupport *ISupport) error {
+	if nick == "" || isDigit(nick[0]) || nick[0] == '-' {
+		return ErrInvalidNick
+	}
+	switch isupport.Casemapping() {
+	case "rfc7613", "precis":
+		for _, r := range nick {
+			if r < utf8.RuneSelf && !isLetter(byte(r)) && !isDigit(byte(r)) && !isNickSpecial(byte(r)) && r != '-' ||
+				r >= utf8.RuneSelf && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return ErrInvalidNick
+			}
+		}
+	default:
+		if !validNick(nick, false) {
+			return ErrInvalidNick
+		}
+	}
+	if utf8.RuneCountInString(nick) > isupport.NickLen() {
+		return ErrNameTooLong
+	}
+	return nil
+}
+
+// Validate checks m against the grammar and limits of profile, returning
+// every problem found rather than just the first. The errors wrap
+// ErrMessageMalformed, or are ErrLineTooLong. Validate is useful before
+// encoding a message built from user input, or when a server accepts one.
+func Validate(m Message, profile Profile) []error {
+	var errs []error
+	bad := func(problem string) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrMessageMalformed, problem))
+	}
+	if profile.NoTags && len(m.Tags) > 0 {
+		bad("tags not permitted")
+	}
+	for k, v := range m.Tags {
+		if !validTagKey(k) {
+			bad(fmt.Sprintf("invalid tag key %q", k))
+		} else if profile.ClientTags && isServerTag(k) {
+			bad(fmt.Sprintf("tag %q not permitted from clients", k))
+		}
+		if strings.IndexByte(v, 0) >= 0 || !utf8.ValidString(v) ||
+			profile.NoControls && hasControl(v) {
+			bad(fmt.Sprintf("invalid value for tag %q", k))
+		} else if profile.Limits.TagValue > 0 && len(v) > profile.Limits.TagValue {
+			bad(fmt.Sprintf("value for tag %q exceeds limit of %d", k, profile.Limits.TagValue))
+		}
+	}
+	if profile.NoPrefix && m.Prefix != "" {
+		bad("prefix not permitted")
+	} else if m.Prefix != "" && (!prefixWellFormed(m.Prefix) || strings.ContainsAny(m.Prefix, " \r\n\x00") ||
+		profile.NoControls && hasControl(m.Prefix) ||
+		profile.StrictPrefix && !validPrefix(m.Prefix, profile.LegacyNicks)) {
+		bad(fmt.Sprintf("invalid prefix %q", m.Prefix))
+	}
+	if m.Command == "" || strings.ContainsAny(m.Command, " :\r\n\x00") ||
+		profile.StrictCommand && !validCommand(m.Command) {
+		bad(fmt.Sprintf("invalid command %q", m.Command))
+	}
+	if profile.Limits.MaxParams > 0 && len(m.Params) > profile.Limits.MaxParams {
+		bad(fmt.Sprintf("%d parameters exceeds limit of %d", len(m.Params), profile.Limits.MaxParams))
+	}
+	for i, p := range m.Params {
+		if strings.ContainsAny(p, "\r\n\x00") || profile.NoControls && hasControl(p) {
+			bad(fmt.Sprintf("forbidden byte in parameter %d", i))
+		} else if i < len(m.Params)-1 && (p == "" || p[0] == runeColon || strings.Contains(p, tokenSpace)) {
+			bad(fmt.Sprintf("parameter %d may only be the last parameter", i))
+		}
+	}
+	if errs == nil {
+		limits := profile.Limits.orDefault()
+		limits.MaxParams = 0
+		if _, err := appendMessage(nil, m, limits, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// validTagKey reports whether k is a tag key as per:
+// https://ircv3.net/specs/extensions/message-tags#format
+func validTagKey(k string) bool {
+	k = strings.TrimPrefix(k, "+")
+	if i := strings.LastIndexByte(k, '/'); i >= 0 {
+		if !validHost(k[:i]) {
+			return false
+		}
+		k = k[i+1:]
+	}
+	for i := 0; i < len(k); i++ {
+		if !isLetter(k[i]) && !isDigit(k[i]) && k[i] != '-' {
+			return false
+		}
+	}
+	return k != ""
+}
@@ -0,0 +1,139 @@
+package ircmessage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var validChannelTests = []struct {
+	name string
+	err  error
+}{
+	{"#chan", nil},
+	{"&local", nil},
+	{"#c", nil},
+	{"#", ErrInvalidChannel},
+	{"chan", ErrInvalidChannel},
+	{"!chan", ErrInvalidChannel},
+	{"#two words", ErrInvalidChannel},
+	{"#a,#b", ErrInvalidChannel},
+	{"#bell\x07", ErrInvalidChannel},
+	{"#" + strings.Repeat("a", 199), nil},
+	{"#" + strings.Repeat("a", 200), ErrNameTooLong},
+}
+
+func TestValidChannel(t *testing.T) {
+	for i, tt := range validChannelTests {
+		if err := ValidChannel(tt.name, nil); err != tt.err {
+			t.Errorf("%d. expecting error %v, got %v", i, tt.err, err)
+		}
+	}
+	is := NewISupport()
+	is.Update(Message{Command: "005", Params: []string{"nick", "CHANTYPES=#!", "CHANNELLEN=5", "are supported"}})
+	if err := ValidChannel("!chan", is); err != nil {
+		t.Errorf("expecting no error, got %v", err)
+	}
+	if err := ValidChannel("&chan", is); err != ErrInvalidChannel {
+		t.Errorf("expecting error %v, got %v", ErrInvalidChannel, err)
+	}
+	if err := ValidChannel("#chann", is); err != ErrNameTooLong {
+		t.Errorf("expecting error %v, got %v", ErrNameTooLong, err)
+	}
+}
+
+var validNickTests = []struct {
+	nick        string
+	casemapping string
+	err         error
+}{
+	{"nick", "", nil},
+	{"[bot]", "", nil},
+	{"a-b_c|d", "", nil},
+	{"nick123", "", nil},
+	{"1nick", "", ErrInvalidNick},
+	{"-nick", "", ErrInvalidNick},
+	{"ni ck", "", ErrInvalidNick},
+	{"nick!", "", ErrInvalidNick},
+	{"", "", ErrInvalidNick},
+	{"ñandú", "", ErrInvalidNick},
+	{"ñandú", "rfc7613", nil},
+	{"ñ!", "rfc7613", ErrInvalidNick},
+	{"abcdefghij", "", ErrNameTooLong},
+	{"abcdefghi", "", nil},
+}
+
+func TestValidNick(t *testing.T) {
+	for i, tt := range validNickTests {
+		var is *ISupport
+		if tt.casemapping != "" {
+			is = NewISupport()
+			is.Update(Message{Command: "005", Params: []string{"nick", "CASEMAPPING=" + tt.casemapping, "are supported"}})
+		}
+		if err := ValidNick(tt.nick, is); err != tt.err {
+			t.Errorf("%d. expecting error %v, got %v", i, tt.err, err)
+		}
+	}
+	is := NewISupport()
+	is.Update(Message{Command: "005", Params: []string{"nick", "NICKLEN=30", "are supported"}})
+	if err := ValidNick("abcdefghij", is); err != nil {
+		t.Errorf("expecting no error, got %v", err)
+	}
+}
+
+var validateTests = []struct {
+	m       Message
+	profile Profile
+	errs    []string
+}{
+	{Message{Prefix: "nick!u@h", Command: "PRIVMSG", Params: []string{"#c", "hi there"}}, RFC2812, nil},
+	{Message{Tags: map[string]string{"+example.com/typing": "active"}, Command: "TAGMSG", Params: []string{"#c"}}, Modern, nil},
+	{
+		Message{Tags: map[string]string{"a b": "x\x00"}, Prefix: "bad prefix", Command: "PR1V", Params: []string{"two words", "x\r\n"}},
+		RFC2812,
+		[]string{
+			"message malformed: tags not permitted",
+			`message malformed: invalid tag key "a b"`,
+			`message malformed: invalid value for tag "a b"`,
+			`message malformed: invalid prefix "bad prefix"`,
+			`message malformed: invalid command "PR1V"`,
+			"message malformed: parameter 0 may only be the last parameter",
+			"message malformed: forbidden byte in parameter 1",
+		},
+	},
+	{Message{Command: "FOO", Params: strings.Fields("1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16")}, RFC1459, []string{"message malformed: 16 parameters exceeds limit of 15"}},
+	{Message{Command: "PRIVMSG", Params: []string{"#c", strings.Repeat("a", 510)}}, Profile{}, []string{"line too long"}},
+	{
+		Message{Tags: map[string]string{"a": strings.Repeat("x", 513)}, Prefix: "n\x07", Command: "PRIVMSG", Params: []string{"#c", "\x02hi\x1b"}},
+		Hardened(),
+		[]string{
+			`message malformed: value for tag "a" exceeds limit of 512`,
+			`message malformed: invalid prefix "n\a"`,
+			"message malformed: forbidden byte in parameter 1",
+		},
+	},
+	{
+		Message{Tags: map[string]string{"time": "x", "+typing": "active"}, Prefix: "nick", Command: "TAGMSG", Params: []string{"#c"}},
+		ServerSide(),
+		[]string{
+			`message malformed: tag "time" not permitted from clients`,
+			"message malformed: prefix not permitted",
+		},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	for i, tt := range validateTests {
+		errs := Validate(tt.m, tt.profile)
+		var got []string
+		for _, err := range errs {
+			if err != ErrLineTooLong && !errors.Is(err, ErrMessageMalformed) {
+				t.Errorf("%d. unexpected error type %v", i, err)
+			}
+			got = append(got, err.Error())
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.errs, "\n") {
+			t.Errorf("%d. expecting errors\n%s\ngot\n%s", i, strings.Join(tt.errs, "\n"), strings.Join(got, "\n"))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package ircmessage
+
+import "strings"
+
+// OperBroadcastKind identifies the kind of an operator broadcast.
+type OperBroadcastKind int
+
+const (
+	OperWallops  OperBroadcastKind = iota // WALLOPS, seen by users with mode +w.
+	OperOperwall                          // OPERWALL, seen by operators only.
+	OperLocops                            // LOCOPS, seen by operators on one server.
+	OperGlobops                           // GLOBOPS, seen by operators network wide.
+)
+
+// OperBroadcast is a message broadcast by an operator or server with
+// WALLOPS, GLOBOPS or a related command.
+type OperBroadcast struct {
+	Kind OperBroadcastKind
+	// Prefix is that of the message, naming the operator or server that
+	// sent it.
+	Prefix string
+	// Sender is the nick of the operator, or the name of the server if
+	// it was sent by one on nobody's behalf.
+	Sender     string
+	FromServer bool
+	Text       string
+}
+
+// operBroadcastMarkers are the text prefixes by which ratbox family
+// servers, such as Solanum, mark operator only broadcasts sent as WALLOPS.
+var operBroadcastMarkers = []struct {
+	marker string
+	kind   OperBroadcastKind
+}{
+	{"OPERWALL - ", OperOperwall},
+	{"LOCOPS - ", OperLocops},
+	{"SLOCOPS - ", OperLocops},
+}
+
+// globopsNoticeMarkers are the text prefixes of the server notices by which
+// UnrealIRCd and InspIRCd deliver GLOBOPS, each followed by the sender and a
+// colon.
+var globopsNoticeMarkers = []string{"*** Global -- from ", "*** GLOBOPS: From "}
+
+// Wallops returns the WALLOPS message broadcasting text.
+func Wallops(text string) Message { return Message{Command: "WALLOPS", Params: []string{text}} }
+
+// Globops returns the GLOBOPS message broadcasting text to operators.
+func Globops(text string) Message { return Message{Command: "GLOBOPS", Params: []string{text}} }
+
+// ParseOperBroadcast parses a WALLOPS or GLOBOPS message, or a server
+// NOTICE delivering a GLOBOPS.
+func ParseOperBroadcast(m Message) (OperBroadcast, error) {
+	p := ParsePrefix(m.Prefix)
+	if p == nil || len(m.Params) == 0 {
+		return OperBroadcast{}, ErrMessageMalformed
+	}
+	b := OperBroadcast{Prefix: m.Prefix, Text: m.Params[len(m.Params)-1]}
+	if p.IsServer {
+		b.Sender, b.FromServer = p.Host, true
+	} else {
+		b.Sender = p.Nickname
+	}
+	switch {
+	case HasCommand(m, "WALLOPS"):
+		for _, om := range operBroadcastMarkers {
+			if text, ok := strings.CutPrefix(b.Text, om.marker); ok {
+				b.Kind, b.Text = om.kind, text
+				break
+			}
+		}
+		return b, nil
+	case HasCommand(m, "GLOBOPS"):
+		b.Kind = OperGlobops
+		return b, nil
+	case HasCommand(m, "NOTICE") && p.IsServer:
+		for _, marker := range globopsNoticeMarkers {
+			rest, ok := strings.CutPrefix(b.Text, marker)
+			if !ok {
+				continue
+			}
+			sender, text, ok := strings.Cut(rest, ": ")
+			if !ok {
+				break
+			}
+			b.Kind, b.Sender, b.FromServer, b.Text = OperGlobops, sender, strings.Contains(sender, "."), text
+			return b, nil
+		}
+	}
+	return OperBroadcast{}, ErrMessageMalformed
+}
@@ -0,0 +1,51 @@
+package ircmessage
+
+import "testing"
+
+var operBroadcastTests = []struct {
+	in       Message
+	expected OperBroadcast
+	err      error
+}{
+	{
+		Message{Prefix: "oper!o@staff.example", Command: "WALLOPS", Params: []string{"Rebooting soon"}},
+		OperBroadcast{Kind: OperWallops, Prefix: "oper!o@staff.example", Sender: "oper", Text: "Rebooting soon"},
+		nil,
+	},
+	{
+		Message{Prefix: "oper!o@staff.example", Command: "wallops", Params: []string{"OPERWALL - spam wave from 192.0.2.0/24"}},
+		OperBroadcast{Kind: OperOperwall, Prefix: "oper!o@staff.example", Sender: "oper", Text: "spam wave from 192.0.2.0/24"},
+		nil,
+	},
+	{
+		Message{Prefix: "irc.example.org", Command: "WALLOPS", Params: []string{"Remote CONNECT hub.example.org 6667 from oper"}},
+		OperBroadcast{Kind: OperWallops, Prefix: "irc.example.org", Sender: "irc.example.org", FromServer: true, Text: "Remote CONNECT hub.example.org 6667 from oper"},
+		nil,
+	},
+	{
+		Message{Prefix: "oper!o@staff.example", Command: "GLOBOPS", Params: []string{"hello opers"}},
+		OperBroadcast{Kind: OperGlobops, Prefix: "oper!o@staff.example", Sender: "oper", Text: "hello opers"},
+		nil,
+	},
+	{
+		Message{Prefix: "irc.example.org", Command: "NOTICE", Params: []string{"me", "*** Global -- from oper: hello opers"}},
+		OperBroadcast{Kind: OperGlobops, Prefix: "irc.example.org", Sender: "oper", Text: "hello opers"},
+		nil,
+	},
+	{
+		Message{Prefix: "irc.example.org", Command: "NOTICE", Params: []string{"me", "*** GLOBOPS: From services.example.org: hello opers"}},
+		OperBroadcast{Kind: OperGlobops, Prefix: "irc.example.org", Sender: "services.example.org", FromServer: true, Text: "hello opers"},
+		nil,
+	},
+	{Message{Prefix: "irc.example.org", Command: "NOTICE", Params: []string{"me", "*** Notice -- hello"}}, OperBroadcast{}, ErrMessageMalformed},
+	{Message{Command: "WALLOPS", Params: []string{"no prefix"}}, OperBroadcast{}, ErrMessageMalformed},
+}
+
+func TestParseOperBroadcast(t *testing.T) {
+	for i, tt := range operBroadcastTests {
+		b, err := ParseOperBroadcast(tt.in)
+		if err != tt.err || b != tt.expected {
+			t.Errorf("%d. expecting %+v %v, got %+v %v", i, tt.expected, tt.err, b, err)
+		}
+	}
+}
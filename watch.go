@@ -0,0 +1,143 @@
+package ircmessage
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// watchLine is the longest WATCH message the builders produce, leaving room
+// for the line ending.
+const watchLine = 510
+
+// WatchAdd returns the messages adding nicks to the watch list, split so
+// that each fits on a line.
+//
+// WATCH is the presence notification system of DALnet, UnrealIRCd and
+// others that predates MONITOR. Servers supporting it advertise the WATCH
+// RPL_ISUPPORT token with the maximum number of entries.
+func WatchAdd(nicks ...string) []Message { return watchEntries('+', nicks) }
+
+// WatchRemove returns the messages removing nicks from the watch list.
+func WatchRemove(nicks ...string) []Message { return watchEntries('-', nicks) }
+
+func watchEntries(sign byte, nicks []string) []Message {
+	var (
+		msgs    []Message
+		entries []string
+		n       = len("WATCH")
+	)
+	for _, nick := range nicks {
+		if len(entries) > 0 && n+2+len(nick) > watchLine {
+			msgs = append(msgs, Message{Command: "WATCH", Params: entries})
+			entries, n = nil, len("WATCH")
+		}
+		entries = append(entries, string(sign)+nick)
+		n += 2 + len(nick)
+	}
+	if len(entries) > 0 {
+		msgs = append(msgs, Message{Command: "WATCH", Params: entries})
+	}
+	return msgs
+}
+
+// WatchClear empties the watch list.
+func WatchClear() Message { return Message{Command: "WATCH", Params: []string{"C"}} }
+
+// WatchList requests the watched nicks that are online, and those that
+// are offline too if offline is true.
+func WatchList(offline bool) Message {
+	if offline {
+		return Message{Command: "WATCH", Params: []string{"L"}}
+	}
+	return Message{Command: "WATCH", Params: []string{"l"}}
+}
+
+// WatchStat requests the number of entries on the watch list and the number
+// of users watching for the client, answered by RPL_WATCHSTAT (603) and
+// RPL_WATCHLIST (606).
+func WatchStat() Message { return Message{Command: "WATCH", Params: []string{"S"}} }
+
+// WatchEventKind identifies the numeric a WatchEvent was parsed from.
+type WatchEventKind int
+
+const (
+	WatchLoggedOn  WatchEventKind = iota // RPL_LOGON (600): a watched nick came online.
+	WatchLoggedOff                       // RPL_LOGOFF (601): a watched nick went offline.
+	WatchRemoved                         // RPL_WATCHOFF (602): a nick was removed from the list.
+	WatchOnline                          // RPL_NOWON (604): a nick is online.
+	WatchOffline                         // RPL_NOWOFF (605): a nick is offline.
+)
+
+// WatchEvent is the state of a watched nick as reported by the server.
+type WatchEvent struct {
+	Kind WatchEventKind
+	Nick string
+	User string // Empty when the server does not know it.
+	Host string // Empty when the server does not know it.
+	// Time is when the nick came online or went offline, or the zero
+	// Time when not given.
+	Time time.Time
+}
+
+var watchEventKinds = map[string]WatchEventKind{
+	"600": WatchLoggedOn,
+	"601": WatchLoggedOff,
+	"602": WatchRemoved,
+	"604": WatchOnline,
+	"605": WatchOffline,
+}
+
+// ParseWatchEvent parses an RPL_LOGON, RPL_LOGOFF, RPL_WATCHOFF, RPL_NOWON
+// or RPL_NOWOFF message. It returns false for any other message.
+func ParseWatchEvent(m Message) (WatchEvent, bool) {
+	kind, ok := watchEventKinds[m.Command]
+	if !ok || len(m.Params) < 5 {
+		return WatchEvent{}, false
+	}
+	e := WatchEvent{Kind: kind, Nick: m.Params[1], User: m.Params[2], Host: m.Params[3]}
+	if e.User == "*" {
+		e.User = ""
+	}
+	if e.Host == "*" {
+		e.Host = ""
+	}
+	if ts, err := strconv.ParseInt(m.Params[4], 10, 64); err == nil && ts > 0 {
+		e.Time = time.Unix(ts, 0)
+	}
+	return e, true
+}
+
+// ParseWatchList parses an RPL_WATCHLIST (606) message, returning the nicks
+// it lists. Several may be sent in reply to WATCH S, ended by
+// RPL_ENDOFWATCHLIST (607). It returns false for any other message.
+func ParseWatchList(m Message) ([]string, bool) {
+	if m.Command != "606" || len(m.Params) < 2 {
+		return nil, false
+	}
+	return strings.Fields(m.Params[len(m.Params)-1]), true
+}
+
+// ParseWatchStat parses an RPL_WATCHSTAT (603) message, such as "You have 3
+// and are on 1 WATCH entries", into the number of entries on the watch list
+// and the number of watch lists the client is on. It returns false for any
+// other message.
+func ParseWatchStat(m Message) (entries, watchers int, ok bool) {
+	if m.Command != "603" || len(m.Params) < 2 {
+		return 0, 0, false
+	}
+	var counts []int
+	for _, f := range strings.Fields(m.Params[len(m.Params)-1]) {
+		if n, err := strconv.Atoi(f); err == nil {
+			counts = append(counts, n)
+		}
+	}
+	if len(counts) != 2 {
+		return 0, 0, false
+	}
+	return counts[0], counts[1], true
+}
+
+// IsWatchEnd reports whether m ends a listing, being RPL_ENDOFWATCHLIST
+// (607), or RPL_CLEARWATCH (608) in reply to WATCH C.
+func IsWatchEnd(m Message) bool { return m.Command == "607" || m.Command == "608" }
@@ -0,0 +1,87 @@
+package ircmessage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchBuilders(t *testing.T) {
+	msgs := WatchAdd("alice", "bob")
+	if len(msgs) != 1 || !reflect.DeepEqual(msgs[0].Params, []string{"+alice", "+bob"}) {
+		t.Errorf("unexpected messages %v", msgs)
+	}
+	if msgs := WatchRemove(); len(msgs) != 0 {
+		t.Errorf("expecting no messages, got %v", msgs)
+	}
+	nicks := make([]string, 100)
+	for i := range nicks {
+		nicks[i] = strings.Repeat("n", 9)
+	}
+	msgs = WatchRemove(nicks...)
+	total := 0
+	for _, m := range msgs {
+		b, err := AppendMessage(nil, m)
+		if err != nil || len(b) > 512 {
+			t.Errorf("expecting a line of at most 512 bytes, got %d %v", len(b), err)
+		}
+		total += len(m.Params)
+	}
+	if len(msgs) != 3 || total != len(nicks) || msgs[0].Params[0] != "-nnnnnnnnn" {
+		t.Errorf("expecting 100 entries over 3 messages, got %d over %d", total, len(msgs))
+	}
+	if m := WatchList(true); m.Params[0] != "L" {
+		t.Errorf("unexpected message %v", m)
+	}
+}
+
+var watchEventTests = []struct {
+	in       Message
+	expected WatchEvent
+	ok       bool
+}{
+	{
+		Message{Command: "600", Params: []string{"me", "alice", "a", "host.example", "1600000000", "logged online"}},
+		WatchEvent{Kind: WatchLoggedOn, Nick: "alice", User: "a", Host: "host.example", Time: time.Unix(1600000000, 0)},
+		true,
+	},
+	{
+		Message{Command: "605", Params: []string{"me", "bob", "*", "*", "0", "is offline"}},
+		WatchEvent{Kind: WatchOffline, Nick: "bob"},
+		true,
+	},
+	{Message{Command: "601", Params: []string{"me", "bob"}}, WatchEvent{}, false},
+	{Message{Command: "603", Params: []string{"me", "a", "b", "c", "0"}}, WatchEvent{}, false},
+}
+
+func TestParseWatchEvent(t *testing.T) {
+	for i, tt := range watchEventTests {
+		e, ok := ParseWatchEvent(tt.in)
+		if ok != tt.ok || e != tt.expected {
+			t.Errorf("%d. expecting %+v %v, got %+v %v", i, tt.expected, tt.ok, e, ok)
+		}
+	}
+}
+
+func TestParseWatchReplies(t *testing.T) {
+	nicks, ok := ParseWatchList(Message{Command: "606", Params: []string{"me", "alice bob"}})
+	if !ok || !reflect.DeepEqual(nicks, []string{"alice", "bob"}) {
+		t.Errorf("unexpected list %v %v", nicks, ok)
+	}
+	entries, watchers, ok := ParseWatchStat(Message{Command: "603", Params: []string{"me", "You have 3 and are on 1 WATCH entries"}})
+	if !ok || entries != 3 || watchers != 1 {
+		t.Errorf("expecting 3 and 1, got %d %d %v", entries, watchers, ok)
+	}
+	if !IsWatchEnd(Message{Command: "607", Params: []string{"me", "End of WATCH S"}}) {
+		t.Error("expecting 607 to end the list")
+	}
+	is := NewISupport()
+	if is.Watch() != 0 {
+		t.Errorf("expecting no WATCH support, got %d", is.Watch())
+	}
+	is.Update(Message{Command: "005", Params: []string{"me", "WATCH=128", "are supported"}})
+	if is.Watch() != 128 {
+		t.Errorf("expecting 128 entries, got %d", is.Watch())
+	}
+}
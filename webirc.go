@@ -0,0 +1,77 @@
+package ircmessage
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// WebIRC holds the parameters of a WEBIRC command, sent by gateways to pass
+// on the real hostname and IP address of the users they relay, as per:
+// https://ircv3.net/specs/extensions/webirc
+type WebIRC struct {
+	Password string
+	Gateway  string
+	Hostname string
+	IP       string
+	// Options holds flags such as "secure" with an empty value, and
+	// key-value options such as "local-port".
+	Options map[string]string
+}
+
+// Message returns the WEBIRC message for w.
+func (w WebIRC) Message() Message {
+	ip := w.IP
+	if strings.HasPrefix(ip, tokenColon) {
+		// IPv6 addresses such as ::1 would otherwise be
+		// mistaken for a trailing parameter.
+		ip = "0" + ip
+	}
+	params := []string{w.Password, w.Gateway, w.Hostname, ip}
+	if len(w.Options) > 0 {
+		keys := make([]string, 0, len(w.Options))
+		for k := range w.Options {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var opts []byte
+		for i, k := range keys {
+			if i > 0 {
+				opts = append(opts, runeSpace)
+			}
+			opts = append(opts, k...)
+			if v := w.Options[k]; v != "" {
+				opts = append(opts, runeEquals)
+				opts = appendTagValue(opts, v)
+			}
+		}
+		params = append(params, string(opts))
+	}
+	return Message{Command: "WEBIRC", Params: params}
+}
+
+// ParseWebIRC parses a WEBIRC message.
+func ParseWebIRC(m Message) (WebIRC, error) {
+	if !HasCommand(m, "WEBIRC") || len(m.Params) < 4 {
+		return WebIRC{}, ErrMessageMalformed
+	}
+	w := WebIRC{
+		Password: m.Params[0],
+		Gateway:  m.Params[1],
+		Hostname: m.Params[2],
+		IP:       m.Params[3],
+	}
+	// Normalising the address drops the 0 padding added by Message and
+	// some gateways, as in 0::1.
+	if ip := net.ParseIP(w.IP); ip != nil {
+		w.IP = ip.String()
+	}
+	if len(m.Params) > 4 {
+		w.Options = make(map[string]string)
+		for _, opt := range strings.Fields(m.Params[4]) {
+			k, v, _ := strings.Cut(opt, tokenEquals)
+			w.Options[k] = unescapeTagValue(v)
+		}
+	}
+	return w, nil
+}
@@ -0,0 +1,52 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWebIRC(t *testing.T) {
+	w := WebIRC{
+		Password: "pass",
+		Gateway:  "gateway",
+		Hostname: "localhost",
+		IP:       "::1",
+		Options:  map[string]string{"secure": "", "local-port": "6697"},
+	}
+	m := w.Message()
+	expected := []string{"pass", "gateway", "localhost", "0::1", "local-port=6697 secure"}
+	if m.Command != "WEBIRC" || !reflect.DeepEqual(m.Params, expected) {
+		t.Errorf("expecting params %q, got %v", expected, m)
+	}
+	parsed, err := ParseWebIRC(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, w) {
+		t.Errorf("expecting %+v, got %+v", w, parsed)
+	}
+	if _, err := ParseWebIRC(Message{Command: "WEBIRC", Params: []string{"pass"}}); err != ErrMessageMalformed {
+		t.Errorf("expecting %v, got %v", ErrMessageMalformed, err)
+	}
+}
+
+var webIRCAddressTests = []struct {
+	in       string
+	expected string
+}{
+	{"0::1", "::1"},
+	{"0:0:0:0:0:0:0:1", "::1"},
+	{"0:db8::1", "0:db8::1"},
+	{"2001:db8::1", "2001:db8::1"},
+	{"192.0.2.1", "192.0.2.1"},
+	{"not-an-ip", "not-an-ip"},
+}
+
+func TestParseWebIRCAddress(t *testing.T) {
+	for i, tt := range webIRCAddressTests {
+		w, err := ParseWebIRC(Message{Command: "WEBIRC", Params: []string{"pass", "gateway", "host", tt.in}})
+		if err != nil || w.IP != tt.expected {
+			t.Errorf("%d. expecting %q, got %q %v", i, tt.expected, w.IP, err)
+		}
+	}
+}
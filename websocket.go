@@ -0,0 +1,99 @@
+package ircmessage
+
+import "bytes"
+
+// WebSocketFrames is a WebSocket connection carrying IRC as per:
+// https://ircv3.net/specs/extensions/websocket
+//
+// Each frame holds exactly one message without a line ending. The package
+// does not implement WebSocket itself; wrap the connection type of a
+// WebSocket library to satisfy this interface, using text frames for the
+// text.ircv3.net subprotocol and binary frames for binary.ircv3.net.
+type WebSocketFrames interface {
+	// ReadFrame returns the payload of the next frame. The returned
+	// slice need only be valid until the next call.
+	ReadFrame() ([]byte, error)
+	// WriteFrame sends p as a single frame.
+	WriteFrame(p []byte) error
+}
+
+// NewWebSocketScanner returns a Scanner that reads one message from each
+// frame of ws. A frame ending with a line ending is accepted, but one
+// holding a line break elsewhere stops the scan with ErrMessageMalformed,
+// as it could otherwise smuggle in a second message.
+func NewWebSocketScanner(ws WebSocketFrames) *Scanner {
+	return NewScanner(&frameReader{ws: ws})
+}
+
+// NewWebSocketEncoder returns an Encoder that writes each message to ws as
+// a frame of its own, without the line ending. The Encoder's terminator
+// must be left as CRLF, by which messages are split into frames.
+func NewWebSocketEncoder(ws WebSocketFrames) *Encoder {
+	return NewEncoder(&frameWriter{ws: ws})
+}
+
+// frameReader presents frames as a stream of CRLF terminated lines.
+type frameReader struct {
+	ws      WebSocketFrames
+	line    []byte
+	pending []byte // Unread remainder of line.
+}
+
+func (r *frameReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		frame, err := r.ws.ReadFrame()
+		if err != nil {
+			return 0, err
+		}
+		frame = trimLineEnding(frame)
+		if bytes.ContainsAny(frame, "\r\n") {
+			return 0, ErrMessageMalformed
+		}
+		if len(frame) == 0 {
+			continue
+		}
+		r.line = append(append(r.line[:0], frame...), '\r', '\n')
+		r.pending = r.line
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// frameWriter sends each line written to it as a frame. Lines are split,
+// and incomplete lines held back, so that buffered output is framed
+// correctly.
+type frameWriter struct {
+	ws      WebSocketFrames
+	partial []byte
+}
+
+func (w *frameWriter) Write(p []byte) (int, error) {
+	n := 0
+	for {
+		i := bytes.IndexByte(p[n:], '\n')
+		if i < 0 {
+			break
+		}
+		line := p[n : n+i+1]
+		if len(w.partial) > 0 {
+			w.partial = append(w.partial, line...)
+			line = w.partial
+		}
+		if frame := trimLineEnding(line); len(frame) > 0 {
+			if err := w.ws.WriteFrame(frame); err != nil {
+				return n, err
+			}
+		}
+		w.partial = w.partial[:0]
+		n += i + 1
+	}
+	w.partial = append(w.partial, p[n:]...)
+	return len(p), nil
+}
+
+// trimLineEnding returns b without a trailing CRLF or LF.
+func trimLineEnding(b []byte) []byte {
+	b = bytes.TrimSuffix(b, []byte("\n"))
+	return bytes.TrimSuffix(b, []byte("\r"))
+}
@@ -0,0 +1,61 @@
+package ircmessage
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeFrames struct {
+	in  []string
+	out []string
+}
+
+func (f *fakeFrames) ReadFrame() ([]byte, error) {
+	if len(f.in) == 0 {
+		return nil, io.EOF
+	}
+	frame := []byte(f.in[0])
+	f.in = f.in[1:]
+	return frame, nil
+}
+
+func (f *fakeFrames) WriteFrame(p []byte) error {
+	f.out = append(f.out, string(p))
+	return nil
+}
+
+func TestWebSocketScanner(t *testing.T) {
+	ws := &fakeFrames{in: []string{"PING a", "", "PING b\r\n", "PRIVMSG #c :x\r\nQUIT", "PING c"}}
+	s := NewWebSocketScanner(ws)
+	var got []string
+	for s.Scan() {
+		got = append(got, s.Message().Raw)
+	}
+	if expected := []string{"PING a\r\n", "PING b\r\n"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expecting %q, got %q", expected, got)
+	}
+	if !errors.Is(s.Err(), ErrMessageMalformed) {
+		t.Errorf("expecting error %v, got %v", ErrMessageMalformed, s.Err())
+	}
+}
+
+func TestWebSocketEncoder(t *testing.T) {
+	ws := &fakeFrames{}
+	e := NewWebSocketEncoder(ws)
+	e.Encode(Message{Command: "PING", Params: []string{"a"}})
+	e.SetFlushPolicy(FlushManual, time.Second)
+	e.Encode(Message{Command: "PING", Params: []string{"b"}})
+	e.Encode(Message{Command: "PRIVMSG", Params: []string{"#c", "hi there"}})
+	e.Flush()
+	// A line split across writes is sent once complete.
+	w := e.w
+	w.Write([]byte("PING "))
+	w.Write([]byte("c\r"))
+	w.Write([]byte("\nPING d\r\n"))
+	if expected := []string{"PING a", "PING b", "PRIVMSG #c :hi there", "PING c", "PING d"}; !reflect.DeepEqual(ws.out, expected) {
+		t.Errorf("expecting %q, got %q", expected, ws.out)
+	}
+}
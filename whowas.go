@@ -0,0 +1,118 @@
+package ircmessage
+
+import "strconv"
+
+// WhowasRequest returns the WHOWAS message asking for up to count entries
+// of the history of nick, or every entry the server keeps if count is 0.
+func WhowasRequest(nick string, count int) Message {
+	m := Message{Command: "WHOWAS", Params: []string{nick}}
+	if count > 0 {
+		m.Params = append(m.Params, strconv.Itoa(count))
+	}
+	return m
+}
+
+// WhowasEntry is a user that once had a nickname.
+type WhowasEntry struct {
+	Nick     string
+	User     string
+	Host     string
+	RealName string
+	Server   string // The server the user was connected to.
+	// ServerInfo is the description of Server, which many servers
+	// replace with the time the user left.
+	ServerInfo string
+	Account    string // The account the user was logged in to, if sent.
+}
+
+// Whowas is the reply to a WHOWAS request.
+type Whowas struct {
+	Nick string
+	// Entries holds the users that had the nickname, most recent first.
+	// It is empty if the server knows of none.
+	Entries []WhowasEntry
+}
+
+// WhowasCollector gathers the RPL_WHOWASUSER (314), RPL_WHOISSERVER (312)
+// and RPL_WHOISACCOUNT (330) replies to WHOWAS requests, returning the
+// history of each nickname when RPL_ENDOFWHOWAS (369) arrives. The zero
+// value is ready to use, and is not safe for concurrent use.
+type WhowasCollector struct {
+	isupport isupportRef
+	pending  map[string]*Whowas // By folded nickname.
+}
+
+// SetISupport sets the server parameters whose casemapping nicknames are
+// compared with.
+func (c *WhowasCollector) SetISupport(isupport *ISupport) { c.isupport.isupport = isupport }
+
+// SetNetworkConfig sets the store whose current casemapping nicknames are
+// compared with, in place of any set by SetISupport.
+func (c *WhowasCollector) SetNetworkConfig(config *NetworkConfig) { c.isupport.config = config }
+
+// Add feeds a message to the collector. It reports whether the message was
+// a WHOWAS reply, and returns the history of a nickname once its replies
+// have ended.
+func (c *WhowasCollector) Add(m Message) (*Whowas, bool) {
+	if len(m.Params) < 2 {
+		return nil, false
+	}
+	nick := m.Params[1]
+	key := c.isupport.get().Fold(nick)
+	switch m.Command {
+	case "314": // RPL_WHOWASUSER
+		if len(m.Params) < 6 {
+			return nil, false
+		}
+		w := c.get(key, nick)
+		w.Entries = append(w.Entries, WhowasEntry{
+			Nick:     nick,
+			User:     m.Params[2],
+			Host:     m.Params[3],
+			RealName: m.Params[5],
+		})
+	case "312": // RPL_WHOISSERVER
+		e := c.last(key)
+		if e == nil || len(m.Params) < 4 {
+			return nil, false
+		}
+		e.Server, e.ServerInfo = m.Params[2], m.Params[3]
+	case "330": // RPL_WHOISACCOUNT
+		e := c.last(key)
+		if e == nil || len(m.Params) < 3 {
+			return nil, false
+		}
+		e.Account = m.Params[2]
+	case "406": // ERR_WASNOSUCHNICK
+		c.get(key, nick)
+	case "369": // RPL_ENDOFWHOWAS
+		w := c.get(key, nick)
+		delete(c.pending, key)
+		return w, true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+func (c *WhowasCollector) get(key, nick string) *Whowas {
+	if c.pending == nil {
+		c.pending = make(map[string]*Whowas)
+	}
+	w, ok := c.pending[key]
+	if !ok {
+		w = &Whowas{Nick: nick}
+		c.pending[key] = w
+	}
+	return w
+}
+
+// last returns the entry being received for the folded nickname, or nil if
+// there is none, in which case the reply belongs to a WHOIS.
+func (c *WhowasCollector) last(key string) *WhowasEntry {
+	w, ok := c.pending[key]
+	if !ok || len(w.Entries) == 0 {
+		return nil
+	}
+	return &w.Entries[len(w.Entries)-1]
+}
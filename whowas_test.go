@@ -0,0 +1,49 @@
+package ircmessage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWhowasCollector(t *testing.T) {
+	in := ":irc.example.org 314 me Alice a old.host * :Alice A\r\n" +
+		":irc.example.org 312 me alice irc.example.org :Mon Jan  1 12:00:00 2024\r\n" +
+		":irc.example.org 330 me alice alice :was logged in as\r\n" +
+		":irc.example.org 312 me bob irc.example.org :Example server\r\n" +
+		":irc.example.org 314 me Alice a2 new.host * :Alice again\r\n" +
+		":irc.example.org 369 me Alice :End of WHOWAS\r\n" +
+		":irc.example.org 406 me nobody :There was no such nickname\r\n" +
+		":irc.example.org 369 me nobody :End of WHOWAS\r\n"
+	var (
+		c       WhowasCollector
+		results []*Whowas
+		handled int
+	)
+	s := NewScanner(strings.NewReader(in))
+	for s.Scan() {
+		w, ok := c.Add(s.Message())
+		if ok {
+			handled++
+		}
+		if w != nil {
+			results = append(results, w)
+		}
+	}
+	expected := []*Whowas{
+		{Nick: "Alice", Entries: []WhowasEntry{
+			{Nick: "Alice", User: "a", Host: "old.host", RealName: "Alice A", Server: "irc.example.org", ServerInfo: "Mon Jan  1 12:00:00 2024", Account: "alice"},
+			{Nick: "Alice", User: "a2", Host: "new.host", RealName: "Alice again"},
+		}},
+		{Nick: "nobody"},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expecting %+v, got %+v", expected, results)
+	}
+	if handled != 7 {
+		t.Errorf("expecting 7 replies to be handled, got %d", handled)
+	}
+	if m := WhowasRequest("alice", 2); !reflect.DeepEqual(m.Params, []string{"alice", "2"}) {
+		t.Errorf("unexpected message %v", m)
+	}
+}
@@ -0,0 +1,52 @@
+package ircmessage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Capabilities offered by the ZNC bouncer.
+const (
+	// ZNCSelfMessage makes ZNC relay messages sent by the user's other
+	// clients, with the user's own nick as the prefix.
+	ZNCSelfMessage = "znc.in/self-message"
+	// ZNCPlayback enables the playback module's buffer replay.
+	ZNCPlayback = "znc.in/playback"
+)
+
+// IsZNCModule reports whether target addresses a ZNC module, such as
+// *status or *playback, rather than a real user.
+func IsZNCModule(target string) bool {
+	return len(target) > 1 && target[0] == '*'
+}
+
+// ZNCModuleMessage returns a PRIVMSG sending command to the ZNC module
+// named module, without its leading '*'.
+func ZNCModuleMessage(module, command string) Message {
+	return Message{Command: "PRIVMSG", Params: []string{"*" + module, command}}
+}
+
+// ZNCPlay asks the playback module to replay the buffer of target, or of
+// every conversation if target is "*", for messages after since.
+func ZNCPlay(target string, since time.Time) Message {
+	ts := fmt.Sprintf("%d.%03d", since.Unix(), since.Nanosecond()/int(time.Millisecond))
+	return ZNCModuleMessage("playback", "PLAY "+target+" "+ts)
+}
+
+// IsSelfMessage reports whether m was sent by the user themselves from
+// another client, as relayed by ZNC with the self-message capability. A nil
+// isupport uses the default casemapping.
+func IsSelfMessage(m Message, ownNick string, isupport *ISupport) bool {
+	if !IsTextMessage(m) && !IsTagMsg(m) {
+		return false
+	}
+	p := ParsePrefix(m.Prefix)
+	return p != nil && !p.IsServer && isupport.Fold(p.Nickname) == isupport.Fold(ownNick)
+}
+
+// IsPlaybackBatch reports whether b is a batch of buffered messages replayed
+// by ZNC. Such messages carry their original time in the server-time tag.
+func IsPlaybackBatch(b *Batch) bool {
+	return strings.EqualFold(b.Type, ZNCPlayback)
+}
@@ -0,0 +1,28 @@
+package ircmessage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestZNCHelpers(t *testing.T) {
+	if !IsZNCModule("*status") || IsZNCModule("*") || IsZNCModule("#chan") {
+		t.Error("unexpected module classification")
+	}
+	m := ZNCPlay("#chan", time.Unix(1500000000, 250e6))
+	if !reflect.DeepEqual(m.Params, []string{"*playback", "PLAY #chan 1500000000.250"}) {
+		t.Errorf("unexpected playback request %v", m)
+	}
+	self := Message{Prefix: "Me!u@h", Command: "PRIVMSG", Params: []string{"friend", "hi"}}
+	if !IsSelfMessage(self, "me", nil) {
+		t.Error("expecting message from own nick to be a self message")
+	}
+	self.Prefix = "friend!u@h"
+	if IsSelfMessage(self, "me", nil) {
+		t.Error("expecting message from another nick not to be a self message")
+	}
+	if !IsPlaybackBatch(&Batch{Type: "znc.in/playback"}) || IsPlaybackBatch(&Batch{Type: "chathistory"}) {
+		t.Error("unexpected playback batch classification")
+	}
+}